@@ -0,0 +1,269 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/config"
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/generator"
+)
+
+var (
+	schemaOutput        string
+	configMigrateDryRun bool
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and check project configuration files",
+	Long:  `Inspect and check the project configuration files passed to 'generate --config'.`,
+}
+
+// schemaConfigCmd prints the JSON Schema of project configuration files
+var schemaConfigCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of project configuration files",
+	Long: `Print the JSON Schema of project configuration files, generated from the
+configuration types, for editor autocompletion and validation.
+
+Unknown fields are rejected, tiers are an enumeration, ports must be within
+1-65535 and probe timeouts must be shorter than their period.
+
+Examples:
+  template-health-endpoint config schema -o project.schema.json
+
+  # Then, on the first line of a configuration file, for the YAML language server:
+  # yaml-language-server: $schema=./project.schema.json`,
+	Args: cobra.NoArgs,
+	RunE: runConfigSchema,
+}
+
+// validateConfigCmd checks configuration files against the schema
+var validateConfigCmd = &cobra.Command{
+	Use:   "validate <file>...",
+	Short: "Check configuration files against the schema",
+	Long: `Check project configuration files against the schema printed by
+'config schema', reporting every violation as file:line:col: path: message,
+such as:
+
+  service.yaml:14:9: kubernetes.health_probes.liveness_probe.timeout_seconds: must be < period_seconds
+
+generate runs the same check on the file passed with --config.
+
+Examples:
+  template-health-endpoint config validate service.yaml`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runValidateConfig,
+}
+
+// explainConfigCmd shows every effective setting and the layer it comes from
+var explainConfigCmd = &cobra.Command{
+	Use:   "explain [path...]",
+	Short: "Show every effective setting and the layer it comes from",
+	Long: `Merge the configuration layers the way generate does and print every
+effective setting with the layer it comes from, lowest precedence first:
+  default   - Built-in default, or derived from another setting such as output_dir
+  tier      - Default of the selected tier
+  org       - $TEMPLATE_HEALTH_ORG_CONFIG, org_config in the tool config, or
+              ~/.template-health-endpoint/org.yaml
+  project   - The file given with --config
+  env       - TEMPLATE_HEALTH_<PATH> variables such as TEMPLATE_HEALTH_FEATURES_DOCKER
+  flag      - --name, --tier, --output, --module, --features and --var
+
+Tier defaults never override a value set in another layer. Paths limit the
+output to the settings below them.
+
+Examples:
+  template-health-endpoint config explain --config service.yaml
+  template-health-endpoint config explain --config service.yaml --tier enterprise features kubernetes.ingress`,
+	RunE: runExplainConfig,
+}
+
+// migrateConfigCmd upgrades configuration files to the current apiVersion
+var migrateConfigCmd = &cobra.Command{
+	Use:   "migrate <file>...",
+	Short: "Upgrade configuration files to the current apiVersion in place",
+	Long: `Upgrade project configuration files to the current apiVersion, rewriting
+them in place. Comments, indentation and the order of settings are kept;
+blank lines are not.
+
+Configuration files carry an apiVersion; files without one are v0. generate
+and the other config commands upgrade older files in memory and warn that
+they are deprecated; migrate makes the upgrade permanent.
+
+Examples:
+  template-health-endpoint config migrate service.yaml
+  template-health-endpoint config migrate --dry-run configs/*.yaml`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runMigrateConfig,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(schemaConfigCmd)
+	configCmd.AddCommand(validateConfigCmd)
+	configCmd.AddCommand(explainConfigCmd)
+	configCmd.AddCommand(migrateConfigCmd)
+
+	schemaConfigCmd.Flags().StringVarP(&schemaOutput, "output", "o", "", "write the schema to a file instead of stdout")
+	migrateConfigCmd.Flags().BoolVar(&configMigrateDryRun, "dry-run", false, "show the changes as a diff without writing them")
+
+	// The flags of generate that feed the flag layer
+	explainConfigCmd.Flags().StringVarP(&configFile, "config", "c", "", "configuration file path")
+	explainConfigCmd.Flags().StringVarP(&projectName, "name", "n", "", "project name")
+	explainConfigCmd.Flags().StringVarP(&tier, "tier", "t", "", "template tier (basic|intermediate|advanced|enterprise)")
+	explainConfigCmd.Flags().StringVarP(&outputDir, "output", "o", "", "output directory")
+	explainConfigCmd.Flags().StringVarP(&goModule, "module", "m", "", "Go module path")
+	explainConfigCmd.Flags().StringSliceVarP(&features, "features", "f", []string{}, "comma-separated list of features to enable")
+	explainConfigCmd.Flags().StringArrayVar(&variables, "var", []string{}, "set a template variable (name=value, repeatable)")
+
+	viper.BindEnv("org_config", config.OrgConfigEnv)
+}
+
+// orgConfigFile returns the file of the org layer, or "" when there is none
+func orgConfigFile() string {
+	if file := viper.GetString("org_config"); file != "" {
+		return file
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	file := filepath.Join(home, config.DefaultOrgConfigFile)
+	if _, err := os.Stat(file); err != nil {
+		return ""
+	}
+	return file
+}
+
+func runConfigSchema(cmd *cobra.Command, args []string) error {
+	data, err := json.MarshalIndent(config.ProjectConfigSchema(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schema: %w", err)
+	}
+	data = append(data, '\n')
+
+	if schemaOutput == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(schemaOutput, data, 0644); err != nil {
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+	fmt.Printf("✅ Wrote %s\n", schemaOutput)
+	return nil
+}
+
+func runValidateConfig(cmd *cobra.Command, args []string) error {
+	violations := 0
+	for _, file := range args {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		layer, err := config.ParseLayer(config.LayerProject, file, data)
+		var errs config.ConfigErrors
+		switch {
+		case errors.As(err, &errs):
+			for _, e := range errs {
+				fmt.Println(e)
+			}
+			violations += len(errs)
+		case err != nil:
+			return err
+		default:
+			warnDeprecatedConfig(file, layer)
+			fmt.Printf("✅ %s\n", file)
+		}
+	}
+
+	if violations > 0 {
+		return fmt.Errorf("found %d schema violations", violations)
+	}
+	return nil
+}
+
+func runExplainConfig(cmd *cobra.Command, args []string) error {
+	resolved, err := loadConfiguration(cmd)
+	if err != nil {
+		return err
+	}
+	// Fill in the defaults Validate derives; an incomplete configuration is still explained
+	resolved.Config.Validate()
+
+	var values []config.ResolvedValue
+	pathWidth, valueWidth := 0, 0
+	for _, value := range resolved.Explain() {
+		if !matchesConfigPath(value.Path, args) {
+			continue
+		}
+		values = append(values, value)
+		pathWidth = max(pathWidth, len(value.Path))
+		valueWidth = max(valueWidth, len(value.Value))
+	}
+
+	for _, value := range values {
+		fmt.Printf("%-*s  %-*s  %s\n", pathWidth, value.Path, valueWidth, value.Value, value.Origin)
+	}
+	return nil
+}
+
+// matchesConfigPath reports whether path is one of prefixes or below one;
+// no prefixes match every path
+func matchesConfigPath(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+".") {
+			return true
+		}
+	}
+	return len(prefixes) == 0
+}
+
+func runMigrateConfig(cmd *cobra.Command, args []string) error {
+	for _, file := range args {
+		result, err := config.MigrateConfigFile(file, !configMigrateDryRun)
+		if err != nil {
+			return err
+		}
+		switch {
+		case len(bytes.TrimSpace(result.Before)) == 0:
+			fmt.Printf("✅ %s is empty\n", file)
+			continue
+		case result.Migration == nil:
+			fmt.Printf("✅ %s is already at apiVersion %s\n", file, config.ConfigAPIVersion)
+			continue
+		}
+
+		if configMigrateDryRun {
+			fmt.Print(generator.UnifiedDiff(file, file, string(result.Before), string(result.After)))
+			continue
+		}
+		fmt.Printf("✅ Upgraded %s from apiVersion %s to %s\n", file, result.Migration.From, result.Migration.To)
+		for _, warning := range result.Migration.Warnings {
+			fmt.Printf("   - %s\n", warning)
+		}
+	}
+	return nil
+}
+
+// warnDeprecatedConfig tells the user that a configuration file was upgraded in memory
+func warnDeprecatedConfig(file string, layer *config.Layer) {
+	if layer == nil || layer.Migration == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "⚠️  %s uses deprecated apiVersion %s and was upgraded to %s in memory; run 'template-health-endpoint config migrate %s' to update it\n",
+		file, layer.Migration.From, layer.Migration.To, file)
+	for _, warning := range layer.Migration.Warnings {
+		fmt.Fprintf(os.Stderr, "   - %s\n", warning)
+	}
+}
@@ -2,11 +2,14 @@ package commands
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -367,6 +370,9 @@ func generateCustomizedProject(c *Customization) error {
 		Description: fmt.Sprintf("Customized %s tier health endpoint service", c.BaseTier),
 	}
 
+	// Start from the tier defaults so the customizations below take precedence
+	projectConfig.ApplyTierDefaults()
+
 	// Apply feature configuration
 	projectConfig.Features.TypeScript = c.Features.TypeScript
 	projectConfig.Features.Docker = c.Features.Docker
@@ -392,17 +398,17 @@ func generateCustomizedProject(c *Customization) error {
 		return fmt.Errorf("configuration validation failed: %w", err)
 	}
 
-	// Apply tier defaults
-	projectConfig.ApplyTierDefaults()
-
 	// Create generator
 	gen, err := generator.New(projectConfig)
 	if err != nil {
 		return err
 	}
 
-	// Generate project
-	return gen.Generate()
+	// Generate project; Ctrl-C rolls back instead of leaving a half-written project
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return gen.GenerateContext(ctx)
 }
 
 // Helper functions for interactive prompts
@@ -1,13 +1,19 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"sort"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	"gopkg.in/yaml.v3"
 
 	"github.com/LarsArtmann/BMAD-METHOD/pkg/config"
 	"github.com/LarsArtmann/BMAD-METHOD/pkg/generator"
@@ -22,6 +28,21 @@ var (
 	dryRun        bool
 	configFile    string
 	interactive   bool
+	templateDirs  []string
+	onConflict    string
+	force         bool
+	archivePath   string
+	archiveFormat string
+	outputFormat  string
+	showStats     bool
+	noCache       bool
+	incremental   bool
+	taskTimeout   time.Duration
+	batchFile     string
+	batchWorkers  int
+	variables     []string
+	timestampFlag string
+	checkOnly     bool
 )
 
 // generateCmd represents the generate command
@@ -61,8 +82,93 @@ Examples:
   # Generate from a configuration file
   template-health-endpoint generate --config my-config.yaml
 
+  # Override built-in templates with files such as ./my-templates/dockerfile.tmpl
+  template-health-endpoint generate --name my-service --template-dir ./my-templates
+
+  # Regenerate into an existing project, merging changes into edited files
+  template-health-endpoint generate --config my-config.yaml --on-conflict merge
+
+  # Package the project as an archive instead of a directory ("-" streams to stdout)
+  template-health-endpoint generate --name my-service --archive my-service.tgz
+  template-health-endpoint generate --name my-service --archive - | tar -xz
+
+  # After a config change, re-render only the files it affects
+  template-health-endpoint generate --config my-config.yaml --incremental
+
+  # Stream progress and artifact events as JSON lines for CI systems and IDEs
+  template-health-endpoint generate --config my-config.yaml --output-format json
+
+  # Generate every service listed in services.yaml into one monorepo
+  template-health-endpoint generate --batch services.yaml
+
+  # Set variables declared in the tier's template.yaml
+  template-health-endpoint generate --name my-service --tier advanced --var log_level=debug
+
+  # Pin the generation time so repeated runs produce identical files
+  template-health-endpoint generate --config my-config.yaml --timestamp 2024-01-01T00:00:00Z
+  SOURCE_DATE_EPOCH=$(git log -1 --format=%ct) template-health-endpoint generate --config my-config.yaml
+
+  # Fail in CI when generated files were edited by hand
+  template-health-endpoint generate --config my-config.yaml --check
+
   # Preview what would be generated (dry run)
-  template-health-endpoint generate --name my-service --tier basic --dry-run`,
+  template-health-endpoint generate --name my-service --tier basic --dry-run
+
+Settings are merged from layers, each overriding the ones before it:
+  tier      - Defaults of the selected tier (basic unless a layer sets tier)
+  org       - $TEMPLATE_HEALTH_ORG_CONFIG, org_config in the tool config, or
+              ~/.template-health-endpoint/org.yaml
+  project   - The file given with --config
+  env       - TEMPLATE_HEALTH_<PATH> variables such as TEMPLATE_HEALTH_KUBERNETES_PORT
+  flag      - --name, --tier, --output, --module, --features and --var
+Tier defaults never override a value set explicitly; 'config explain' shows
+where every effective value comes from.
+
+An environments section in the configuration file overrides replicas,
+resources, otel_endpoint, log_level and mtls per environment:
+
+  environments:
+    production:
+      replicas: 5
+      resources: {limits: {cpu: "1", memory: 512Mi}}
+      log_level: warn
+
+Each environment gets configs/<env>.yaml and, with Kubernetes, a kustomize
+overlay in deployments/kubernetes/overlays/<env> (kubectl apply -k).
+
+Named templates can be replaced without forking the tool by a file such as
+go-server.tmpl in .template-health/templates (project) or
+~/.template-health-endpoint/templates (user). --template-dir wins over both;
+'template which <name>' shows which layer provides a template.
+
+Files are rendered into a staging directory and moved into the output directory
+only when every file rendered; on error or Ctrl-C the output directory is left
+untouched. An output directory that already has files is refused unless
+--on-conflict says otherwise:
+  fail      - Refuse to generate (default)
+  overwrite - Replace existing files (same as --force)
+  skip      - Keep existing files and only add new ones
+  merge     - Three-way merge into existing files, writing conflict markers
+
+A batch file lists services as project configurations on top of shared
+defaults, and can ask for a go.work, a root Makefile and a docker-compose.yml
+that runs every service on one network:
+
+  output_dir: platform
+  module_prefix: github.com/acme
+  workspace: {go_work: true, makefile: true, docker_compose: true}
+  defaults:
+    tier: intermediate
+  services:
+    - name: orders
+      dependencies: {external_services: [billing]}
+    - name: billing
+      tier: advanced
+
+Services generate concurrently through a shared template cache; a failed
+service does not stop the others. The workspace files render from the
+workspace-go-work, workspace-makefile and workspace-compose templates, which
+overlays can replace like any other.`,
 	RunE: runGenerate,
 }
 
@@ -77,9 +183,24 @@ func init() {
 	generateCmd.Flags().StringVarP(&outputDir, "output", "o", "", "output directory (default: project name)")
 	generateCmd.Flags().StringVarP(&goModule, "module", "m", "", "Go module path (default: github.com/example/{name})")
 	generateCmd.Flags().StringSliceVarP(&features, "features", "f", []string{}, "comma-separated list of features to enable")
-	generateCmd.Flags().BoolVar(&dryRun, "dry-run", false, "preview what would be generated without creating files")
+	generateCmd.Flags().BoolVar(&dryRun, "dry-run", false, "preview what would be generated, or list every configuration problem, without creating files")
 	generateCmd.Flags().StringVarP(&configFile, "config", "c", "", "configuration file path")
 	generateCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "interactive mode with prompts")
+	generateCmd.Flags().StringSliceVar(&templateDirs, "template-dir", []string{}, "directories with <name>.tmpl files that override built-in and overlay templates (later directories win)")
+	generateCmd.Flags().StringVar(&onConflict, "on-conflict", string(generator.ConflictPolicyFail), "how to handle an output directory with existing files (fail|overwrite|skip|merge)")
+	generateCmd.Flags().BoolVar(&force, "force", false, "overwrite existing files in the output directory (same as --on-conflict=overwrite)")
+	generateCmd.Flags().StringVar(&archivePath, "archive", "", "write the project to a .tgz or .zip archive instead of a directory (- for stdout)")
+	generateCmd.Flags().BoolVar(&incremental, "incremental", false, "only re-render files whose template or template inputs changed since the last generation")
+	generateCmd.Flags().DurationVar(&taskTimeout, "task-timeout", generator.DefaultTaskTimeout, "how long a single file may take to render and write (0 for no limit)")
+	generateCmd.Flags().BoolVar(&showStats, "stats", false, "show file and template cache statistics after generating")
+	generateCmd.Flags().BoolVar(&noCache, "no-cache", false, "render every file without the template cache")
+	generateCmd.Flags().StringVar(&outputFormat, "output-format", "text", "progress output format (text|json); json streams generation events as JSON lines on stdout")
+	generateCmd.Flags().StringArrayVar(&variables, "var", []string{}, "set a template variable declared in the tier's template.yaml (name=value, repeatable)")
+	generateCmd.Flags().StringVar(&batchFile, "batch", "", "generate every service listed in a batch file into one monorepo")
+	generateCmd.Flags().IntVar(&batchWorkers, "batch-concurrency", generator.DefaultBatchConcurrency, "how many services of a batch generate at once")
+	generateCmd.Flags().StringVar(&timestampFlag, "timestamp", "", "generation time recorded in generated files, as RFC 3339 or seconds since the Unix epoch (default: $SOURCE_DATE_EPOCH, else now)")
+	generateCmd.Flags().BoolVar(&checkOnly, "check", false, "re-render the project in memory and fail if generated files in the output directory differ")
+	generateCmd.Flags().StringVar(&archiveFormat, "archive-format", "", "archive format (tgz|zip, default: from the --archive file name, tgz for stdout)")
 
 	// Mark name as required only when not using interactive mode
 	// This will be validated in the command logic
@@ -89,6 +210,45 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	var cfg *config.ProjectConfig
 	var err error
 
+	// Human-readable output goes to stderr when stdout carries events or an archive
+	w := cmd.OutOrStdout()
+	if outputFormat == "json" || archivePath == "-" {
+		w = cmd.ErrOrStderr()
+	}
+
+	conflictPolicy := generator.ConflictPolicy(onConflict)
+	if force {
+		conflictPolicy = generator.ConflictPolicyOverwrite
+	}
+	if !conflictPolicy.IsValid() {
+		return fmt.Errorf("invalid conflict policy '%s' (must be one of: fail, overwrite, skip, merge)", onConflict)
+	}
+	if outputFormat != "text" && outputFormat != "json" {
+		return fmt.Errorf("invalid output format '%s' (must be one of: text, json)", outputFormat)
+	}
+	if outputFormat == "json" && archivePath == "-" {
+		return fmt.Errorf("--output-format=json and --archive - both need stdout; write the archive to a file instead")
+	}
+
+	if checkOnly && (batchFile != "" || archivePath != "" || dryRun) {
+		return fmt.Errorf("--check cannot be combined with --batch, --archive or --dry-run")
+	}
+	var timestamp time.Time
+	if timestampFlag != "" {
+		if timestamp, err = generator.ParseTimestamp(timestampFlag); err != nil {
+			return fmt.Errorf("invalid --timestamp: %w", err)
+		}
+	}
+
+	if batchFile != "" {
+		for _, name := range []string{"name", "config", "interactive", "output", "module", "features", "archive"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--batch cannot be combined with --%s; set it in the batch file instead", name)
+			}
+		}
+		return runBatch(w, conflictPolicy, timestamp)
+	}
+
 	// Use interactive wizard if requested or if minimal flags provided
 	if interactive || (projectName == "" && configFile == "") {
 		cfg, err = InteractiveWizard()
@@ -101,133 +261,498 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("project name is required when not using interactive mode. Use --interactive or provide --name")
 		}
 		
-		// Load configuration from flags/config file
-		cfg, err = loadConfiguration()
+		// Merge the configuration layers over the tier defaults
+		resolved, err := loadConfiguration(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to load configuration: %w", err)
 		}
+		cfg = resolved.Config
 	}
 
-	// Validate configuration
+	// Validate configuration; a dry run lists every problem with its fix
 	if err := cfg.Validate(); err != nil {
+		var problems config.ValidationErrors
+		if dryRun && errors.As(err, &problems) {
+			showValidationErrors(w, problems)
+			return fmt.Errorf("configuration has %d problems", len(problems))
+		}
 		return fmt.Errorf("configuration validation failed: %w", err)
 	}
 
-	// Apply tier-specific defaults
-	cfg.ApplyTierDefaults()
+	// Check template variables against the tier's declarations up front
+	tierConfig, err := loadTierConfig(cfg.Tier)
+	if err != nil {
+		return err
+	}
+	if _, err := tierConfig.ResolveVariables(cfg); err != nil {
+		return fmt.Errorf("invalid template variables: %w", err)
+	}
 
 	// Show configuration summary
 	if viper.GetBool("verbose") || dryRun {
-		if err := showConfigurationSummary(cfg); err != nil {
+		if err := showConfigurationSummary(w, cfg); err != nil {
 			return fmt.Errorf("failed to show configuration summary: %w", err)
 		}
 	}
 
 	// Dry run mode - just show what would be generated
 	if dryRun {
-		fmt.Println("\n🔍 Dry run mode - no files will be created")
-		return showGenerationPlan(cfg)
+		fmt.Fprintln(w, "\n🔍 Dry run mode - no files will be created")
+		return showGenerationPlan(w, cfg)
+	}
+
+	// Reuse renders from earlier runs; the cache is only an optimisation,
+	// so an unusable cache directory is not an error
+	if !noCache {
+		if dir, err := generator.DefaultCacheDir(); err == nil {
+			if err := generator.SharedTemplateCache().Persist(dir, generator.DefaultDiskCacheSize); err != nil && viper.GetBool("verbose") {
+				fmt.Fprintf(w, "⚠️  Template cache disabled: %v\n", err)
+			}
+		}
+	}
+
+	// Load templates: built-in, then the user and project overlays, then --template-dir
+	registry, err := generator.NewOverlayRegistry(".", templateDirs...)
+	if err != nil {
+		return fmt.Errorf("failed to load templates: %w", err)
 	}
 
 	// Create the generator
-	gen, err := generator.New(cfg)
+	gen, err := generator.NewWithRegistry(cfg, registry)
 	if err != nil {
 		return fmt.Errorf("failed to create generator: %w", err)
 	}
 
-	// Generate the project
-	fmt.Printf("🚀 Generating %s tier health endpoint project: %s\n", cfg.Tier, cfg.Name)
+	gen.SetConflictPolicy(conflictPolicy)
+	gen.SetTemplateConfig(tierConfig)
+	gen.SetTimestamp(timestamp)
+	gen.SetIncremental(incremental)
+	gen.SetTaskTimeout(taskTimeout)
+	if noCache {
+		gen.SetCache(nil)
+	}
+	if showStats {
+		defer showGenerationStats(w, gen)
+	}
+
+	if outputFormat == "json" {
+		gen.AddObserver(generator.NewJSONLinesObserver(os.Stdout))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if checkOnly {
+		return checkProject(ctx, w, gen, cfg)
+	}
+
+	if archivePath != "" {
+		return generateArchive(ctx, w, gen, cfg)
+	}
+
+	// Generate the project; Ctrl-C rolls back instead of leaving a half-written project
+	fmt.Fprintf(w, "🚀 Generating %s tier health endpoint project: %s\n", cfg.Tier, cfg.Name)
 
-	if err := gen.Generate(); err != nil {
+	if err := gen.GenerateContext(ctx); err != nil {
+		if errors.Is(err, generator.ErrOutputNotEmpty) {
+			return fmt.Errorf("%w; use --force to overwrite it, --on-conflict=skip|merge to keep your files, or a different directory with --output", err)
+		}
 		return fmt.Errorf("generation failed: %w", err)
 	}
+	showGenerated(w, gen)
+
+	if skipped := gen.Skipped(); skipped > 0 {
+		fmt.Fprintf(w, "♻️  Left %d unchanged files untouched\n", skipped)
+	}
+	if conflicts := gen.Conflicts(); len(conflicts) > 0 {
+		fmt.Fprintf(w, "⚠️  %d files have merge conflicts to resolve:\n", len(conflicts))
+		for _, path := range conflicts {
+			fmt.Fprintf(w, "   %s\n", path)
+		}
+	}
 
 	// Show success message with next steps
-	return showSuccessMessage(cfg)
+	return showSuccessMessage(w, cfg)
+}
+
+// checkProject re-renders the project in memory and fails if any generated
+// file in the output directory was edited or removed
+func checkProject(ctx context.Context, w io.Writer, gen *generator.Generator, cfg *config.ProjectConfig) error {
+	fmt.Fprintf(w, "🔍 Checking %s against a fresh render...\n", cfg.OutputDir)
+
+	drifts, err := gen.Check(ctx)
+	if err != nil {
+		return fmt.Errorf("check failed: %w", err)
+	}
+	if len(drifts) == 0 {
+		fmt.Fprintf(w, "✅ Generated files in %s are unchanged\n", cfg.OutputDir)
+		return nil
+	}
+
+	for _, drift := range drifts {
+		if drift.Missing {
+			fmt.Fprintf(w, "❌ %s is missing\n", drift.Path)
+			continue
+		}
+		fmt.Fprintf(w, "❌ %s differs:\n%s", drift.Path, drift.Diff)
+	}
+	return fmt.Errorf("%d generated files in %s differ from a fresh render; regenerate or move the edits into templates", len(drifts), cfg.OutputDir)
+}
+
+// generateArchive writes the project into the --archive file or stdout.
+// The archive is only written once every file rendered; a failed archive
+// file is removed.
+func generateArchive(ctx context.Context, w io.Writer, gen *generator.Generator, cfg *config.ProjectConfig) error {
+	format := generator.ArchiveFormat(archiveFormat)
+	switch {
+	case archiveFormat != "":
+		if format != generator.ArchiveTarGz && format != generator.ArchiveZip {
+			return fmt.Errorf("invalid archive format '%s' (must be one of: tgz, zip)", archiveFormat)
+		}
+	case archivePath == "-":
+		format = generator.ArchiveTarGz
+	default:
+		detected, err := generator.ArchiveFormatFromName(archivePath)
+		if err != nil {
+			return err
+		}
+		format = detected
+	}
+
+	if archivePath == "-" {
+		return writeArchive(ctx, w, gen, cfg, os.Stdout, format)
+	}
+
+	f, err := os.Create(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	err = writeArchive(ctx, w, gen, cfg, f, format)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write archive: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(archivePath)
+		return err
+	}
+
+	fmt.Fprintf(w, "\n📦 Archive written to: %s\n", archivePath)
+	return nil
+}
+
+// writeArchive generates the project and streams it to w as an archive
+func writeArchive(ctx context.Context, w io.Writer, gen *generator.Generator, cfg *config.ProjectConfig, archive io.Writer, format generator.ArchiveFormat) error {
+	timestamp, err := gen.GenerationTime()
+	if err != nil {
+		return err
+	}
+	out := generator.NewArchiveOutput(archive, format, cfg.Name, timestamp)
+	gen.SetOutput(out)
+
+	fmt.Fprintf(w, "🚀 Generating %s tier health endpoint project: %s\n", cfg.Tier, cfg.Name)
+	if err := gen.GenerateContext(ctx); err != nil {
+		return fmt.Errorf("generation failed: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	showGenerated(w, gen)
+
+	fmt.Fprintf(w, "\n✅ Successfully generated %s tier health endpoint project!\n", cfg.Tier)
+	return nil
+}
+
+// runBatch generates every service of the --batch file and prints one
+// consolidated summary. It fails if any service failed.
+func runBatch(w io.Writer, conflictPolicy generator.ConflictPolicy, timestamp time.Time) error {
+	batch, err := generator.LoadBatch(batchFile)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		fmt.Fprintf(w, "🔍 Dry run mode - %d services would be generated into %s\n", len(batch.Services), batch.OutputDir)
+		for _, cfg := range batch.Services {
+			fmt.Fprintf(w, "  - %-24s %-13s %s\n", cfg.Name, cfg.Tier, cfg.OutputDir)
+		}
+		return nil
+	}
+
+	if !noCache {
+		if dir, err := generator.DefaultCacheDir(); err == nil {
+			if err := generator.SharedTemplateCache().Persist(dir, generator.DefaultDiskCacheSize); err != nil && viper.GetBool("verbose") {
+				fmt.Fprintf(w, "⚠️  Template cache disabled: %v\n", err)
+			}
+		}
+	}
+
+	registry, err := generator.NewOverlayRegistry(".", templateDirs...)
+	if err != nil {
+		return fmt.Errorf("failed to load templates: %w", err)
+	}
+
+	var events *generator.JSONLinesObserver
+	if outputFormat == "json" {
+		events = generator.NewJSONLinesObserver(os.Stdout)
+	}
+
+	tierConfigs := make(map[config.TemplateTier]*config.TemplateConfig)
+	for _, cfg := range batch.Services {
+		if _, ok := tierConfigs[cfg.Tier]; !ok {
+			if tierConfigs[cfg.Tier], err = loadTierConfig(cfg.Tier); err != nil {
+				return err
+			}
+		}
+	}
+
+	batchGen := generator.NewBatchGenerator(registry, batchWorkers)
+	batchGen.Configure(func(gen *generator.Generator) {
+		gen.SetTemplateConfig(tierConfigs[gen.Config().Tier])
+		gen.SetTimestamp(timestamp)
+		gen.SetIncremental(incremental)
+		gen.SetTaskTimeout(taskTimeout)
+		if noCache {
+			gen.SetCache(nil)
+		}
+		if events != nil {
+			gen.AddObserver(events)
+		}
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(w, "🚀 Generating %d services into %s\n", len(batch.Services), batch.OutputDir)
+	summary, err := batchGen.Generate(ctx, batch, conflictPolicy)
+	if summary != nil {
+		showBatchSummary(w, summary)
+	}
+	if err != nil {
+		return err
+	}
+	if failures := summary.Failures(); len(failures) > 0 {
+		return fmt.Errorf("%d of %d services failed to generate", len(failures), len(summary.Results))
+	}
+	return nil
+}
+
+// showBatchSummary prints the outcome of every service of a batch
+func showBatchSummary(w io.Writer, summary *generator.BatchSummary) {
+	var files int
+	var size int64
+	fmt.Fprintln(w, "\n📊 Batch summary:")
+	for _, result := range summary.Results {
+		if result.Err != nil {
+			fmt.Fprintf(w, "  ❌ %-24s %v\n", result.Config.Name, result.Err)
+			continue
+		}
+
+		detail := ""
+		if result.Summary != nil {
+			generated := result.Summary.SuccessCount - result.Summary.SkippedCount
+			files += generated
+			size += result.Summary.TotalSize
+			detail = fmt.Sprintf("%d files, %d KiB", generated, result.Summary.TotalSize/1024)
+		}
+		if result.Skipped > 0 {
+			detail += fmt.Sprintf(", %d unchanged", result.Skipped)
+		}
+		fmt.Fprintf(w, "  ✅ %-24s %-13s %-28s %v\n", result.Config.Name, result.Config.Tier, detail, result.Elapsed.Round(time.Millisecond))
+		for _, path := range result.Conflicts {
+			fmt.Fprintf(w, "     ⚠️  merge conflict to resolve: %s\n", path)
+		}
+	}
+
+	if len(summary.WorkspaceFiles) > 0 {
+		fmt.Fprintf(w, "\n🗂️  Workspace files: %s\n", strings.Join(summary.WorkspaceFiles, ", "))
+	}
+
+	stats := generator.SharedTemplateCache().Stats()
+	fmt.Fprintf(w, "\n%d of %d services generated (%d files, %d KiB) in %v; template cache hit rate %.1f%%\n",
+		len(summary.Results)-len(summary.Failures()), len(summary.Results), files, size/1024,
+		summary.Elapsed.Round(time.Millisecond), stats.HitRatio()*100)
+}
+
+// loadTierConfig returns the built-in template configuration of a tier,
+// merged with the tiers it extends, and adds the variables installed
+// template packs declare
+func loadTierConfig(tier config.TemplateTier) (*config.TemplateConfig, error) {
+	tierConfig, err := generator.BuiltinTierConfig(tier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s tier configuration: %w", tier, err)
+	}
+
+	root, err := generator.UserPackDir()
+	if err != nil {
+		return tierConfig, nil
+	}
+	packVariables, err := generator.InstalledPackVariables(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template pack variables: %w", err)
+	}
+	if len(packVariables) == 0 {
+		return tierConfig, nil
+	}
+	if tierConfig == nil {
+		tierConfig = &config.TemplateConfig{Tier: string(tier)}
+	}
+	return tierConfig.WithVariables(packVariables), nil
+}
+
+// showGenerated prints how many files a parallel run wrote and how long it took
+func showGenerated(w io.Writer, gen *generator.Generator) {
+	if summary := gen.Summary(); summary != nil {
+		fmt.Fprintf(w, "✅ Generated %d files in %v (parallel mode)\n", summary.SuccessCount-summary.SkippedCount, summary.Elapsed.Round(time.Microsecond))
+	}
+}
+
+// showGenerationStats prints per-file timings and how much work the template cache saved
+func showGenerationStats(w io.Writer, gen *generator.Generator) {
+	if summary := gen.Summary(); summary != nil {
+		fmt.Fprintln(w, "\n📊 Files:")
+		fmt.Fprintf(w, "  Generated:        %d (%d unchanged, %d failed)\n", summary.SuccessCount, summary.SkippedCount, summary.FailureCount)
+		fmt.Fprintf(w, "  Total size:       %d KiB\n", summary.TotalSize/1024)
+		fmt.Fprintf(w, "  Elapsed:          %v (%v of task time)\n", summary.Elapsed.Round(time.Microsecond), summary.TotalDuration.Round(time.Microsecond))
+
+		slowest := append([]generator.GenerationResult(nil), summary.Results...)
+		sort.Slice(slowest, func(i, j int) bool { return slowest[i].Duration > slowest[j].Duration })
+		fmt.Fprintln(w, "  Slowest:")
+		for _, result := range slowest[:min(3, len(slowest))] {
+			fmt.Fprintf(w, "    %-40s %v\n", result.Filename, result.Duration.Round(time.Microsecond))
+		}
+	}
+
+	stats := generator.SharedTemplateCache().Stats()
+	fmt.Fprintln(w, "\n📊 Template cache:")
+	fmt.Fprintf(w, "  Templates parsed: %d (%d from cache)\n", stats.ParseHits+stats.ParseMisses, stats.ParseHits)
+	fmt.Fprintf(w, "  Files rendered:   %d (%d from cache, %d of them from disk)\n", stats.RenderHits+stats.RenderMisses, stats.RenderHits, stats.DiskHits)
+	fmt.Fprintf(w, "  Hit rate:         %.1f%%\n", stats.HitRatio()*100)
+	fmt.Fprintf(w, "  Memory:           %d entries, %d KiB, %d evicted\n", stats.Entries, stats.Bytes/1024, stats.Evictions)
 }
 
-func loadConfiguration() (*config.ProjectConfig, error) {
-	var cfg config.ProjectConfig
+// loadConfiguration merges the configuration layers: tier defaults, the
+// org file, the --config file, TEMPLATE_HEALTH_* environment variables and
+// the flags set on cmd
+func loadConfiguration(cmd *cobra.Command) (*config.ResolvedConfig, error) {
+	var layers []*config.Layer
+
+	if orgFile := orgConfigFile(); orgFile != "" {
+		org, err := config.LoadLayer(config.LayerOrg, orgFile)
+		if err != nil {
+			return nil, err
+		}
+		warnDeprecatedConfig(orgFile, org)
+		layers = append(layers, org)
+	}
 
 	// Load from config file if specified
 	if configFile != "" {
-		data, err := os.ReadFile(configFile)
+		project, err := config.LoadLayer(config.LayerProject, configFile)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read config file: %w", err)
+			return nil, err
 		}
+		warnDeprecatedConfig(configFile, project)
+		layers = append(layers, project)
+	}
 
-		if err := yaml.Unmarshal(data, &cfg); err != nil {
-			return nil, fmt.Errorf("failed to parse config file: %w", err)
-		}
+	env, err := config.EnvLayer(os.LookupEnv)
+	if err != nil {
+		return nil, err
 	}
+	layers = append(layers, env)
 
 	// Override with command line flags
-	if projectName != "" {
-		cfg.Name = projectName
+	flags := config.NewLayer(config.LayerFlag)
+	for _, flag := range [][2]string{{"name", "name"}, {"tier", "tier"}, {"output", "output_dir"}, {"module", "go_module"}} {
+		if cmd.Flags().Changed(flag[0]) {
+			value, _ := cmd.Flags().GetString(flag[0])
+			if err := flags.Set(flag[1], value, "--"+flag[0]); err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	if tier != "" {
-		cfg.Tier = config.TemplateTier(tier)
+	for _, variable := range variables {
+		name, value, ok := strings.Cut(variable, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid variable '%s' (must be name=value)", variable)
+		}
+		if err := flags.Set("variables."+name, value, "--var"); err != nil {
+			return nil, err
+		}
 	}
 
-	if outputDir != "" {
-		cfg.OutputDir = outputDir
-	} else if cfg.OutputDir == "" {
-		cfg.OutputDir = cfg.Name
+	// Parse features flag
+	for _, feature := range features {
+		var path string
+		switch strings.ToLower(feature) {
+		case "opentelemetry", "otel":
+			path = "features.opentelemetry"
+		case "server-timing", "servertiming":
+			path = "features.server_timing"
+		case "cloudevents", "events":
+			path = "features.cloudevents"
+		case "kubernetes", "k8s":
+			path = "features.kubernetes"
+		case "typescript", "ts":
+			path = "features.typescript"
+		case "docker":
+			path = "features.docker"
+		default:
+			return nil, fmt.Errorf("unknown feature: %s", feature)
+		}
+		if err := flags.Set(path, "true", "--features"); err != nil {
+			return nil, err
+		}
+	}
+	layers = append(layers, flags)
+
+	resolved, err := config.Resolve(layers...)
+	if err != nil {
+		return nil, err
 	}
 
-	if goModule != "" {
-		cfg.GoModule = goModule
-	} else if cfg.GoModule == "" {
+	cfg := resolved.Config
+	if cfg.OutputDir == "" {
+		cfg.OutputDir = cfg.Name
+	}
+	if cfg.GoModule == "" && cfg.Name != "" {
 		cfg.GoModule = fmt.Sprintf("github.com/example/%s", cfg.Name)
 	}
+	return resolved, nil
+}
 
-	// Parse features flag
-	if len(features) > 0 {
-		for _, feature := range features {
-			switch strings.ToLower(feature) {
-			case "opentelemetry", "otel":
-				cfg.Features.OpenTelemetry = true
-			case "server-timing", "servertiming":
-				cfg.Features.ServerTiming = true
-			case "cloudevents", "events":
-				cfg.Features.CloudEvents = true
-			case "kubernetes", "k8s":
-				cfg.Features.Kubernetes = true
-			case "typescript", "ts":
-				cfg.Features.TypeScript = true
-			case "docker":
-				cfg.Features.Docker = true
-			default:
-				return nil, fmt.Errorf("unknown feature: %s", feature)
-			}
+// showValidationErrors lists configuration problems with their fixes
+func showValidationErrors(w io.Writer, problems config.ValidationErrors) {
+	fmt.Fprintf(w, "\n🔍 Dry run mode - the configuration has %d problems:\n", len(problems))
+	for _, problem := range problems {
+		fmt.Fprintf(w, "  ❌ %s: %s\n", problem.Path, problem.Message)
+		if problem.Suggestion != "" {
+			fmt.Fprintf(w, "     💡 %s\n", problem.Suggestion)
 		}
 	}
-
-	return &cfg, nil
 }
 
-func showConfigurationSummary(cfg *config.ProjectConfig) error {
-	fmt.Println("\n📋 Configuration Summary:")
-	fmt.Printf("  Project Name: %s\n", cfg.Name)
-	fmt.Printf("  Tier: %s\n", cfg.Tier)
-	fmt.Printf("  Description: %s\n", cfg.Tier.Description())
-	fmt.Printf("  Go Module: %s\n", cfg.GoModule)
-	fmt.Printf("  Output Directory: %s\n", cfg.OutputDir)
-
-	fmt.Println("\n🎛️  Features:")
-	fmt.Printf("  OpenTelemetry: %v\n", cfg.Features.OpenTelemetry)
-	fmt.Printf("  Server Timing: %v\n", cfg.Features.ServerTiming)
-	fmt.Printf("  CloudEvents: %v\n", cfg.Features.CloudEvents)
-	fmt.Printf("  Kubernetes: %v\n", cfg.Features.Kubernetes)
-	fmt.Printf("  TypeScript: %v\n", cfg.Features.TypeScript)
-	fmt.Printf("  Docker: %v\n", cfg.Features.Docker)
+func showConfigurationSummary(w io.Writer, cfg *config.ProjectConfig) error {
+	fmt.Fprintln(w, "\n📋 Configuration Summary:")
+	fmt.Fprintf(w, "  Project Name: %s\n", cfg.Name)
+	fmt.Fprintf(w, "  Tier: %s\n", cfg.Tier)
+	fmt.Fprintf(w, "  Description: %s\n", cfg.Tier.Description())
+	fmt.Fprintf(w, "  Go Module: %s\n", cfg.GoModule)
+	fmt.Fprintf(w, "  Output Directory: %s\n", cfg.OutputDir)
+
+	fmt.Fprintln(w, "\n🎛️  Features:")
+	fmt.Fprintf(w, "  OpenTelemetry: %v\n", cfg.Features.OpenTelemetry)
+	fmt.Fprintf(w, "  Server Timing: %v\n", cfg.Features.ServerTiming)
+	fmt.Fprintf(w, "  CloudEvents: %v\n", cfg.Features.CloudEvents)
+	fmt.Fprintf(w, "  Kubernetes: %v\n", cfg.Features.Kubernetes)
+	fmt.Fprintf(w, "  TypeScript: %v\n", cfg.Features.TypeScript)
+	fmt.Fprintf(w, "  Docker: %v\n", cfg.Features.Docker)
 
 	if cfg.Features.Kubernetes {
-		fmt.Println("\n☸️  Kubernetes Configuration:")
-		fmt.Printf("  Service Monitor: %v\n", cfg.Kubernetes.ServiceMonitor)
-		fmt.Printf("  Ingress: %v\n", cfg.Kubernetes.Ingress.Enabled)
-		fmt.Printf("  Health Probes: Liveness=%v, Readiness=%v, Startup=%v\n",
+		fmt.Fprintln(w, "\n☸️  Kubernetes Configuration:")
+		fmt.Fprintf(w, "  Service Monitor: %v\n", cfg.Kubernetes.ServiceMonitor)
+		fmt.Fprintf(w, "  Ingress: %v\n", cfg.Kubernetes.Ingress.Enabled)
+		fmt.Fprintf(w, "  Health Probes: Liveness=%v, Readiness=%v, Startup=%v\n",
 			cfg.Kubernetes.HealthProbes.LivenessProbe.Enabled,
 			cfg.Kubernetes.HealthProbes.ReadinessProbe.Enabled,
 			cfg.Kubernetes.HealthProbes.StartupProbe.Enabled)
@@ -236,8 +761,8 @@ func showConfigurationSummary(cfg *config.ProjectConfig) error {
 	return nil
 }
 
-func showGenerationPlan(cfg *config.ProjectConfig) error {
-	fmt.Println("\n📁 Files that would be generated:")
+func showGenerationPlan(w io.Writer, cfg *config.ProjectConfig) error {
+	fmt.Fprintln(w, "\n📁 Files that would be generated:")
 
 	// Core files
 	files := []string{
@@ -248,6 +773,7 @@ func showGenerationPlan(cfg *config.ProjectConfig) error {
 		"docker-compose.yml",
 		".gitignore",
 		"Makefile",
+		generator.ManifestFileName,
 	}
 
 	// Go source files
@@ -289,52 +815,81 @@ func showGenerationPlan(cfg *config.ProjectConfig) error {
 		}
 	}
 
+	// Per-environment files (if environments are configured)
+	var envFiles []string
+	if len(cfg.Environments) > 0 && cfg.Features.Kubernetes {
+		k8sFiles = append(k8sFiles, "deployments/kubernetes/kustomization.yaml")
+	}
+	for _, env := range cfg.EnvironmentNames() {
+		envFiles = append(envFiles, "configs/"+env+".yaml")
+		if cfg.Features.Kubernetes {
+			overlay := "deployments/kubernetes/overlays/" + env + "/"
+			envFiles = append(envFiles, overlay+"kustomization.yaml", overlay+"deployment-patch.yaml", overlay+"configmap-patch.yaml")
+		}
+	}
+
 	// Print file lists
-	printFileList("Core Files", files)
-	printFileList("Go Source Files", goFiles)
+	printFileList(w, "Core Files", files)
+	printFileList(w, "Go Source Files", goFiles)
 
 	if len(tsFiles) > 0 {
-		printFileList("TypeScript Client Files", tsFiles)
+		printFileList(w, "TypeScript Client Files", tsFiles)
 	}
 
 	if len(k8sFiles) > 0 {
-		printFileList("Kubernetes Manifests", k8sFiles)
+		printFileList(w, "Kubernetes Manifests", k8sFiles)
+	}
+
+	if len(envFiles) > 0 {
+		printFileList(w, "Environment Files", envFiles)
 	}
 
 	// Show estimated deployment time
-	fmt.Printf("\n⏱️  Estimated deployment time: %s\n", cfg.Tier.Description())
+	fmt.Fprintf(w, "\n⏱️  Estimated deployment time: %s\n", cfg.Tier.Description())
 
 	return nil
 }
 
-func printFileList(title string, files []string) {
-	fmt.Printf("\n  %s:\n", title)
+func printFileList(w io.Writer, title string, files []string) {
+	fmt.Fprintf(w, "\n  %s:\n", title)
 	for _, file := range files {
-		fmt.Printf("    - %s\n", file)
+		fmt.Fprintf(w, "    - %s\n", file)
 	}
 }
 
-func showSuccessMessage(cfg *config.ProjectConfig) error {
-	fmt.Printf("\n✅ Successfully generated %s tier health endpoint project!\n", cfg.Tier)
-	fmt.Printf("\n📁 Project created in: %s\n", cfg.OutputDir)
+func showSuccessMessage(w io.Writer, cfg *config.ProjectConfig) error {
+	fmt.Fprintf(w, "\n✅ Successfully generated %s tier health endpoint project!\n", cfg.Tier)
+	fmt.Fprintf(w, "\n📁 Project created in: %s\n", cfg.OutputDir)
 
-	fmt.Println("\n🚀 Next steps:")
-	fmt.Printf("  1. cd %s\n", cfg.OutputDir)
-	fmt.Println("  2. go mod tidy")
-	fmt.Println("  3. go run cmd/server/main.go")
-	fmt.Println("  4. curl http://localhost:8080/health")
+	fmt.Fprintln(w, "\n🚀 Next steps:")
+	fmt.Fprintf(w, "  1. cd %s\n", cfg.OutputDir)
+	fmt.Fprintln(w, "  2. go mod tidy")
+	fmt.Fprintln(w, "  3. go run cmd/server/main.go")
+	fmt.Fprintln(w, "  4. curl http://localhost:8080/health")
 
 	if cfg.Features.TypeScript {
-		fmt.Println("\n📦 TypeScript client:")
-		fmt.Printf("  cd %s/client/typescript && npm install\n", cfg.OutputDir)
+		fmt.Fprintln(w, "\n📦 TypeScript client:")
+		fmt.Fprintf(w, "  cd %s/client/typescript && npm install\n", cfg.OutputDir)
 	}
 
 	if cfg.Features.Kubernetes {
-		fmt.Println("\n☸️  Kubernetes deployment:")
-		fmt.Printf("  kubectl apply -f %s/deployments/kubernetes/\n", cfg.OutputDir)
+		fmt.Fprintln(w, "\n☸️  Kubernetes deployment:")
+		if len(cfg.Environments) == 0 {
+			fmt.Fprintf(w, "  kubectl apply -f %s/deployments/kubernetes/\n", cfg.OutputDir)
+		}
+		for _, env := range cfg.EnvironmentNames() {
+			fmt.Fprintf(w, "  kubectl apply -k %s/deployments/kubernetes/overlays/%s\n", cfg.OutputDir, env)
+		}
+	}
+
+	if len(cfg.Environments) > 0 {
+		fmt.Fprintln(w, "\n🌍 Environment configs:")
+		for _, env := range cfg.EnvironmentNames() {
+			fmt.Fprintf(w, "  %s/configs/%s.yaml\n", cfg.OutputDir, env)
+		}
 	}
 
-	fmt.Printf("\n📚 Documentation: %s/README.md\n", cfg.OutputDir)
+	fmt.Fprintf(w, "\n📚 Documentation: %s/README.md\n", cfg.OutputDir)
 
 	return nil
 }
@@ -1,13 +1,19 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/config"
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/generator"
 )
 
 var (
@@ -40,7 +46,9 @@ The migration process:
 3. Shows migration plan with added/removed features
 4. Updates dependencies and configurations
 5. Adds new code components
-6. Updates documentation
+6. Wires new routes, middleware and imports into internal/server/server.go
+   with Go AST edits, leaving your own routes and code untouched
+7. Updates documentation
 
 Examples:
   # Migrate from basic to intermediate tier
@@ -131,6 +139,9 @@ func runMigrateProject(cmd *cobra.Command, args []string) error {
 		if len(op.Files) > 0 {
 			for _, file := range op.Files {
 				fmt.Printf("     - %s\n", file)
+				for _, edit := range op.Edits[file] {
+					fmt.Printf("       + %s\n", edit)
+				}
 			}
 		}
 	}
@@ -189,6 +200,13 @@ type MigrationOperation struct {
 	Description string
 	Files       []string
 	Commands    []string
+
+	// Wiring is the server wiring a "modify" operation patches into its files
+	Wiring *generator.WiringPatch
+
+	// Edits and Content hold the planned edits and patched content per file
+	Edits   map[string][]generator.WiringEdit
+	Content map[string][]byte
 }
 
 // MigrationPlan holds the complete migration plan
@@ -259,15 +277,118 @@ func createMigrationPlan(projectInfo *ProjectInfo, targetTier string, path []str
 		fromTier := path[i]
 		toTier := path[i+1]
 
-		ops := generateTierTransitionOperations(fromTier, toTier)
+		ops := generateTierTransitionOperations(fromTier, toTier, projectInfo.Module)
 		plan.Operations = append(plan.Operations, ops...)
 	}
 
+	if err := planAddedFiles(projectInfo, plan); err != nil {
+		return nil, err
+	}
+
+	if err := planWiringEdits(projectInfo.Path, plan); err != nil {
+		return nil, err
+	}
+
 	return plan, nil
 }
 
+// planAddedFiles renders the files every "add" operation takes from the
+// target tier with the project's configuration, through the generator's
+// templates and the overlays in the project directory
+func planAddedFiles(projectInfo *ProjectInfo, plan *MigrationPlan) error {
+	tiers, err := generator.BuiltinTierRegistry()
+	if err != nil {
+		return err
+	}
+	registry, err := generator.NewOverlayRegistry(projectInfo.Path)
+	if err != nil {
+		return fmt.Errorf("failed to load templates: %w", err)
+	}
+	ctx := migrationContext(projectInfo, plan.ToTier)
+
+	for i := range plan.Operations {
+		op := &plan.Operations[i]
+		if op.Type != "add" {
+			continue
+		}
+
+		rendered, err := tiers.RenderFiles(registry, plan.ToTier, op.Files, ctx)
+		if err != nil {
+			return fmt.Errorf("failed to render %s tier files: %w", plan.ToTier, err)
+		}
+		op.Content = make(map[string][]byte)
+		for _, file := range rendered {
+			op.Content[file.Path] = file.Content
+		}
+	}
+
+	return nil
+}
+
+// migrationContext returns the context the added files are rendered with:
+// the manifest's at the target tier, or for projects generated before
+// manifests existed, the detected name and module
+func migrationContext(projectInfo *ProjectInfo, tier string) *generator.GenerationContext {
+	if manifest := projectInfo.Manifest; manifest != nil && manifest.Config != nil {
+		cfg := *manifest.Config
+		cfg.Tier = config.TemplateTier(tier)
+		ctx := manifest.Context()
+		ctx.Config = &cfg
+		return ctx
+	}
+
+	return &generator.GenerationContext{
+		Config: &config.ProjectConfig{
+			Name:     projectInfo.Name,
+			GoModule: projectInfo.Module,
+			Tier:     config.TemplateTier(tier),
+		},
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Version:   generator.GeneratorVersion,
+	}
+}
+
+// planWiringEdits patches the server wiring of every "modify" operation in
+// memory so the plan can list each edit. Operations build on each other,
+// so a multi-tier migration patches the result of the previous step.
+func planWiringEdits(targetDir string, plan *MigrationPlan) error {
+	sources := make(map[string][]byte)
+
+	for i := range plan.Operations {
+		op := &plan.Operations[i]
+		if op.Type != "modify" || op.Wiring == nil {
+			continue
+		}
+
+		op.Edits = make(map[string][]generator.WiringEdit)
+		op.Content = make(map[string][]byte)
+
+		for _, file := range op.Files {
+			src, ok := sources[file]
+			if !ok {
+				data, err := os.ReadFile(filepath.Join(targetDir, file))
+				if err != nil {
+					return fmt.Errorf("failed to read %s: %w", file, err)
+				}
+				src = data
+			}
+
+			patched, edits, err := generator.PatchServerWiring(file, src, *op.Wiring)
+			if err != nil {
+				return fmt.Errorf("failed to plan edits of %s: %w", file, err)
+			}
+
+			sources[file] = patched
+			op.Edits[file] = edits
+			op.Content[file] = patched
+		}
+	}
+
+	return nil
+}
+
 // generateTierTransitionOperations generates operations for a single tier transition
-func generateTierTransitionOperations(fromTier, toTier string) []MigrationOperation {
+func generateTierTransitionOperations(fromTier, toTier, module string) []MigrationOperation {
 	var operations []MigrationOperation
 
 	switch fromTier + "→" + toTier {
@@ -279,8 +400,13 @@ func generateTierTransitionOperations(fromTier, toTier string) []MigrationOperat
 		})
 		operations = append(operations, MigrationOperation{
 			Type:        "modify",
-			Description: "Update server configuration for dependency checks",
-			Files:       []string{"internal/server/server.go", "internal/config/config.go"},
+			Description: "Register dependency health check route",
+			Files:       []string{"internal/server/server.go"},
+			Wiring: &generator.WiringPatch{
+				Routes: []generator.Route{
+					{Method: "GET", Path: "/dependencies", Handler: "DependenciesCheck"},
+				},
+			},
 		})
 		operations = append(operations, MigrationOperation{
 			Type:        "dependency",
@@ -292,7 +418,19 @@ func generateTierTransitionOperations(fromTier, toTier string) []MigrationOperat
 		operations = append(operations, MigrationOperation{
 			Type:        "add",
 			Description: "Add OpenTelemetry observability",
-			Files:       []string{"internal/middleware/server_timing.go", "internal/events/health_events.go"},
+			Files:       []string{"internal/middleware/server_timing.go", "internal/events/health_events.go", "internal/handlers/metrics.go"},
+		})
+		operations = append(operations, MigrationOperation{
+			Type:        "modify",
+			Description: "Wire server timing middleware and metrics route",
+			Files:       []string{"internal/server/server.go"},
+			Wiring: &generator.WiringPatch{
+				Imports:    []string{module + "/internal/middleware"},
+				Middleware: []string{"middleware.ServerTimingMiddleware"},
+				Routes: []generator.Route{
+					{Method: "GET", Path: "/metrics", Handler: "MetricsCheck"},
+				},
+			},
 		})
 		operations = append(operations, MigrationOperation{
 			Type:        "add",
@@ -321,12 +459,24 @@ func generateTierTransitionOperations(fromTier, toTier string) []MigrationOperat
 		})
 		operations = append(operations, MigrationOperation{
 			Type:        "modify",
-			Description: "Update server for enterprise features",
-			Files:       []string{"cmd/server/main.go", "internal/server/server.go"},
+			Description: "Wire mTLS, audit and RBAC middleware",
+			Files:       []string{"internal/server/server.go"},
+			Wiring:      enterpriseWiring(module),
 		})
 
 	// Downgrade operations
 	case "intermediate→basic":
+		operations = append(operations, MigrationOperation{
+			Type:        "modify",
+			Description: "Unregister dependency health check route",
+			Files:       []string{"internal/server/server.go"},
+			Wiring: &generator.WiringPatch{
+				Routes: []generator.Route{
+					{Method: "GET", Path: "/dependencies", Handler: "DependenciesCheck"},
+				},
+				Remove: true,
+			},
+		})
 		operations = append(operations, MigrationOperation{
 			Type:        "remove",
 			Description: "Remove dependency health check handlers",
@@ -334,13 +484,34 @@ func generateTierTransitionOperations(fromTier, toTier string) []MigrationOperat
 		})
 
 	case "advanced→intermediate":
+		operations = append(operations, MigrationOperation{
+			Type:        "modify",
+			Description: "Unwire server timing middleware and metrics route",
+			Files:       []string{"internal/server/server.go"},
+			Wiring: &generator.WiringPatch{
+				Imports:    []string{module + "/internal/middleware"},
+				Middleware: []string{"middleware.ServerTimingMiddleware"},
+				Routes: []generator.Route{
+					{Method: "GET", Path: "/metrics", Handler: "MetricsCheck"},
+				},
+				Remove: true,
+			},
+		})
 		operations = append(operations, MigrationOperation{
 			Type:        "remove",
 			Description: "Remove OpenTelemetry and CloudEvents features",
-			Files:       []string{"internal/middleware/server_timing.go", "internal/events/"},
+			Files:       []string{"internal/middleware/server_timing.go", "internal/events/", "internal/handlers/metrics.go"},
 		})
 
 	case "enterprise→advanced":
+		unwire := enterpriseWiring(module)
+		unwire.Remove = true
+		operations = append(operations, MigrationOperation{
+			Type:        "modify",
+			Description: "Unwire mTLS, audit and RBAC middleware",
+			Files:       []string{"internal/server/server.go"},
+			Wiring:      unwire,
+		})
 		operations = append(operations, MigrationOperation{
 			Type:        "remove",
 			Description: "Remove enterprise security and compliance features",
@@ -351,6 +522,19 @@ func generateTierTransitionOperations(fromTier, toTier string) []MigrationOperat
 	return operations
 }
 
+// enterpriseWiring is the middleware the enterprise tier adds to the router.
+// mTLS goes first, so RBAC sees the client identity it extracts.
+func enterpriseWiring(module string) *generator.WiringPatch {
+	return &generator.WiringPatch{
+		Imports: []string{module + "/internal/security", module + "/internal/compliance"},
+		Middleware: []string{
+			"security.MTLSMiddleware",
+			"compliance.AuditMiddleware(compliance.DefaultAuditLogger())",
+			"security.RBACMiddleware(security.DefaultRBACPolicy())",
+		},
+	}
+}
+
 // applyMigration applies the migration plan
 func applyMigration(targetDir string, plan *MigrationPlan) error {
 	fmt.Printf("\n🔄 Applying migration...\n")
@@ -360,7 +544,7 @@ func applyMigration(targetDir string, plan *MigrationPlan) error {
 
 		switch op.Type {
 		case "add":
-			if err := addMigrationFiles(targetDir, plan.ToTier, op.Files); err != nil {
+			if err := addMigrationFiles(targetDir, op); err != nil {
 				return fmt.Errorf("failed to add files: %w", err)
 			}
 
@@ -370,7 +554,7 @@ func applyMigration(targetDir string, plan *MigrationPlan) error {
 			}
 
 		case "modify":
-			if err := modifyMigrationFiles(targetDir, plan.FromTier, plan.ToTier, op.Files); err != nil {
+			if err := modifyMigrationFiles(targetDir, op); err != nil {
 				return fmt.Errorf("failed to modify files: %w", err)
 			}
 
@@ -389,21 +573,17 @@ func applyMigration(targetDir string, plan *MigrationPlan) error {
 	return nil
 }
 
-// addMigrationFiles copies files from the target tier template
-func addMigrationFiles(targetDir, toTier string, files []string) error {
-	templateDir := filepath.Join("templates", toTier)
-
-	for _, file := range files {
-		srcPath := filepath.Join(templateDir, file)
-		dstPath := filepath.Join(targetDir, file)
+// addMigrationFiles writes the files an "add" operation rendered from the target tier
+func addMigrationFiles(targetDir string, op MigrationOperation) error {
+	for file, content := range op.Content {
+		dstPath := filepath.Join(targetDir, filepath.FromSlash(file))
 
 		// Create directory if needed
 		if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
 			return err
 		}
 
-		// Copy file or directory
-		if err := copyFileOrDir(srcPath, dstPath); err != nil {
+		if err := os.WriteFile(dstPath, content, 0644); err != nil {
 			return err
 		}
 	}
@@ -422,11 +602,20 @@ func removeMigrationFiles(targetDir string, files []string) error {
 	return nil
 }
 
-// modifyMigrationFiles updates existing files for the new tier
-func modifyMigrationFiles(targetDir, fromTier, toTier string, files []string) error {
-	// This would contain the logic to update existing files
-	// For now, we'll just copy the new versions
-	return addMigrationFiles(targetDir, toTier, files)
+// modifyMigrationFiles writes the patched files of a "modify" operation
+func modifyMigrationFiles(targetDir string, op MigrationOperation) error {
+	for _, file := range op.Files {
+		if len(op.Edits[file]) == 0 {
+			continue
+		}
+		for _, edit := range op.Edits[file] {
+			fmt.Printf("     %s: %s\n", file, edit)
+		}
+		if err := os.WriteFile(filepath.Join(targetDir, file), op.Content[file], 0644); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // runMigrationCommands runs shell commands for the migration
@@ -439,18 +628,29 @@ func runMigrationCommands(targetDir string, commands []string) error {
 	return nil
 }
 
-// updateProjectMetadata updates the project metadata file
+// updateProjectMetadata records the new tier in the generation manifest
 func updateProjectMetadata(targetDir, newTier string) error {
-	metadataPath := filepath.Join(targetDir, ".template-metadata.yaml")
+	manifest, err := generator.LoadManifest(targetDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Projects generated before manifests existed get a minimal one
+		info, err := analyzeProjectStructure(targetDir)
+		if err != nil {
+			return err
+		}
+		manifest = generator.NewManifest(&config.ProjectConfig{
+			Name:     info.Name,
+			GoModule: info.Module,
+		}, "")
+	} else if err != nil {
+		return err
+	}
 
-	// Create or update metadata file
-	metadata := fmt.Sprintf(`name: %s
-tier: %s
-version: "1.0.0"
-migrated_at: %d
-`, filepath.Base(targetDir), newTier, getCurrentTimestamp())
+	manifest.Tier = newTier
+	if manifest.Config != nil {
+		manifest.Config.Tier = config.TemplateTier(newTier)
+	}
 
-	return os.WriteFile(metadataPath, []byte(metadata), 0644)
+	return manifest.Save(targetDir)
 }
 
 // createProjectBackup creates a full backup of the project
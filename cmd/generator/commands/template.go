@@ -1,23 +1,23 @@
 package commands
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"text/template"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
-)
+	"github.com/spf13/viper"
 
-// TemplateMetadata represents the metadata for a template tier
-type TemplateMetadata struct {
-	Name        string            `yaml:"name"`
-	Description string            `yaml:"description"`
-	Tier        string            `yaml:"tier"`
-	Features    map[string]bool   `yaml:"features"`
-	Version     string            `yaml:"version"`
-}
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/config"
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/generator"
+)
 
 // templateCmd represents the template command
 var templateCmd = &cobra.Command{
@@ -30,16 +30,20 @@ var templateCmd = &cobra.Command{
 var listTemplatesCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available template tiers",
-	Long:  `List all available template tiers with their descriptions and features.`,
-	RunE:  runListTemplates,
+	Long: `List all available template tiers with their descriptions and features.
+
+With --installed, list the template packs installed with 'template install'
+instead, with their versions and signing keys.`,
+	RunE: runListTemplates,
 }
 
-// generateFromTemplateCmd generates a project from static template
+// generateFromTemplateCmd generates a project from a template tier
 var generateFromTemplateCmd = &cobra.Command{
 	Use:   "from-static",
-	Short: "Generate project from static template directory",
-	Long:  `Generate a new project from a static template directory.`,
-	RunE:  runGenerateFromTemplate,
+	Short: "Generate project from a template tier",
+	Long: `Generate a new project from a template tier, rendered through the same templates and
+file list as generate with the tier defaults filled in.`,
+	RunE: runGenerateFromTemplate,
 }
 
 // validateTemplatesCmd validates template directories
@@ -50,11 +54,175 @@ var validateTemplatesCmd = &cobra.Command{
 	RunE:  runValidateTemplates,
 }
 
+// whichTemplateCmd shows which overlay layer provides a named template
+var whichTemplateCmd = &cobra.Command{
+	Use:   "which <name>",
+	Short: "Show which layer provides a named template",
+	Long: `Show where a named template such as go-server or dockerfile is loaded from.
+
+Named templates are looked up along an overlay search path, highest precedence first:
+  --template-dir directories (later directories win)
+  <project>/.template-health/templates
+  ~/.template-health-endpoint/templates
+  installed template packs, ~/.template-health-endpoint/packs/<name>
+  built-in templates
+
+A file named after the template, such as go-server.tmpl, in any of these
+directories replaces the template from the layers below it.
+
+Examples:
+  template-health-endpoint template which go-server
+  template-health-endpoint template which dockerfile --project ./my-service`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWhichTemplate,
+}
+
+// testTemplatesCmd compares rendered projects with golden copies
+var testTemplatesCmd = &cobra.Command{
+	Use:   "test [golden-dir]",
+	Short: "Compare rendered projects with golden files",
+	Long: `Render fixture configurations and compare the output byte for byte with
+checked-in golden projects. Nothing is written outside the golden directory
+and no network access is needed.
+
+Each subdirectory of the golden directory (default: ` + generator.DefaultGoldenDir + `)
+is a test case:
+  <case>/config.yaml   project configuration, as passed to generate --config
+  <case>/output/       the project it must render to
+
+Templates are loaded as generate loads them: built-in, installed packs, the
+user and project overlays, then --template-dir. Mismatching files are shown as unified diffs.
+--update rewrites the golden output of every mismatching case instead; add a
+case by writing its config.yaml and running with --update.
+
+Examples:
+  template-health-endpoint template test
+  template-health-endpoint template test --template-dir ./my-templates
+  template-health-endpoint template test --update`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runTestTemplates,
+}
+
+// shippedTemplateDirs are the template trees of the repository that lint
+// checks by default besides the named templates
+var shippedTemplateDirs = []string{config.DefaultTemplatesDir, "template-health/templates"}
+
+// lintTemplatesCmd checks templates against the generation data model
+var lintTemplatesCmd = &cobra.Command{
+	Use:   "lint [path...]",
+	Short: "Check templates for undefined fields, unknown functions and unused conditionals",
+	Long: `Parse templates and resolve every field chain such as .Config.Kubernetes.Port
+against the Go types templates are rendered with (GenerationContext and
+ProjectConfig), reporting problems as file:line:col:
+  undefined-field     a field or method that does not exist, such as .Config.Owner
+  unknown-function    a function that is neither built in nor in the function library
+  unused-conditional  an if/with with an empty body, a constant condition or a
+                      condition on a struct, which is always true
+  parse-error         a template that does not parse
+
+Without arguments, the named templates generate uses are checked: built-in,
+installed packs, the user and project overlays and --template-dir, plus the
+static tiers in templates/ and the SRE templates in template-health/templates
+when run from the repository. Paths name template files or directories, whose
+.tmpl, .go, .yaml, .yml, .json, .ts and .sh files, go.mod and README.md are checked.
+
+Examples:
+  template-health-endpoint template lint
+  template-health-endpoint template lint templates template-health/templates`,
+	RunE: runLintTemplates,
+}
+
+// packTemplateCmd bundles a template directory into a signed pack
+var packTemplateCmd = &cobra.Command{
+	Use:   "pack <dir>",
+	Short: "Bundle a template directory into a signed, versioned pack",
+	Long: `Bundle a template directory into a single <name>-<version>.tgz file that
+can be installed offline with 'template install'.
+
+The directory needs a template.yaml with the name and version of the pack
+and any variable declarations; every other file is bundled as is. Named
+templates such as go-server.tmpl at the top level override the built-in
+templates once the pack is installed, and the variables it declares can be
+set with generate --var. The pack holds a manifest with the
+SHA-256 of every file, signed with an ed25519 key (PKCS #8 PEM, as written by
+'template keygen' or openssl genpkey -algorithm ed25519).
+
+Examples:
+  template-health-endpoint template keygen acme
+  template-health-endpoint template pack ./acme-templates --key acme.key`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPackTemplates,
+}
+
+// installTemplateCmd verifies and installs a template pack
+var installTemplateCmd = &cobra.Command{
+	Use:   "install <file>",
+	Short: "Verify and install a template pack",
+	Long: `Verify a template pack made with 'template pack' and install it into
+~/.template-health-endpoint/packs, where it joins the template search path
+above the built-in templates and below the user and project overlays.
+
+The pack must be signed by one of the public keys listed under trusted_keys
+in the user config (~/.template-health-endpoint.yaml or --config); a config
+file in the current directory or environment variables are never trusted:
+
+  trusted_keys:
+    - <contents of the publisher's .pub file>
+
+Installing another version of an installed pack replaces it.
+
+Examples:
+  template-health-endpoint template install acme-templates-1.2.0.tgz`,
+	Args: cobra.ExactArgs(1),
+	RunE: runInstallTemplates,
+}
+
+// keygenTemplateCmd creates a key pair for signing template packs
+var keygenTemplateCmd = &cobra.Command{
+	Use:   "keygen <name>",
+	Short: "Create an ed25519 key pair for signing template packs",
+	Long: `Create <name>.key, the private key 'template pack --key' signs with, and
+<name>.pub, the public key installers list under trusted_keys.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runKeygenTemplates,
+}
+
+var (
+	listInstalled     bool
+	packKeyFile       string
+	packOutput        string
+	lintTemplateDirs  []string
+	whichProjectDir   string
+	whichTemplateDirs []string
+	testTemplateDirs  []string
+	testUpdate        bool
+)
+
 func init() {
 	// Add template subcommands
 	templateCmd.AddCommand(listTemplatesCmd)
 	templateCmd.AddCommand(generateFromTemplateCmd)
 	templateCmd.AddCommand(validateTemplatesCmd)
+	templateCmd.AddCommand(whichTemplateCmd)
+	templateCmd.AddCommand(testTemplatesCmd)
+	templateCmd.AddCommand(lintTemplatesCmd)
+	templateCmd.AddCommand(packTemplateCmd)
+	templateCmd.AddCommand(installTemplateCmd)
+	templateCmd.AddCommand(keygenTemplateCmd)
+
+	listTemplatesCmd.Flags().BoolVar(&listInstalled, "installed", false, "list installed template packs instead of tiers")
+
+	packTemplateCmd.Flags().StringVar(&packKeyFile, "key", "", "ed25519 private key to sign the pack with (required)")
+	packTemplateCmd.Flags().StringVarP(&packOutput, "output", "o", "", "pack file to write (default: <name>-<version>.tgz)")
+	packTemplateCmd.MarkFlagRequired("key")
+
+	whichTemplateCmd.Flags().StringVar(&whichProjectDir, "project", ".", "project directory whose .template-health/templates overlay applies")
+	whichTemplateCmd.Flags().StringSliceVar(&whichTemplateDirs, "template-dir", []string{}, "additional override directories, as passed to generate")
+
+	testTemplatesCmd.Flags().StringSliceVar(&testTemplateDirs, "template-dir", []string{}, "additional override directories, as passed to generate")
+	testTemplatesCmd.Flags().BoolVar(&testUpdate, "update", false, "rewrite mismatching golden files with the rendered output")
+
+	lintTemplatesCmd.Flags().StringSliceVar(&lintTemplateDirs, "template-dir", []string{}, "additional override directories, as passed to generate")
 
 	// Add flags for generate-from-template
 	generateFromTemplateCmd.Flags().StringP("name", "n", "", "Project name (required)")
@@ -70,34 +238,26 @@ func init() {
 }
 
 func runListTemplates(cmd *cobra.Command, args []string) error {
-	templatesDir := "templates"
-
-	fmt.Println("📋 Available Template Tiers:")
-	fmt.Println("=" + fmt.Sprintf("%*s", 50, ""))
+	if listInstalled {
+		return listInstalledPacks()
+	}
 
-	// Read template directories
-	entries, err := os.ReadDir(templatesDir)
+	tiers, err := generator.LoadTierRegistry(config.DefaultTemplatesDir)
 	if err != nil {
-		return fmt.Errorf("failed to read templates directory: %w", err)
+		return fmt.Errorf("failed to load template tiers: %w", err)
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-
-		tierName := entry.Name()
-		metadataPath := filepath.Join(templatesDir, tierName, "template.yaml")
+	fmt.Println("📋 Available Template Tiers:")
+	fmt.Println("=" + fmt.Sprintf("%*s", 50, ""))
 
-		// Read template metadata
-		metadata, err := readTemplateMetadata(metadataPath)
-		if err != nil {
-			fmt.Printf("⚠️  %s: No metadata found\n", tierName)
-			continue
-		}
+	for _, tier := range tiers.Tiers() {
+		metadata := tier.Config
 
 		fmt.Printf("\n🎯 **%s** (%s)\n", metadata.Name, metadata.Version)
 		fmt.Printf("   %s\n", metadata.Description)
+		if tier.Parent != nil {
+			fmt.Printf("   Extends: %s\n", tier.Parent.Name)
+		}
 		fmt.Printf("   Features: ")
 
 		var features []string
@@ -106,6 +266,7 @@ func runListTemplates(cmd *cobra.Command, args []string) error {
 				features = append(features, feature)
 			}
 		}
+		sort.Strings(features)
 
 		if len(features) > 0 {
 			for i, feature := range features {
@@ -139,38 +300,46 @@ func runGenerateFromTemplate(cmd *cobra.Command, args []string) error {
 		description = fmt.Sprintf("%s health endpoint service", name)
 	}
 
-	fmt.Printf("🚀 Generating project from %s template...\n", tier)
-
-	// Validate template exists
-	templateDir := filepath.Join("templates", tier)
-	if _, err := os.Stat(templateDir); os.IsNotExist(err) {
-		return fmt.Errorf("template tier '%s' not found in templates directory", tier)
+	// Fill in the tier defaults exactly as generate does
+	flags := config.NewLayer(config.LayerFlag)
+	for _, setting := range [][3]string{
+		{"name", name, "--name"},
+		{"tier", tier, "--tier"},
+		{"go_module", module, "--module"},
+		{"output_dir", output, "--output"},
+		{"description", description, "--description"},
+	} {
+		if err := flags.Set(setting[0], setting[1], setting[2]); err != nil {
+			return err
+		}
 	}
-
-	// Read template metadata
-	metadataPath := filepath.Join(templateDir, "template.yaml")
-	metadata, err := readTemplateMetadata(metadataPath)
+	resolved, err := config.Resolve(flags)
 	if err != nil {
-		return fmt.Errorf("failed to read template metadata: %w", err)
+		return err
 	}
+	cfg := resolved.Config
 
-	fmt.Printf("📋 Using template: %s (%s)\n", metadata.Description, metadata.Version)
+	tierConfig, err := loadTierConfig(cfg.Tier)
+	if err != nil {
+		return err
+	}
+	if tierConfig != nil {
+		fmt.Printf("📋 Using template: %s (%s)\n", tierConfig.Description, tierConfig.Version)
+	}
 
-	// Create template context
-	context := map[string]interface{}{
-		"Config": map[string]interface{}{
-			"Name":        name,
-			"Description": description,
-			"GoModule":    module,
-			"Tier":       tier,
-			"Features":   metadata.Features,
-		},
-		"Version":   metadata.Version,
-		"Timestamp": "2024-01-01T00:00:00Z", // TODO: Use actual timestamp
+	// Render through the same registry and file list as the generate command
+	registry, err := generator.NewOverlayRegistry(".")
+	if err != nil {
+		return fmt.Errorf("failed to load templates: %w", err)
+	}
+	gen, err := generator.NewWithRegistry(cfg, registry)
+	if err != nil {
+		return fmt.Errorf("failed to create generator: %w", err)
 	}
+	gen.SetTemplateConfig(tierConfig)
 
-	// Generate project from template
-	if err := generateFromStaticTemplate(templateDir, output, context); err != nil {
+	fmt.Printf("🚀 Generating project from %s template...\n", tier)
+	if err := gen.Generate(); err != nil {
 		return fmt.Errorf("failed to generate project: %w", err)
 	}
 
@@ -181,10 +350,15 @@ func runGenerateFromTemplate(cmd *cobra.Command, args []string) error {
 }
 
 func runValidateTemplates(cmd *cobra.Command, args []string) error {
-	templatesDir := "templates"
+	templatesDir := config.DefaultTemplatesDir
 
 	fmt.Println("🔍 Validating template directories...")
 
+	tiers, err := generator.LoadTierRegistry(templatesDir)
+	if err != nil {
+		return fmt.Errorf("failed to load template tiers: %w", err)
+	}
+
 	entries, err := os.ReadDir(templatesDir)
 	if err != nil {
 		return fmt.Errorf("failed to read templates directory: %w", err)
@@ -192,19 +366,24 @@ func runValidateTemplates(cmd *cobra.Command, args []string) error {
 
 	valid := true
 
+	// A directory without template.yaml is not loaded as a tier at all
 	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
+		if _, ok := tiers.Tier(entry.Name()); entry.IsDir() && !ok {
+			fmt.Printf("\n📋 Validating %s template...\n", entry.Name())
+			fmt.Printf("❌ Missing required file: %s\n", config.TemplateConfigFile)
+			valid = false
 		}
+	}
 
-		tierName := entry.Name()
-		templateDir := filepath.Join(templatesDir, tierName)
-
-		fmt.Printf("\n📋 Validating %s template...\n", tierName)
+	for _, tier := range tiers.Tiers() {
+		fmt.Printf("\n📋 Validating %s template...\n", tier.Name)
+		if chain := tier.Chain(); len(chain) > 1 {
+			fmt.Printf("   Extends: %s\n", strings.Join(chain[:len(chain)-1], " → "))
+		}
+		fmt.Printf("✅ Valid metadata\n")
 
-		// Check for required files
+		// Check for required files in the effective file set
 		requiredFiles := []string{
-			"template.yaml",
 			"cmd/server/main.go",
 			"internal/handlers/health.go",
 			"go.mod.tmpl",
@@ -212,8 +391,7 @@ func runValidateTemplates(cmd *cobra.Command, args []string) error {
 		}
 
 		for _, file := range requiredFiles {
-			filePath := filepath.Join(templateDir, file)
-			if _, err := os.Stat(filePath); os.IsNotExist(err) {
+			if _, ok := tier.File(file); !ok {
 				fmt.Printf("❌ Missing required file: %s\n", file)
 				valid = false
 			} else {
@@ -221,14 +399,34 @@ func runValidateTemplates(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		// Validate metadata
-		metadataPath := filepath.Join(templateDir, "template.yaml")
-		if _, err := readTemplateMetadata(metadataPath); err != nil {
-			fmt.Printf("❌ Invalid metadata: %v\n", err)
-			valid = false
-		} else {
-			fmt.Printf("✅ Valid metadata\n")
+		// Report the effective file set: + added, ~ overridden, - deleted
+		files := tier.Files()
+		fmt.Printf("📦 Effective files (%d):\n", len(files))
+		for _, file := range files {
+			switch {
+			case file.Tier != tier.Name:
+				fmt.Printf("     %s (from %s)\n", file.Path, file.Tier)
+			case file.Overrides != "":
+				fmt.Printf("   ~ %s (overrides %s)\n", file.Path, file.Overrides)
+			default:
+				fmt.Printf("   + %s\n", file.Path)
+			}
+		}
+		for _, file := range tier.Deleted {
+			fmt.Printf("   - %s (deleted)\n", file)
+		}
+	}
+
+	issues, err := tiers.Check()
+	if err != nil {
+		return fmt.Errorf("failed to compare template tiers: %w", err)
+	}
+	if len(issues) > 0 {
+		fmt.Println("\n⚠️  Divergences between tiers:")
+		for _, issue := range issues {
+			fmt.Printf("❌ %s\n", issue)
 		}
+		valid = false
 	}
 
 	if valid {
@@ -241,102 +439,345 @@ func runValidateTemplates(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func readTemplateMetadata(path string) (*TemplateMetadata, error) {
-	data, err := os.ReadFile(path)
+func runWhichTemplate(cmd *cobra.Command, args []string) error {
+	name := strings.TrimSuffix(args[0], ".tmpl")
+
+	registry, err := generator.NewOverlayRegistry(whichProjectDir, whichTemplateDirs...)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to load templates: %w", err)
 	}
 
-	var metadata TemplateMetadata
-	if err := yaml.Unmarshal(data, &metadata); err != nil {
-		return nil, err
+	providers := registry.Providers(name)
+	if len(providers) == 0 {
+		return fmt.Errorf("unknown template '%s'; available templates: %s", name, strings.Join(registry.Names(), ", "))
+	}
+
+	fmt.Printf("🔍 %s (highest precedence first):\n", name)
+	for i, layer := range providers {
+		marker := "   "
+		if i == 0 {
+			marker = "✅ "
+		}
+		fmt.Printf("  %s%s\n", marker, templateLayerLabel(layer, name))
 	}
 
-	return &metadata, nil
+	if len(providers) > 1 {
+		fmt.Printf("\n%s overrides %d other layer(s)\n", providers[0].Name, len(providers)-1)
+	}
+	return nil
 }
 
-func generateFromStaticTemplate(templateDir, outputDir string, context map[string]interface{}) error {
-	// Create output directory
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+func runTestTemplates(cmd *cobra.Command, args []string) error {
+	goldenDir := generator.DefaultGoldenDir
+	if len(args) > 0 {
+		goldenDir = args[0]
 	}
 
-	// Walk through template directory
-	return filepath.Walk(templateDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	cases, err := generator.LoadGoldenCases(goldenDir)
+	if err != nil {
+		return err
+	}
+	if len(cases) == 0 {
+		return fmt.Errorf("no test cases in %s; add <case>/%s files", goldenDir, generator.GoldenConfigFile)
+	}
+
+	registry, err := generator.NewOverlayRegistry(".", testTemplateDirs...)
+	if err != nil {
+		return fmt.Errorf("failed to load templates: %w", err)
+	}
 
-		// Skip template.yaml metadata file
-		if filepath.Base(path) == "template.yaml" {
-			return nil
+	stdout := cmd.OutOrStdout()
+	failed := 0
+	for _, c := range cases {
+		mismatches, err := c.Check(registry, testUpdate)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Fprintf(stdout, "❌ %s: %v\n", c.Name, err)
+		case len(mismatches) == 0:
+			fmt.Fprintf(stdout, "✅ %s\n", c.Name)
+		case testUpdate:
+			fmt.Fprintf(stdout, "📝 %s: updated %d golden files\n", c.Name, len(mismatches))
+		default:
+			failed++
+			fmt.Fprintf(stdout, "❌ %s: %d files differ\n", c.Name, len(mismatches))
+			for _, mismatch := range mismatches {
+				fmt.Fprint(stdout, mismatch.Diff)
+			}
 		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d golden test cases failed; run with --update to accept the rendered output", failed, len(cases))
+	}
+	return nil
+}
 
-		// Calculate relative path
-		relPath, err := filepath.Rel(templateDir, path)
+func runLintTemplates(cmd *cobra.Command, args []string) error {
+	funcs := generator.TemplateFuncs()
+
+	var issues []generator.LintIssue
+	if len(args) == 0 {
+		builtin, err := generator.BuiltinTemplateLayer()
 		if err != nil {
 			return err
 		}
+		layers := []generator.TemplateLayer{builtin}
+		for _, dir := range append(generator.OverlayDirs("."), lintTemplateDirs...) {
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				layers = append(layers, generator.TemplateLayer{Name: dir, FS: os.DirFS(dir)})
+			}
+		}
+		if issues, err = generator.LintLayers(layers, funcs); err != nil {
+			return err
+		}
 
-		// Calculate output path
-		outputPath := filepath.Join(outputDir, relPath)
+		// The static tiers and SRE templates ship with the repository too
+		for _, dir := range shippedTemplateDirs {
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				args = append(args, dir)
+			}
+		}
+	}
 
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return fmt.Errorf("failed to lint %s: %w", arg, err)
+		}
 		if info.IsDir() {
-			// Create directory
-			return os.MkdirAll(outputPath, info.Mode())
+			found, err := generator.LintFS(os.DirFS(arg), filepath.ToSlash(filepath.Clean(arg)), funcs)
+			if err != nil {
+				return err
+			}
+			issues = append(issues, found...)
+			continue
 		}
+		content, err := os.ReadFile(arg)
+		if err != nil {
+			return fmt.Errorf("failed to lint %s: %w", arg, err)
+		}
+		issues = append(issues, generator.LintSource(arg, string(content), funcs)...)
+	}
+
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("found %d template issues", len(issues))
+	}
+	fmt.Println("✅ No template issues found")
+	return nil
+}
+
+// templateLayerLabel describes where a layer's copy of a template lives
+func templateLayerLabel(layer generator.TemplateLayer, name string) string {
+	if layer.Name == generator.BuiltinLayer {
+		return fmt.Sprintf("%s.tmpl (built-in)", name)
+	}
+
+	file := filepath.Join(layer.Name, name+".tmpl")
+	switch {
+	case filepath.Clean(layer.Name) == filepath.Join(whichProjectDir, filepath.FromSlash(generator.ProjectTemplateDir)):
+		return file + " (project overlay)"
+	case isUserTemplateDir(layer.Name):
+		return file + " (user overlay)"
+	case isPackDir(layer.Name):
+		return file + " (installed pack)"
+	default:
+		return file + " (--template-dir)"
+	}
+}
 
-		// Process file
-		return processTemplateFile(path, outputPath, context)
-	})
+// isUserTemplateDir reports whether dir is the per-user overlay directory
+func isUserTemplateDir(dir string) bool {
+	userDir, err := generator.UserTemplateDir()
+	return err == nil && filepath.Clean(dir) == userDir
 }
 
-func processTemplateFile(inputPath, outputPath string, context map[string]interface{}) error {
-	// Read input file
-	content, err := os.ReadFile(inputPath)
+// isPackDir reports whether dir is the directory of an installed template pack
+func isPackDir(dir string) bool {
+	packDir, err := generator.UserPackDir()
+	return err == nil && filepath.Dir(filepath.Clean(dir)) == packDir
+}
+
+func runPackTemplates(cmd *cobra.Command, args []string) error {
+	keyData, err := os.ReadFile(packKeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read signing key: %w", err)
+	}
+	key, err := generator.ParsePackPrivateKey(keyData)
 	if err != nil {
 		return err
 	}
 
-	// Create output directory if needed
-	outputDir := filepath.Dir(outputPath)
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
+	var buf bytes.Buffer
+	manifest, err := generator.WritePack(&buf, args[0], key)
+	if err != nil {
+		return fmt.Errorf("failed to pack %s: %w", args[0], err)
+	}
+
+	output := packOutput
+	if output == "" {
+		output = manifest.FileName()
+	}
+	if err := os.WriteFile(output, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write pack: %w", err)
+	}
+
+	fmt.Printf("📦 Packed %s %s (%d files", manifest.Name, manifest.Version, len(manifest.Files))
+	if len(manifest.Variables) > 0 {
+		fmt.Printf(", variables: %s", strings.Join(manifest.Variables, ", "))
+	}
+	fmt.Printf(")\n🔏 Signed with key %s\n📁 %s\n", manifest.KeyID, output)
+	return nil
+}
+
+func runInstallTemplates(cmd *cobra.Command, args []string) error {
+	trusted, err := trustedPackKeys()
+	if err != nil {
 		return err
 	}
+	trustFile, _ := userConfigFile()
+	if len(trusted) == 0 {
+		return fmt.Errorf("no trusted keys configured; list the publisher's public key under trusted_keys in %s", trustFile)
+	}
 
-	// Remove .tmpl extension from output path
-	if filepath.Ext(outputPath) == ".tmpl" {
-		outputPath = outputPath[:len(outputPath)-5]
+	file, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open pack: %w", err)
 	}
+	defer file.Close()
 
-	// Check if file needs template processing
-	if filepath.Ext(inputPath) == ".tmpl" ||
-	   filepath.Base(inputPath) == "go.mod" ||
-	   filepath.Base(inputPath) == "README.md" ||
-	   filepath.Ext(inputPath) == ".go" ||
-	   filepath.Ext(inputPath) == ".yaml" ||
-	   filepath.Ext(inputPath) == ".yml" ||
-	   filepath.Ext(inputPath) == ".json" ||
-	   filepath.Ext(inputPath) == ".ts" ||
-	   filepath.Ext(inputPath) == ".sh" {
+	pack, err := generator.ReadPack(file, trusted)
+	if errors.Is(err, generator.ErrUntrustedPack) {
+		return fmt.Errorf("refusing to install %s: %w; list the publisher's public key under trusted_keys in %s", args[0], err, trustFile)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to verify %s: %w", args[0], err)
+	}
 
-		// Process as template
-		tmpl, err := template.New(filepath.Base(inputPath)).Parse(string(content))
-		if err != nil {
-			return fmt.Errorf("failed to parse template %s: %w", inputPath, err)
+	root, err := generator.UserPackDir()
+	if err != nil {
+		return err
+	}
+	previous, err := pack.Install(root)
+	if err != nil {
+		return err
+	}
+
+	manifest := pack.Manifest
+	fmt.Printf("🔏 Verified signature of key %s\n", manifest.KeyID)
+	switch {
+	case previous == nil:
+		fmt.Printf("✅ Installed %s %s\n", manifest.Name, manifest.Version)
+	case previous.Version == manifest.Version:
+		fmt.Printf("✅ Reinstalled %s %s\n", manifest.Name, manifest.Version)
+	default:
+		fmt.Printf("✅ Installed %s %s, replacing %s\n", manifest.Name, manifest.Version, previous.Version)
+	}
+	fmt.Printf("📁 %s\n", filepath.Join(root, manifest.Name))
+	return nil
+}
+
+func runKeygenTemplates(cmd *cobra.Command, args []string) error {
+	keyFile, pubFile := args[0]+".key", args[0]+".pub"
+	for _, file := range []string{keyFile, pubFile} {
+		if _, err := os.Stat(file); err == nil {
+			return fmt.Errorf("%s already exists", file)
+		}
+	}
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+	keyData, err := generator.MarshalPackPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(keyFile, keyData, 0600); err != nil {
+		return fmt.Errorf("failed to write private key: %w", err)
+	}
+	if err := os.WriteFile(pubFile, []byte(generator.FormatPackPublicKey(pub)+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write public key: %w", err)
+	}
+
+	fmt.Printf("🔑 Private key: %s (keep it secret)\n", keyFile)
+	fmt.Printf("📢 Public key:  %s (key %s)\n", pubFile, generator.PackKeyID(pub))
+	fmt.Printf("\n💡 Installers trust the key with this in their user config:\n\ntrusted_keys:\n  - %s\n", generator.FormatPackPublicKey(pub))
+	return nil
+}
+
+// listInstalledPacks prints the installed template packs and their versions
+func listInstalledPacks() error {
+	root, err := generator.UserPackDir()
+	if err != nil {
+		return err
+	}
+	packs, err := generator.InstalledPacks(root)
+	if err != nil {
+		return err
+	}
+	if len(packs) == 0 {
+		fmt.Println("No template packs installed; install one with 'template install <file>'")
+		return nil
+	}
+
+	fmt.Println("📦 Installed Template Packs:")
+	for _, pack := range packs {
+		fmt.Printf("\n🎯 **%s** (%s)\n", pack.Name, pack.Version)
+		if pack.Description != "" {
+			fmt.Printf("   %s\n", pack.Description)
+		}
+		fmt.Printf("   Files: %d, signed with key %s\n", len(pack.Files), pack.KeyID)
+		if len(pack.Variables) > 0 {
+			fmt.Printf("   Variables: %s\n", strings.Join(pack.Variables, ", "))
+		}
+		fmt.Printf("   Path: %s\n", filepath.Join(root, pack.Name))
+	}
+	return nil
+}
+
+// trustedPackKeys returns the public keys listed under trusted_keys in the
+// user config. Only that file is read: keys from a config file in the
+// working directory or from the environment could be planted by whoever
+// controls them.
+func trustedPackKeys() ([]ed25519.PublicKey, error) {
+	file, err := userConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	userConfig := viper.New()
+	userConfig.SetConfigFile(file)
+	userConfig.SetConfigType("yaml")
+	if err := userConfig.ReadInConfig(); err != nil {
+		if errors.Is(err, fs.ErrNotExist) && cfgFile == "" {
+			return nil, nil
 		}
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
 
-		// Create output file
-		outputFile, err := os.Create(outputPath)
+	var keys []ed25519.PublicKey
+	for _, value := range userConfig.GetStringSlice("trusted_keys") {
+		key, err := generator.ParsePackPublicKey(value)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("invalid trusted key %q: %w", value, err)
 		}
-		defer outputFile.Close()
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
 
-		// Execute template
-		return tmpl.Execute(outputFile, context)
-	} else {
-		// Copy file as-is
-		return os.WriteFile(outputPath, content, 0644)
+// userConfigFile returns the --config file, or else ~/.template-health-endpoint.yaml
+func userConfigFile() (string, error) {
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
 	}
+	return filepath.Join(home, ".template-health-endpoint.yaml"), nil
 }
@@ -1,16 +1,17 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	"gopkg.in/yaml.v3"
 
-	"github.com/LarsArtmann/BMAD-METHOD/pkg/config"
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/generator"
 )
 
 var (
@@ -20,6 +21,7 @@ var (
 	updateComponents   []string
 	updateShowDiff     bool
 	updateBackup       bool
+	updateConflicts    string
 )
 
 // updateCmd represents the update command
@@ -32,16 +34,22 @@ This command analyzes your existing project, compares it with the latest templat
 version, and applies updates while preserving your customizations.
 
 The update process:
-1. Detects current project template version and tier
-2. Compares with target template version
-3. Shows a diff of changes to be applied
-4. Applies updates with user confirmation
-5. Creates backup of modified files (optional)
-
-Available update strategies:
-  selective - Update only specific components (default)
-  full      - Update all template files
-  merge     - Merge template changes with existing customizations
+1. Reads the generation manifest (.template-metadata.yaml) of the project
+2. Renders the template version the project was generated from and the current one
+3. Three-way merges the template changes into your files
+4. Shows a diff of changes to be applied
+5. Applies updates with user confirmation
+6. Creates backup of modified files (optional)
+
+Change types:
+  add      - File is new in the current template version
+  modify   - File was not edited locally and is replaced
+  merge    - Local edits and template changes were merged cleanly
+  conflict - Local edits and template changes overlap
+  delete   - File is no longer generated and was not edited locally
+
+Conflicts are written as git-style markers by default. Use --conflicts rej to
+keep your version and write the rejected template hunks to <file>.rej instead.
 
 Examples:
   # Update current project to latest template version
@@ -53,6 +61,9 @@ Examples:
   # Show what would be updated without applying changes
   template-health-endpoint update --dry-run --show-diff
 
+  # Keep local versions of conflicting files and write .rej files
+  template-health-endpoint update --conflicts rej
+
   # Force update without confirmation
   template-health-endpoint update --force
 
@@ -71,6 +82,7 @@ func init() {
 	updateCmd.Flags().StringSliceVar(&updateComponents, "components", []string{}, "specific components to update (kubernetes,docker,go,typescript)")
 	updateCmd.Flags().BoolVar(&updateShowDiff, "show-diff", false, "show detailed diff of changes")
 	updateCmd.Flags().BoolVar(&updateBackup, "backup", true, "create backup of modified files")
+	updateCmd.Flags().StringVar(&updateConflicts, "conflicts", string(generator.ConflictMarkers), "how to write merge conflicts (markers|rej)")
 
 	// Bind flags to viper
 	viper.BindPFlag("update.target", updateCmd.Flags().Lookup("target"))
@@ -79,9 +91,15 @@ func init() {
 	viper.BindPFlag("update.components", updateCmd.Flags().Lookup("components"))
 	viper.BindPFlag("update.show-diff", updateCmd.Flags().Lookup("show-diff"))
 	viper.BindPFlag("update.backup", updateCmd.Flags().Lookup("backup"))
+	viper.BindPFlag("update.conflicts", updateCmd.Flags().Lookup("conflicts"))
 }
 
 func runUpdateProject(cmd *cobra.Command, args []string) error {
+	conflictStyle := generator.ConflictStyle(updateConflicts)
+	if !conflictStyle.IsValid() {
+		return fmt.Errorf("invalid conflict style '%s' (must be one of: markers, rej)", updateConflicts)
+	}
+
 	if verbose {
 		fmt.Printf("🔄 Starting project update in directory: %s\n", updateTargetDir)
 	}
@@ -97,14 +115,26 @@ func runUpdateProject(cmd *cobra.Command, args []string) error {
 			projectInfo.Name, projectInfo.Tier, projectInfo.Version)
 	}
 
-	// 2. Load current template configuration
-	currentTemplate, err := loadTemplateConfig(projectInfo.Tier)
-	if err != nil {
-		return fmt.Errorf("failed to load current template config: %w", err)
+	// Report generated files that were edited since generation
+	if projectInfo.Manifest != nil {
+		drift, err := projectInfo.Manifest.Drift(updateTargetDir)
+		if err != nil {
+			return fmt.Errorf("failed to check generated files: %w", err)
+		}
+		if len(drift) > 0 {
+			fmt.Printf("✏️  %d generated files changed since generation:\n", len(drift))
+			for _, d := range drift {
+				if d.Missing {
+					fmt.Printf("   deleted  %s\n", d.Path)
+				} else {
+					fmt.Printf("   modified %s\n", d.Path)
+				}
+			}
+		}
 	}
 
-	// 3. Compare with target template version
-	updatePlan, err := createUpdatePlan(projectInfo, currentTemplate, updateComponents)
+	// 2. Three-way merge the template changes into the project files
+	updatePlan, err := createUpdatePlan(projectInfo, updateComponents, conflictStyle)
 	if err != nil {
 		return fmt.Errorf("failed to create update plan: %w", err)
 	}
@@ -117,16 +147,20 @@ func runUpdateProject(cmd *cobra.Command, args []string) error {
 	// 4. Show update plan
 	fmt.Printf("\n📋 Update Plan for %s:\n", projectInfo.Name)
 	fmt.Printf("   Current version: %s\n", projectInfo.Version)
-	fmt.Printf("   Target version:  %s\n", currentTemplate.Version)
+	fmt.Printf("   Target version:  %s\n", generator.GeneratorVersion)
 	fmt.Printf("   Changes:         %d files\n\n", len(updatePlan.Changes))
 
 	for _, change := range updatePlan.Changes {
-		fmt.Printf("   %s %s\n", change.Type, change.Path)
+		fmt.Printf("   %-8s %s\n", change.Type, change.Path)
 		if updateShowDiff && change.Diff != "" {
-			fmt.Printf("     %s\n", strings.ReplaceAll(change.Diff, "\n", "\n     "))
+			fmt.Printf("\n%s\n", change.Diff)
 		}
 	}
 
+	if conflicts := updatePlan.ConflictCount(); conflicts > 0 {
+		fmt.Printf("\n⚠️  %d files have conflicts between your edits and the template changes\n", conflicts)
+	}
+
 	// 5. Dry run check
 	if updateDryRun {
 		fmt.Println("\n🔍 Dry run complete. No changes applied.")
@@ -161,6 +195,14 @@ func runUpdateProject(cmd *cobra.Command, args []string) error {
 	fmt.Printf("✅ Project updated successfully!\n")
 	fmt.Printf("   Updated %d files\n", len(updatePlan.Changes))
 
+	if conflicts := updatePlan.ConflictCount(); conflicts > 0 {
+		if conflictStyle == generator.ConflictReject {
+			fmt.Printf("   Review the rejected template hunks in %d .rej files\n", conflicts)
+		} else {
+			fmt.Printf("   Resolve the conflict markers in %d files\n", conflicts)
+		}
+	}
+
 	if updateBackup {
 		fmt.Printf("   Backup available at: %s.backup.%d\n", updateTargetDir, getCurrentTimestamp())
 	}
@@ -175,13 +217,23 @@ type ProjectInfo struct {
 	Version string
 	Module  string
 	Path    string
+
+	// Manifest is the generation manifest, nil for projects generated before manifests existed
+	Manifest *generator.Manifest
 }
 
 // UpdateChange represents a single file change in the update
 type UpdateChange struct {
-	Type string // "modify", "add", "delete"
+	Type string // "add", "modify", "merge", "conflict", "delete"
 	Path string
 	Diff string
+
+	// Content is the new file content; Rejects holds rejected hunks for .rej files
+	Content []byte
+	Rejects string
+
+	// Rendered is the file as rendered by the current template version
+	Rendered generator.RenderedFile
 }
 
 // UpdatePlan holds the complete update plan
@@ -189,44 +241,47 @@ type UpdatePlan struct {
 	ProjectInfo *ProjectInfo
 	Changes     []UpdateChange
 	TargetTier  string
+
+	registry *generator.TemplateRegistry
+}
+
+// ConflictCount returns the number of files with unresolved conflicts
+func (p *UpdatePlan) ConflictCount() int {
+	count := 0
+	for _, change := range p.Changes {
+		if change.Type == "conflict" {
+			count++
+		}
+	}
+	return count
 }
 
 // detectProjectInfo analyzes the target directory to determine project information
 func detectProjectInfo(targetDir string) (*ProjectInfo, error) {
-	// Look for template metadata file
-	metadataPath := filepath.Join(targetDir, ".template-metadata.yaml")
+	// Look for the generation manifest
+	metadataPath := filepath.Join(targetDir, generator.ManifestFileName)
 	if _, err := os.Stat(metadataPath); err == nil {
-		return loadProjectMetadata(metadataPath)
+		return loadProjectMetadata(targetDir)
 	}
 
 	// Fallback: analyze go.mod and directory structure
 	return analyzeProjectStructure(targetDir)
 }
 
-// loadProjectMetadata loads project information from metadata file
-func loadProjectMetadata(metadataPath string) (*ProjectInfo, error) {
-	data, err := os.ReadFile(metadataPath)
+// loadProjectMetadata loads project information from the generation manifest
+func loadProjectMetadata(targetDir string) (*ProjectInfo, error) {
+	manifest, err := generator.LoadManifest(targetDir)
 	if err != nil {
 		return nil, err
 	}
 
-	var metadata struct {
-		Name    string `yaml:"name"`
-		Tier    string `yaml:"tier"`
-		Version string `yaml:"version"`
-		Module  string `yaml:"module"`
-	}
-
-	if err := yaml.Unmarshal(data, &metadata); err != nil {
-		return nil, err
-	}
-
 	return &ProjectInfo{
-		Name:    metadata.Name,
-		Tier:    metadata.Tier,
-		Version: metadata.Version,
-		Module:  metadata.Module,
-		Path:    filepath.Dir(metadataPath),
+		Name:     manifest.Name,
+		Tier:     manifest.Tier,
+		Version:  manifest.GeneratorVersion,
+		Module:   manifest.Module,
+		Path:     targetDir,
+		Manifest: manifest,
 	}, nil
 }
 
@@ -275,43 +330,179 @@ func analyzeProjectStructure(targetDir string) (*ProjectInfo, error) {
 	}, nil
 }
 
-// loadTemplateConfig loads the template configuration for the specified tier
-func loadTemplateConfig(tier string) (*config.TemplateConfig, error) {
-	templatePath := filepath.Join("templates", tier, "template.yaml")
-	return config.LoadTemplateConfig(templatePath)
-}
+// createUpdatePlan renders the template version the project was generated from
+// and the current one, and three-way merges the difference into the project files
+func createUpdatePlan(projectInfo *ProjectInfo, components []string, style generator.ConflictStyle) (*UpdatePlan, error) {
+	manifest := projectInfo.Manifest
+	if manifest == nil || manifest.Config == nil {
+		return nil, fmt.Errorf("no %s found in %s; update needs the manifest written at generation time", generator.ManifestFileName, projectInfo.Path)
+	}
+
+	registry, err := generator.NewOverlayRegistry(projectInfo.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load templates: %w", err)
+	}
+
+	// Render with the original configuration and context so the only
+	// differences are the ones introduced by the templates themselves
+	cfg := *manifest.Config
+	cfg.OutputDir = projectInfo.Path
+	gen, err := generator.NewWithRegistry(&cfg, registry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create generator: %w", err)
+	}
+
+	ctx := manifest.Context()
+	ctx.Config = &cfg
+
+	rendered, err := gen.Render(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render current templates: %w", err)
+	}
 
-// createUpdatePlan creates a plan for updating the project
-func createUpdatePlan(projectInfo *ProjectInfo, template *config.TemplateConfig, components []string) (*UpdatePlan, error) {
 	plan := &UpdatePlan{
 		ProjectInfo: projectInfo,
 		Changes:     []UpdateChange{},
 		TargetTier:  projectInfo.Tier,
+		registry:    registry,
 	}
 
-	// For now, create a simple update plan
-	// In a real implementation, this would compare files and generate diffs
+	current := make(map[string]bool)
+	for _, file := range rendered {
+		current[file.Path] = true
+		if !shouldUpdateComponent(fileComponent(file.Path), components) {
+			continue
+		}
 
-	// Example changes (this would be generated by comparing actual files)
-	if shouldUpdateComponent("go", components) {
-		plan.Changes = append(plan.Changes, UpdateChange{
-			Type: "modify",
-			Path: "internal/server/server.go",
-			Diff: "Updated server configuration",
-		})
+		change, err := planFileUpdate(projectInfo.Path, manifest, registry, ctx, file, style)
+		if err != nil {
+			return nil, fmt.Errorf("failed to plan update of %s: %w", file.Path, err)
+		}
+		if change != nil {
+			plan.Changes = append(plan.Changes, *change)
+		}
 	}
 
-	if shouldUpdateComponent("kubernetes", components) {
+	// Files the current templates no longer generate are removed unless edited locally
+	for _, entry := range manifest.Files {
+		if current[entry.Path] || !shouldUpdateComponent(fileComponent(entry.Path), components) {
+			continue
+		}
+
+		ours, err := os.ReadFile(filepath.Join(projectInfo.Path, entry.Path))
+		if err != nil || generator.Checksum(ours) != entry.SHA256 {
+			continue
+		}
+
 		plan.Changes = append(plan.Changes, UpdateChange{
-			Type: "modify",
-			Path: "deployments/kubernetes/deployment.yaml",
-			Diff: "Updated Kubernetes deployment",
+			Type: "delete",
+			Path: entry.Path,
+			Diff: generator.UnifiedDiff("a/"+entry.Path, "/dev/null", string(ours), ""),
 		})
 	}
 
 	return plan, nil
 }
 
+// planFileUpdate works out the change for a single rendered file, or nil if it is up to date
+func planFileUpdate(projectDir string, manifest *generator.Manifest, registry *generator.TemplateRegistry, ctx *generator.GenerationContext, file generator.RenderedFile, style generator.ConflictStyle) (*UpdateChange, error) {
+	path := filepath.Join(projectDir, file.Path)
+	theirs := string(file.Content)
+
+	entry, generated := manifest.File(file.Path)
+	ours, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		if generated {
+			// Deleted locally on purpose; keep it deleted
+			return nil, nil
+		}
+		return &UpdateChange{
+			Type:     "add",
+			Path:     file.Path,
+			Diff:     generator.UnifiedDiff("/dev/null", "b/"+file.Path, "", theirs),
+			Content:  file.Content,
+			Rendered: file,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Same template version: nothing to bring in
+	if generated && entry.TemplateHash == file.TemplateHash {
+		return nil, nil
+	}
+
+	// Render the template version the file was generated from. Without a
+	// snapshot the base is unknown and every local edit becomes a conflict.
+	base := ""
+	if generated {
+		if source, err := generator.LoadTemplateSnapshot(projectDir, entry.TemplateHash); err == nil {
+			rendered, err := registry.RenderSource(entry.Template, source, ctx.ForEnvironment(entry.Environment))
+			if err != nil {
+				return nil, fmt.Errorf("failed to render original template: %w", err)
+			}
+			// Older template versions may predate output verification; keep them as rendered
+			if formatted, err := generator.FormatArtifact(file.Path, rendered); err == nil {
+				rendered = formatted
+			}
+			base = string(rendered)
+		} else if generator.Checksum(ours) == entry.SHA256 {
+			base = string(ours)
+		}
+	}
+
+	if string(ours) == theirs {
+		return nil, nil
+	}
+
+	change := &UpdateChange{Path: file.Path, Rendered: file}
+	if string(ours) == base {
+		change.Type = "modify"
+		change.Content = file.Content
+	} else {
+		result := generator.Merge3(base, string(ours), theirs, generator.MergeLabels{
+			Base:   "a/" + file.Path,
+			Ours:   "local",
+			Theirs: "template " + generator.GeneratorVersion,
+		}, style)
+
+		change.Type = "merge"
+		if result.HasConflicts() {
+			change.Type = "conflict"
+		}
+		change.Content = []byte(result.Content)
+		change.Rejects = result.Rejects
+	}
+
+	if string(change.Content) == string(ours) && change.Rejects == "" {
+		return nil, nil
+	}
+
+	change.Diff = generator.UnifiedDiff("a/"+file.Path, "b/"+file.Path, string(ours), string(change.Content))
+	if change.Rejects != "" {
+		change.Diff += change.Rejects
+	}
+
+	return change, nil
+}
+
+// fileComponent maps a project file to the component it belongs to
+func fileComponent(path string) string {
+	switch {
+	case strings.HasPrefix(path, "deployments/kubernetes/"):
+		return "kubernetes"
+	case strings.HasPrefix(path, "client/typescript/"):
+		return "typescript"
+	case path == "Dockerfile" || path == "docker-compose.yml" || path == ".dockerignore":
+		return "docker"
+	case strings.HasSuffix(path, ".go") || path == "go.mod":
+		return "go"
+	default:
+		return "docs"
+	}
+}
+
 // shouldUpdateComponent checks if a component should be updated
 func shouldUpdateComponent(component string, requestedComponents []string) bool {
 	if len(requestedComponents) == 0 {
@@ -333,7 +524,7 @@ func createBackup(sourceDir, backupDir string, changes []UpdateChange) error {
 	}
 
 	for _, change := range changes {
-		if change.Type == "modify" {
+		if change.Type != "add" {
 			sourcePath := filepath.Join(sourceDir, change.Path)
 			backupPath := filepath.Join(backupDir, change.Path)
 
@@ -352,20 +543,60 @@ func createBackup(sourceDir, backupDir string, changes []UpdateChange) error {
 	return nil
 }
 
-// applyUpdates applies the update plan to the project
+// applyUpdates applies the update plan to the project and records it in the manifest
 func applyUpdates(targetDir string, plan *UpdatePlan) error {
+	manifest := plan.ProjectInfo.Manifest
+
 	for _, change := range plan.Changes {
+		path := filepath.Join(targetDir, change.Path)
+
 		switch change.Type {
-		case "modify":
-			// In a real implementation, this would apply the actual file changes
-			fmt.Printf("   📝 Updating %s\n", change.Path)
-		case "add":
-			fmt.Printf("   ➕ Adding %s\n", change.Path)
 		case "delete":
 			fmt.Printf("   🗑️  Removing %s\n", change.Path)
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			manifest.Remove(change.Path)
+			continue
+		case "add":
+			fmt.Printf("   ➕ Adding %s\n", change.Path)
+		case "conflict":
+			fmt.Printf("   ⚠️  Conflict in %s\n", change.Path)
+		default:
+			fmt.Printf("   📝 Updating %s\n", change.Path)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, change.Content, 0644); err != nil {
+			return err
+		}
+		if change.Rejects != "" {
+			if err := os.WriteFile(path+".rej", []byte(change.Rejects), 0644); err != nil {
+				return err
+			}
+		}
+
+		// The manifest tracks the rendered template output, so local edits keep showing up as drift
+		manifest.Record(generator.ManifestFile{
+			Path:         change.Rendered.Path,
+			Template:     change.Rendered.Template,
+			TemplateHash: change.Rendered.TemplateHash,
+			SHA256:       generator.Checksum(change.Rendered.Content),
+			Environment:  change.Rendered.Environment,
+			Fields:       change.Rendered.Fields,
+			InputsHash:   change.Rendered.InputsHash,
+		})
+		if source, ok := plan.registry.Source(change.Rendered.Template); ok {
+			if err := generator.SaveTemplateSnapshot(targetDir, change.Rendered.TemplateHash, source); err != nil {
+				return err
+			}
 		}
 	}
-	return nil
+
+	manifest.GeneratorVersion = generator.GeneratorVersion
+	return manifest.Save(targetDir)
 }
 
 // Helper functions
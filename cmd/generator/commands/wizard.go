@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -25,6 +26,9 @@ func InteractiveWizard() (*config.ProjectConfig, error) {
 		return nil, err
 	}
 
+	// Start from the tier defaults so the answers below take precedence
+	cfg.ApplyTierDefaults()
+
 	// Step 3: Feature selection based on tier
 	if err := askFeatureSelection(&cfg); err != nil {
 		return nil, err
@@ -35,8 +39,10 @@ func InteractiveWizard() (*config.ProjectConfig, error) {
 		return nil, err
 	}
 
-	// Apply tier-specific defaults
-	cfg.ApplyTierDefaults()
+	// Step 5: Variables declared by the tier's template.yaml
+	if err := askTemplateVariables(&cfg); err != nil {
+		return nil, err
+	}
 
 	return &cfg, nil
 }
@@ -231,7 +237,7 @@ func askKubernetesConfiguration(cfg *config.ProjectConfig) error {
 	cfg.Kubernetes.Ingress.Enabled = answers.Ingress
 	
 	if cfg.Tier == "enterprise" {
-		cfg.Security.RBAC = answers.RBAC
+		cfg.Features.Security = answers.RBAC
 	}
 
 	if answers.Ingress {
@@ -249,6 +255,81 @@ func askKubernetesConfiguration(cfg *config.ProjectConfig) error {
 	return nil
 }
 
+// askTemplateVariables prompts for every variable the tier's template.yaml
+// declares for the enabled features, validating answers as generate does
+func askTemplateVariables(cfg *config.ProjectConfig) error {
+	tierConfig, err := loadTierConfig(cfg.Tier)
+	if err != nil || tierConfig == nil || len(tierConfig.Variables) == 0 {
+		return err
+	}
+
+	fmt.Printf("\n📝 Template variables for %s tier:\n", cfg.Tier)
+	cfg.Variables = make(map[string]string)
+	for _, spec := range tierConfig.Variables {
+		if !spec.Applies(cfg) {
+			continue
+		}
+
+		message := spec.Name + ":"
+		if spec.Description != "" {
+			message = fmt.Sprintf("%s (%s):", spec.Name, spec.Description)
+		}
+
+		var prompt survey.Prompt
+		switch spec.Type {
+		case config.VariableBool:
+			def, _ := strconv.ParseBool(spec.Default)
+			prompt = &survey.Confirm{Message: message, Default: def}
+		case config.VariableEnum:
+			selectPrompt := &survey.Select{Message: message, Options: spec.Enum}
+			if spec.Default != "" {
+				selectPrompt.Default = spec.Default
+			}
+			prompt = selectPrompt
+		default:
+			prompt = &survey.Input{Message: message, Default: spec.Default, Help: spec.Pattern}
+		}
+
+		var opts []survey.AskOpt
+		if spec.Type == config.VariableString || spec.Type == config.VariableInt {
+			spec := spec
+			opts = append(opts, survey.WithValidator(func(answer interface{}) error {
+				value := fmt.Sprint(answer)
+				if value == "" {
+					if spec.Required {
+						return fmt.Errorf("%s is required", spec.Name)
+					}
+					return nil
+				}
+				_, err := spec.Parse(value)
+				return err
+			}))
+		}
+
+		var answer interface{}
+		switch spec.Type {
+		case config.VariableBool:
+			var b bool
+			if err := survey.AskOne(prompt, &b, opts...); err != nil {
+				return err
+			}
+			answer = b
+		default:
+			var text string
+			if err := survey.AskOne(prompt, &text, opts...); err != nil {
+				return err
+			}
+			if text == "" {
+				continue
+			}
+			answer = text
+		}
+		cfg.Variables[spec.Name] = fmt.Sprint(answer)
+	}
+
+	return nil
+}
+
 func getAvailableFeatures(tier config.TemplateTier) []string {
 	var features []string
 
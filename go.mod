@@ -3,6 +3,7 @@ module github.com/LarsArtmann/BMAD-METHOD
 go 1.21
 
 require (
+	github.com/AlecAivazis/survey/v2 v2.3.7
 	github.com/cucumber/godog v0.14.0
 	github.com/spf13/cobra v1.8.0
 	github.com/spf13/viper v1.18.2
@@ -10,7 +11,6 @@ require (
 )
 
 require (
-	github.com/AlecAivazis/survey/v2 v2.3.7 // indirect
 	github.com/cucumber/gherkin/go/v26 v26.2.0 // indirect
 	github.com/cucumber/messages/go/v21 v21.0.1 // indirect
 	github.com/fsnotify/fsnotify v1.7.0 // indirect
@@ -0,0 +1,383 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Configuration layers from the lowest precedence to the highest. Values
+// of a higher layer replace those of lower layers; tier defaults only fill
+// in what no other layer sets.
+const (
+	LayerDefault = "default"
+	LayerTier    = "tier"
+	LayerOrg     = "org"
+	LayerProject = "project"
+	LayerEnv     = "env"
+	LayerFlag    = "flag"
+)
+
+const (
+	// EnvPrefix starts the environment variables of the env layer, such as
+	// TEMPLATE_HEALTH_TIER or TEMPLATE_HEALTH_KUBERNETES_PORT
+	EnvPrefix = "TEMPLATE_HEALTH_"
+
+	// OrgConfigEnv names the file of the org layer
+	OrgConfigEnv = EnvPrefix + "ORG_CONFIG"
+
+	// DefaultOrgConfigFile is the file of the org layer relative to the home
+	// directory, used when OrgConfigEnv is not set
+	DefaultOrgConfigFile = ".template-health-endpoint/org.yaml"
+)
+
+// Origin is where an effective configuration value comes from
+type Origin struct {
+	Layer  string
+	Source string // file:line, environment variable, flag or tier the value was read from
+}
+
+// String formats the origin as layer (source)
+func (o Origin) String() string {
+	if o.Source == "" {
+		return o.Layer
+	}
+	return fmt.Sprintf("%s (%s)", o.Layer, o.Source)
+}
+
+// layerValue is a leaf value of a configuration layer
+type layerValue struct {
+	segments []string
+	node     *yaml.Node
+	origin   Origin
+}
+
+// Layer is one source of project configuration values, keyed by the YAML
+// path of each leaf value, such as kubernetes.health_probes.liveness_probe.path
+type Layer struct {
+	Name      string
+	Migration *ConfigMigration // how the file was upgraded to ConfigAPIVersion, if it was
+	values    map[string]layerValue
+}
+
+// NewLayer returns an empty layer to fill with Set
+func NewLayer(name string) *Layer {
+	return &Layer{Name: name, values: make(map[string]layerValue)}
+}
+
+// LoadLayer reads a project configuration file into a layer
+func LoadLayer(name, file string) (*Layer, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return ParseLayer(name, file, data)
+}
+
+// ParseLayer parses a project configuration into a layer after upgrading
+// it to ConfigAPIVersion and checking it against ProjectConfigSchema; file
+// identifies it in errors and origins
+func ParseLayer(name, file string, data []byte) (*Layer, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", file, err)
+	}
+	if len(document.Content) == 0 {
+		return NewLayer(name), nil
+	}
+
+	migration, err := UpgradeConfigNode(document.Content[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", file, err)
+	}
+	if errs := ValidateConfigNode(document.Content[0], ""); len(errs) > 0 {
+		for i := range errs {
+			errs[i].File = file
+		}
+		return nil, errs
+	}
+
+	layer := NodeLayer(name, file, document.Content[0])
+	layer.Migration = migration
+	return layer, nil
+}
+
+// NodeLayer turns a YAML node holding a ProjectConfig, already checked with
+// ValidateConfigNode, into a layer; file identifies it in origins
+func NodeLayer(name, file string, node *yaml.Node) *Layer {
+	layer := NewLayer(name)
+	ProjectConfigSchema().flatten(node, nil, func(segments []string, value *yaml.Node) {
+		source := fmt.Sprintf("line %d", value.Line)
+		switch {
+		case value.Line == 0:
+			// Added by an upgrade
+			source = file
+		case file != "":
+			source = fmt.Sprintf("%s:%d", file, value.Line)
+		}
+		layer.add(segments, value, Origin{Layer: name, Source: source})
+	})
+	return layer
+}
+
+// EnvLayer reads the env layer: every leaf value outside of maps can be set
+// with EnvPrefix and its path in upper case, dots replaced by underscores.
+// Lists are comma-separated.
+func EnvLayer(lookup func(string) (string, bool)) (*Layer, error) {
+	layer := NewLayer(LayerEnv)
+	var errs ConfigErrors
+	ProjectConfigSchema().leaves(nil, func(segments []string, _ *Schema) {
+		name := EnvPrefix + strings.ToUpper(strings.Join(segments, "_"))
+		if value, ok := lookup(name); ok {
+			if err := layer.Set(strings.Join(segments, "."), value, "$"+name); err != nil {
+				errs = append(errs, err.(ConfigErrors)...)
+			}
+		}
+	})
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return layer, nil
+}
+
+// Set sets the value at a dotted path, such as features.docker,
+// variables.log_level or environments.staging.replicas, parsing it according to the schema; source is the
+// flag or environment variable the value comes from. Schema violations are
+// returned as ConfigErrors.
+func (l *Layer) Set(path, value, source string) error {
+	schema, segments := ProjectConfigSchema(), strings.Split(path, ".")
+	for i := 0; i < len(segments) && schema != nil; i++ {
+		if schema.Properties != nil {
+			schema = schema.Properties[segments[i]]
+			continue
+		}
+		if additional, ok := schema.AdditionalProperties.(*Schema); ok && additional.Properties != nil {
+			// Groups of settings keyed by name, such as environments.staging
+			schema = additional
+			continue
+		} else if ok {
+			// Map keys may contain dots
+			segments = append(segments[:i], strings.Join(segments[i:], "."))
+			schema = additional
+			break
+		}
+		schema = nil
+	}
+	if schema == nil {
+		return ConfigErrors{{File: source, Path: path, Message: "unknown setting"}}
+	}
+	if schema.Type == "object" {
+		return ConfigErrors{{File: source, Path: path, Message: "is a group of settings, not a single value"}}
+	}
+
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	switch schema.Type {
+	case "array":
+		node = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item})
+			}
+		}
+	case "string":
+	default:
+		node.Tag = ""
+		node.Tag = node.ShortTag()
+	}
+
+	var errs ConfigErrors
+	schema.validate(node, path, &errs)
+	if len(errs) > 0 {
+		for i := range errs {
+			errs[i].File = source
+		}
+		return errs
+	}
+	l.add(segments, node, Origin{Layer: l.Name, Source: source})
+	return nil
+}
+
+// add stores a leaf value
+func (l *Layer) add(segments []string, node *yaml.Node, origin Origin) {
+	l.values[strings.Join(segments, ".")] = layerValue{segments: segments, node: node, origin: origin}
+}
+
+// ResolvedConfig is a project configuration merged from its layers
+type ResolvedConfig struct {
+	Config *ProjectConfig
+	values map[string]layerValue
+}
+
+// ResolvedValue is an effective configuration value and where it comes from
+type ResolvedValue struct {
+	Path   string
+	Value  string
+	Origin Origin
+}
+
+// Resolve merges layers given from the lowest precedence to the highest on
+// top of the defaults of the tier they select (basic if none does). Tier
+// defaults never replace a value a layer sets, so explicitly disabling a
+// feature the tier enables sticks.
+func Resolve(layers ...*Layer) (*ResolvedConfig, error) {
+	explicit := make(map[string]layerValue)
+	for _, layer := range layers {
+		for path, value := range layer.values {
+			explicit[path] = value
+		}
+	}
+
+	tier := TierBasic
+	if value, ok := explicit["tier"]; ok {
+		tier = TemplateTier(value.node.Value)
+		if !tier.IsValid() {
+			return nil, fmt.Errorf("invalid tier: %s from %s (must be one of: basic, intermediate, advanced, enterprise)", tier, value.origin)
+		}
+	}
+	tierDefaults := &ProjectConfig{Tier: tier}
+	tierDefaults.ApplyTierDefaults()
+
+	values := make(map[string]layerValue)
+	for path, value := range flattenConfig(&ProjectConfig{Tier: tier}) {
+		value.origin = Origin{Layer: LayerDefault}
+		values[path] = value
+	}
+	for path, value := range flattenConfig(tierDefaults) {
+		if renderNode(value.node) != renderNode(values[path].node) {
+			value.origin = Origin{Layer: LayerTier, Source: string(tier)}
+			values[path] = value
+		}
+	}
+	for path, value := range explicit {
+		values[path] = value
+	}
+
+	document := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, value := range values {
+		parent := document
+		for _, segment := range value.segments[:len(value.segments)-1] {
+			parent = mappingChild(parent, segment)
+		}
+		parent.Content = append(parent.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value.segments[len(value.segments)-1]}, value.node)
+	}
+
+	var cfg ProjectConfig
+	if err := document.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to merge configuration layers: %w", err)
+	}
+	return &ResolvedConfig{Config: &cfg, values: values}, nil
+}
+
+// Explain returns every leaf value of the configuration sorted by path with
+// the layer it comes from. Values changed after Resolve, such as an output
+// directory derived from the name, are reported as defaults.
+func (r *ResolvedConfig) Explain() []ResolvedValue {
+	var explained []ResolvedValue
+	for path, value := range flattenConfig(r.Config) {
+		origin := Origin{Layer: LayerDefault, Source: "derived"}
+		if resolved, ok := r.values[path]; ok && renderNode(resolved.node) == renderNode(value.node) {
+			origin = resolved.origin
+		}
+		explained = append(explained, ResolvedValue{Path: path, Value: renderNode(value.node), Origin: origin})
+	}
+	sort.Slice(explained, func(i, j int) bool { return explained[i].Path < explained[j].Path })
+	return explained
+}
+
+// flattenConfig returns the leaf values of a configuration keyed by path
+func flattenConfig(cfg *ProjectConfig) map[string]layerValue {
+	var node yaml.Node
+	if err := node.Encode(cfg); err != nil {
+		panic(fmt.Sprintf("failed to encode project config: %v", err))
+	}
+	values := make(map[string]layerValue)
+	ProjectConfigSchema().flatten(&node, nil, func(segments []string, value *yaml.Node) {
+		values[strings.Join(segments, ".")] = layerValue{segments: segments, node: value}
+	})
+	return values
+}
+
+// flatten calls visit with the path and node of every leaf value below
+// node: scalars, lists and the entries of maps. Unset values are skipped.
+func (s *Schema) flatten(node *yaml.Node, segments []string, visit func([]string, *yaml.Node)) {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+	if s.Type != "object" || node.Kind != yaml.MappingNode {
+		visit(segments, node)
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		child := append(segments[:len(segments):len(segments)], key.Value)
+		if key.Value == "<<" {
+			s.flatten(value, segments, visit)
+		} else if property, ok := s.Properties[key.Value]; ok {
+			property.flatten(value, child, visit)
+		} else if entry, ok := s.AdditionalProperties.(*Schema); ok {
+			entry.flatten(value, child, visit)
+		}
+	}
+}
+
+// leaves calls visit with the path and schema of every property that is
+// not an object
+func (s *Schema) leaves(segments []string, visit func([]string, *Schema)) {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, child := s.Properties[name], append(segments[:len(segments):len(segments)], name)
+		switch {
+		case property.Properties != nil:
+			property.leaves(child, visit)
+		case property.Type != "object":
+			visit(child, property)
+		}
+	}
+}
+
+// mappingChild returns the mapping stored under key in parent, adding it if needed
+func mappingChild(parent *yaml.Node, key string) *yaml.Node {
+	if child := mappingValue(parent, key); child != nil {
+		return child
+	}
+	child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+	return child
+}
+
+// renderNode formats a leaf value for display and comparison
+func renderNode(node *yaml.Node) string {
+	if node == nil {
+		return ""
+	}
+	switch node.Kind {
+	case yaml.SequenceNode:
+		items := make([]string, len(node.Content))
+		for i, item := range node.Content {
+			items[i] = renderNode(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case yaml.ScalarNode:
+		if node.Value == "" && node.Tag == "!!str" {
+			return `""`
+		}
+		return node.Value
+	default:
+		return node.Tag
+	}
+}
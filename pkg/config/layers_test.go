@@ -0,0 +1,156 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResolve_Precedence(t *testing.T) {
+	org, err := ParseLayer(LayerOrg, "org.yaml", []byte(`
+tier: intermediate
+kubernetes:
+  namespace: platform
+  port: 8000
+  labels: {team: platform, cost: shared}
+`))
+	if err != nil {
+		t.Fatalf("ParseLayer(org) error = %v", err)
+	}
+	project, err := ParseLayer(LayerProject, "service.yaml", []byte(`
+name: orders
+kubernetes:
+  namespace: shop
+  labels: {team: orders-team, owner: orders}
+`))
+	if err != nil {
+		t.Fatalf("ParseLayer(project) error = %v", err)
+	}
+	env, err := EnvLayer(func(name string) (string, bool) {
+		value, ok := map[string]string{
+			EnvPrefix + "KUBERNETES_PORT": "9000",
+			EnvPrefix + "FEATURES_DOCKER": "false",
+			EnvPrefix + "NAME":            "orders-env",
+		}[name]
+		return value, ok
+	})
+	if err != nil {
+		t.Fatalf("EnvLayer() error = %v", err)
+	}
+	flags := NewLayer(LayerFlag)
+	if err := flags.Set("name", "orders-flag", "--name"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	resolved, err := Resolve(org, project, env, flags)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	cfg := resolved.Config
+
+	if cfg.Name != "orders-flag" || cfg.Kubernetes.Namespace != "shop" || cfg.Kubernetes.Port != 9000 {
+		t.Errorf("name, namespace, port = %s, %s, %d, want orders-flag, shop, 9000", cfg.Name, cfg.Kubernetes.Namespace, cfg.Kubernetes.Port)
+	}
+	// Maps are merged key by key
+	wantLabels := map[string]string{"team": "orders-team", "owner": "orders", "cost": "shared"}
+	if len(cfg.Kubernetes.Labels) != len(wantLabels) {
+		t.Errorf("labels = %v, want %v", cfg.Kubernetes.Labels, wantLabels)
+	}
+	for key, value := range wantLabels {
+		if cfg.Kubernetes.Labels[key] != value {
+			t.Errorf("labels[%s] = %q, want %q", key, cfg.Kubernetes.Labels[key], value)
+		}
+	}
+	// The intermediate tier enables Docker, but an explicit false sticks
+	if cfg.Features.Docker || !cfg.Features.OpenTelemetry {
+		t.Errorf("features = %+v, want docker disabled and tier defaults otherwise", cfg.Features)
+	}
+
+	origins := make(map[string]string)
+	for _, value := range resolved.Explain() {
+		origins[value.Path] = value.Origin.String()
+	}
+	for path, want := range map[string]string{
+		"name":                    "flag (--name)",
+		"tier":                    "org (org.yaml:2)",
+		"kubernetes.namespace":    "project (service.yaml:4)",
+		"kubernetes.labels.cost":  "org (org.yaml:6)",
+		"kubernetes.labels.team":  "project (service.yaml:5)",
+		"kubernetes.port":         "env ($TEMPLATE_HEALTH_KUBERNETES_PORT)",
+		"features.docker":         "env ($TEMPLATE_HEALTH_FEATURES_DOCKER)",
+		"features.opentelemetry":  "tier (intermediate)",
+		"kubernetes.service_name": "default",
+	} {
+		if origins[path] != want {
+			t.Errorf("origin of %s = %q, want %q", path, origins[path], want)
+		}
+	}
+
+	// Values changed after resolving are derived defaults
+	cfg.OutputDir = "orders-flag"
+	for _, value := range resolved.Explain() {
+		if value.Path == "output_dir" && value.Origin.String() != "default (derived)" {
+			t.Errorf("origin of output_dir = %q, want default (derived)", value.Origin)
+		}
+	}
+}
+
+func TestResolve_TierDefaults(t *testing.T) {
+	project, err := ParseLayer(LayerProject, "service.yaml", []byte("tier: enterprise\nfeatures: {compliance: false}\n"))
+	if err != nil {
+		t.Fatalf("ParseLayer() error = %v", err)
+	}
+	resolved, err := Resolve(project)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if features := resolved.Config.Features; features.Compliance || !features.Security {
+		t.Errorf("features = %+v, want enterprise defaults without compliance", features)
+	}
+
+	basic, err := Resolve()
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if basic.Config.Tier != TierBasic || !basic.Config.Features.Docker {
+		t.Errorf("Resolve() without layers = %+v, want basic tier defaults", basic.Config)
+	}
+
+	flags := NewLayer(LayerFlag)
+	if err := flags.Set("tier", "huge", "--tier"); err == nil {
+		t.Error("Set() accepted an invalid tier")
+	}
+}
+
+func TestLayer_Set(t *testing.T) {
+	layer := NewLayer(LayerFlag)
+	for path, value := range map[string]string{
+		"features.docker":                "true",
+		"dependencies.external_services": "billing, users",
+		"variables.log.level":            "debug",
+		"environments.staging.replicas":  "2",
+	} {
+		if err := layer.Set(path, value, "--test"); err != nil {
+			t.Errorf("Set(%s) error = %v", path, err)
+		}
+	}
+	resolved, err := Resolve(layer)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	cfg := resolved.Config
+	if !cfg.Features.Docker || len(cfg.Dependencies.ExternalServices) != 2 || cfg.Variables["log.level"] != "debug" || cfg.Environments["staging"].Replicas != 2 {
+		t.Errorf("Resolved = %+v", cfg)
+	}
+
+	for path, value := range map[string]string{
+		"features.unknown":  "true",
+		"features":          "true",
+		"kubernetes.port":   "http",
+		"environments.prod": "1",
+	} {
+		var errs ConfigErrors
+		if err := layer.Set(path, value, "--test"); !errors.As(err, &errs) {
+			t.Errorf("Set(%s, %s) error = %v, want ConfigErrors", path, value, err)
+		}
+	}
+}
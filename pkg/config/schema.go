@@ -0,0 +1,339 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaDialect is the JSON Schema draft ProjectConfigSchema is written in
+const SchemaDialect = "http://json-schema.org/draft-07/schema#"
+
+// Schema is a JSON Schema restricted to the keywords project configuration
+// files need
+type Schema struct {
+	Dialect              string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"` // false or a *Schema
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Maximum              *int               `json:"maximum,omitempty"`
+
+	lessThan string // sibling integer property the value must stay below
+
+	// scalar marks a string that also accepts numbers and booleans, which
+	// yaml.v3 decodes into strings, such as version: 1.0 or cpu: 0.5
+	scalar bool
+}
+
+// scalarTypes are the JSON types a scalar string setting accepts
+var scalarTypes = []string{"string", "number", "boolean"}
+
+// MarshalJSON writes the type of a scalar string setting as the list of
+// types it accepts, so editors accept what the validator accepts
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	if !s.scalar {
+		return json.Marshal((*plain)(s))
+	}
+	return json.Marshal(struct {
+		Type []string `json:"type"`
+		*plain
+	}{scalarTypes, (*plain)(s)})
+}
+
+// schemaEnums holds the values of the string types that are enumerations
+var schemaEnums = map[reflect.Type]func() []string{
+	reflect.TypeOf(TemplateTier("")): func() []string {
+		values := make([]string, len(Tiers))
+		for i, tier := range Tiers {
+			values[i] = string(tier)
+		}
+		return values
+	},
+	reflect.TypeOf(LogLevel("")): func() []string {
+		values := make([]string, len(LogLevels))
+		for i, level := range LogLevels {
+			values[i] = string(level)
+		}
+		return values
+	},
+}
+
+// ProjectConfigSchema returns the JSON Schema of project configuration
+// files, generated from the yaml tags of ProjectConfig. Numeric bounds come
+// from schema tags such as `schema:"minimum=1,maximum=65535"`.
+func ProjectConfigSchema() *Schema {
+	schema := schemaFor(reflect.TypeOf(ProjectConfig{}))
+	schema.Dialect = SchemaDialect
+	schema.Title = "template-health-endpoint project configuration"
+	schema.Description = "Configuration file passed to 'template-health-endpoint generate --config'"
+	return schema
+}
+
+// schemaFor builds the schema of a Go type
+func schemaFor(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Struct:
+		schema := &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: false}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			property := schemaFor(field.Type)
+			applySchemaTag(property, field.Tag.Get("schema"))
+			schema.Properties[name] = property
+		}
+		return schema
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaFor(t.Elem())}
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: schemaFor(t.Elem())}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	default:
+		schema := &Schema{Type: "string"}
+		if values, ok := schemaEnums[t]; ok {
+			schema.Enum = values()
+		} else {
+			schema.scalar = true
+		}
+		return schema
+	}
+}
+
+// applySchemaTag applies the comma-separated key=value constraints of a
+// schema struct tag: minimum, maximum and lessThan, which names a sibling
+// integer property the value must stay below
+func applySchemaTag(schema *Schema, tag string) {
+	for _, constraint := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(constraint, "=")
+		switch key {
+		case "minimum", "maximum":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				panic(fmt.Sprintf("invalid schema tag %q: %v", tag, err))
+			}
+			if key == "minimum" {
+				schema.Minimum = &n
+			} else {
+				schema.Maximum = &n
+			}
+		case "lessThan":
+			schema.lessThan = value
+			schema.Description = "Must be less than " + value
+		case "":
+		default:
+			panic(fmt.Sprintf("invalid schema tag %q: unknown constraint %s", tag, key))
+		}
+	}
+}
+
+// ConfigError is a value in a configuration file that does not match the schema
+type ConfigError struct {
+	File    string // file, environment variable or flag the value was read from
+	Path    string // YAML path such as kubernetes.health_probes.liveness_probe.timeout_seconds
+	Line    int
+	Column  int
+	Message string
+}
+
+// Error formats the error as file:line:col: path: message, leaving out
+// the parts that are unknown
+func (e ConfigError) Error() string {
+	var parts []string
+	if e.File != "" {
+		parts = append(parts, e.File)
+	}
+	if e.Line > 0 {
+		parts = append(parts, strconv.Itoa(e.Line), strconv.Itoa(e.Column))
+	}
+	prefix := strings.Join(parts, ":")
+	if e.Path != "" {
+		prefix = strings.TrimPrefix(prefix+": "+e.Path, ": ")
+	}
+	if prefix == "" {
+		return e.Message
+	}
+	return prefix + ": " + e.Message
+}
+
+// ConfigErrors is every schema violation found in a configuration, in document order
+type ConfigErrors []ConfigError
+
+// Error lists the errors one per line
+func (e ConfigErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = "\n  " + err.Error()
+	}
+	return fmt.Sprintf("%d schema violations:%s", len(e), strings.Join(lines, ""))
+}
+
+// ValidateConfigNode checks a YAML node holding a ProjectConfig against
+// ProjectConfigSchema; prefix is prepended to the paths of the errors, such
+// as services[2] for a service of a batch file
+func ValidateConfigNode(node *yaml.Node, prefix string) ConfigErrors {
+	var errs ConfigErrors
+	ProjectConfigSchema().validate(node, prefix, &errs)
+	return errs
+}
+
+// validate appends the violations of node and its children to errs
+func (s *Schema) validate(node *yaml.Node, path string, errs *ConfigErrors) {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+	fail := func(node *yaml.Node, path, format string, args ...interface{}) {
+		*errs = append(*errs, ConfigError{Path: path, Line: node.Line, Column: node.Column, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch s.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			fail(node, path, "must be a mapping, got %s", describeNode(node))
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				s.validate(value, path, errs)
+				continue
+			}
+			child := joinPath(path, key.Value)
+			property, ok := s.Properties[key.Value]
+			if !ok {
+				additional, ok := s.AdditionalProperties.(*Schema)
+				if !ok {
+					fail(key, child, "unknown field %q", key.Value)
+					continue
+				}
+				property = additional
+			}
+			property.validate(value, child, errs)
+			if property.lessThan != "" {
+				checkLessThan(node, key, value, property.lessThan, child, fail)
+			}
+		}
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			fail(node, path, "must be a list, got %s", describeNode(node))
+			return
+		}
+		for i, item := range node.Content {
+			s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case "string":
+		tags := []string{"!!str"}
+		if s.scalar {
+			tags = append(tags, "!!int", "!!float", "!!bool")
+		}
+		if node.Kind != yaml.ScalarNode || !containsString(tags, node.Tag) {
+			fail(node, path, "must be a string, got %s", describeNode(node))
+			return
+		}
+		if len(s.Enum) > 0 && !containsString(s.Enum, node.Value) {
+			fail(node, path, "must be one of: %s", strings.Join(s.Enum, ", "))
+		}
+	case "integer":
+		var n int
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" || node.Decode(&n) != nil {
+			fail(node, path, "must be an integer, got %s", describeNode(node))
+			return
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			fail(node, path, "must be >= %d", *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			fail(node, path, "must be <= %d", *s.Maximum)
+		}
+	case "number":
+		if node.Kind != yaml.ScalarNode || (node.Tag != "!!int" && node.Tag != "!!float") {
+			fail(node, path, "must be a number, got %s", describeNode(node))
+		}
+	case "boolean":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			fail(node, path, "must be true or false, got %s", describeNode(node))
+		}
+	}
+}
+
+// checkLessThan reports an integer property of mapping that is not below
+// its sibling other, when both are set to integers
+func checkLessThan(mapping, key, value *yaml.Node, other, path string, fail func(*yaml.Node, string, string, ...interface{})) {
+	var n, limit int
+	if value.Tag != "!!int" || value.Decode(&n) != nil {
+		return
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != other {
+			continue
+		}
+		if limitNode := mapping.Content[i+1]; limitNode.Tag == "!!int" && limitNode.Decode(&limit) == nil && n >= limit {
+			fail(key, path, "must be < %s", other)
+		}
+		return
+	}
+}
+
+// describeNode names the kind of value a node holds for error messages
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	switch node.Tag {
+	case "!!str":
+		return fmt.Sprintf("string %q", node.Value)
+	case "!!int", "!!float":
+		return "number " + node.Value
+	case "!!bool":
+		return "boolean " + node.Value
+	default:
+		return fmt.Sprintf("%s %s", strings.TrimPrefix(node.Tag, "!!"), node.Value)
+	}
+}
+
+// joinPath appends a key to a dotted YAML path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// containsString reports whether values holds value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestProjectConfigSchema(t *testing.T) {
+	schema := ProjectConfigSchema()
+	if schema.Dialect != SchemaDialect || schema.Type != "object" || schema.AdditionalProperties != false {
+		t.Errorf("Root schema = %+v, want a closed draft-07 object", schema)
+	}
+
+	tier := schema.Properties["tier"]
+	if strings.Join(tier.Enum, ",") != "basic,intermediate,advanced,enterprise" {
+		t.Errorf("tier enum = %v", tier.Enum)
+	}
+
+	port := schema.Properties["kubernetes"].Properties["port"]
+	if port.Type != "integer" || port.Minimum == nil || *port.Minimum != 1 || port.Maximum == nil || *port.Maximum != 65535 {
+		t.Errorf("kubernetes.port = %+v, want an integer within 1-65535", port)
+	}
+
+	timeout := schema.Properties["kubernetes"].Properties["health_probes"].Properties["liveness_probe"].Properties["timeout_seconds"]
+	if timeout.lessThan != "period_seconds" || timeout.Description == "" {
+		t.Errorf("timeout_seconds = %+v, want lessThan period_seconds", timeout)
+	}
+
+	labels := schema.Properties["kubernetes"].Properties["labels"]
+	if entry, ok := labels.AdditionalProperties.(*Schema); !ok || entry.Type != "string" {
+		t.Errorf("kubernetes.labels = %+v, want a map of strings", labels)
+	}
+
+	environments := schema.Properties["environments"]
+	entry, ok := environments.AdditionalProperties.(*Schema)
+	if !ok || entry.Properties["replicas"] == nil || strings.Join(entry.Properties["log_level"].Enum, ",") != "debug,info,warn,error" {
+		t.Errorf("environments = %+v, want a map of environment settings", environments)
+	}
+}
+
+func TestProjectConfigSchema_PrintedTypes(t *testing.T) {
+	data, err := json.Marshal(ProjectConfigSchema())
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var printed map[string]interface{}
+	if err := json.Unmarshal(data, &printed); err != nil {
+		t.Fatal(err)
+	}
+	printedType := func(schema map[string]interface{}) string {
+		data, _ := json.Marshal(schema["type"])
+		return string(data)
+	}
+
+	// Every string setting accepts a number in the printed schema exactly
+	// when the validator does
+	number := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: "1.0"}
+	ProjectConfigSchema().leaves(nil, func(segments []string, leaf *Schema) {
+		if leaf.Type != "string" {
+			return
+		}
+		schema := printed
+		for _, segment := range segments {
+			schema = schema["properties"].(map[string]interface{})[segment].(map[string]interface{})
+		}
+		path := strings.Join(segments, ".")
+
+		var errs ConfigErrors
+		leaf.validate(number, path, &errs)
+		accepted := strings.Contains(printedType(schema), `"number"`)
+		if accepted != (len(errs) == 0) {
+			t.Errorf("%s: printed type %s, validator errors %v", path, printedType(schema), errs)
+		}
+	})
+
+	properties := printed["properties"].(map[string]interface{})
+	for _, name := range []string{"version", "name"} {
+		if got := printedType(properties[name].(map[string]interface{})); got != `["string","number","boolean"]` {
+			t.Errorf("%s type = %s, want string, number or boolean", name, got)
+		}
+	}
+	if got := printedType(properties["tier"].(map[string]interface{})); got != `"string"` {
+		t.Errorf("tier type = %s, want string", got)
+	}
+	labels := properties["kubernetes"].(map[string]interface{})["properties"].(map[string]interface{})["labels"].(map[string]interface{})
+	if got := printedType(labels["additionalProperties"].(map[string]interface{})); got != `["string","number","boolean"]` {
+		t.Errorf("kubernetes.labels entry type = %s, want string, number or boolean", got)
+	}
+}
+
+func TestValidateConfigNode(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want []string
+	}{
+		{
+			name: "valid",
+			yaml: "name: orders\ntier: advanced\nkubernetes:\n  port: 8080\n",
+		},
+		{
+			name: "scalars decoded into strings",
+			yaml: "name: orders\nversion: 1.0\nkubernetes:\n  labels: {tier: 1, canary: true}\nenvironments:\n  production:\n    resources: {requests: {cpu: 0.5}}\n",
+		},
+		{
+			name: "unknown field and enum",
+			yaml: "name: orders\nnamespace: shop\ntier: huge\n",
+			want: []string{
+				`2:1: namespace: unknown field "namespace"`,
+				"3:7: tier: must be one of: basic, intermediate, advanced, enterprise",
+			},
+		},
+		{
+			name: "types",
+			yaml: "name: [orders]\nfeatures:\n  docker: yes please\nkubernetes: 8080\n",
+			want: []string{
+				"1:7: name: must be a string, got a list",
+				`3:11: features.docker: must be true or false, got string "yes please"`,
+				"4:13: kubernetes: must be a mapping, got number 8080",
+			},
+		},
+		{
+			name: "bounds",
+			yaml: "kubernetes:\n  port: 70000\nobservability:\n  metrics: {port: 0}\n",
+			want: []string{
+				"2:9: kubernetes.port: must be <= 65535",
+				"4:19: observability.metrics.port: must be >= 1",
+			},
+		},
+		{
+			name: "lessThan",
+			yaml: "kubernetes:\n  health_probes:\n    startup_probe:\n      timeout_seconds: 10\n      period_seconds: 10\n    readiness_probe: {timeout_seconds: 3, period_seconds: 5}\n",
+			want: []string{
+				"4:7: kubernetes.health_probes.startup_probe.timeout_seconds: must be < period_seconds",
+			},
+		},
+		{
+			name: "map entries",
+			yaml: "environments:\n  production:\n    replicas: 0\n    log_level: verbose\n",
+			want: []string{
+				"3:15: environments.production.replicas: must be >= 1",
+				"4:16: environments.production.log_level: must be one of: debug, info, warn, error",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var document yaml.Node
+			if err := yaml.Unmarshal([]byte(tt.yaml), &document); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, err := range ValidateConfigNode(document.Content[0], "") {
+				got = append(got, err.Error())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Errors =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestValidateConfigNode_Prefix(t *testing.T) {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte("kubernetes:\n  port: 0\n"), &document); err != nil {
+		t.Fatal(err)
+	}
+	errs := ValidateConfigNode(document.Content[0], "services[1]")
+	errs[0].File = "batch.yaml"
+	if got := errs.Error(); got != "batch.yaml:2:9: services[1].kubernetes.port: must be >= 1" {
+		t.Errorf("Error() = %q", got)
+	}
+}
+
+func TestConfigError(t *testing.T) {
+	tests := []struct {
+		err  ConfigError
+		want string
+	}{
+		{ConfigError{File: "a.yaml", Path: "tier", Line: 3, Column: 7, Message: "bad"}, "a.yaml:3:7: tier: bad"},
+		{ConfigError{File: "$TEMPLATE_HEALTH_TIER", Path: "tier", Message: "bad"}, "$TEMPLATE_HEALTH_TIER: tier: bad"},
+		{ConfigError{Path: "tier", Message: "bad"}, "tier: bad"},
+		{ConfigError{Message: "bad"}, "bad"},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+
+	errs := ConfigErrors{{Path: "a", Message: "x"}, {Path: "b", Message: "y"}}
+	if got := errs.Error(); got != "2 schema violations:\n  a: x\n  b: y" {
+		t.Errorf("ConfigErrors.Error() = %q", got)
+	}
+}
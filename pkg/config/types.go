@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -24,6 +27,9 @@ const (
 	TierEnterprise TemplateTier = "enterprise"
 )
 
+// Tiers lists the valid tiers from the simplest to the most complete
+var Tiers = []TemplateTier{TierBasic, TierIntermediate, TierAdvanced, TierEnterprise}
+
 // IsValid checks if the tier is a valid option
 func (t TemplateTier) IsValid() bool {
 	switch t {
@@ -57,6 +63,9 @@ func (t TemplateTier) Description() string {
 
 // ProjectConfig represents the configuration for generating a health endpoint project
 type ProjectConfig struct {
+	// Format of the configuration file, see ConfigAPIVersion
+	APIVersion string `yaml:"apiVersion,omitempty" mapstructure:"apiVersion"`
+
 	// Project metadata
 	Name        string `yaml:"name" mapstructure:"name"`
 	Version     string `yaml:"version" mapstructure:"version"`
@@ -78,6 +87,12 @@ type ProjectConfig struct {
 
 	// Observability configuration
 	Observability ObservabilityConfig `yaml:"observability" mapstructure:"observability"`
+
+	// Per-environment overrides keyed by environment name, such as staging
+	Environments map[string]EnvironmentConfig `yaml:"environments,omitempty" mapstructure:"environments"`
+
+	// Values for the variables declared by the tier's template.yaml
+	Variables map[string]string `yaml:"variables,omitempty" mapstructure:"variables"`
 }
 
 // FeatureConfig controls which features are enabled
@@ -106,7 +121,7 @@ type KubernetesConfig struct {
 	Enabled        bool              `yaml:"enabled" mapstructure:"enabled"`
 	Namespace      string            `yaml:"namespace" mapstructure:"namespace"`
 	ServiceName    string            `yaml:"service_name" mapstructure:"service_name"`
-	Port           int               `yaml:"port" mapstructure:"port"`
+	Port           int               `yaml:"port" mapstructure:"port" schema:"minimum=1,maximum=65535"`
 	Labels         map[string]string `yaml:"labels" mapstructure:"labels"`
 	Annotations    map[string]string `yaml:"annotations" mapstructure:"annotations"`
 	HealthProbes   HealthProbeConfig `yaml:"health_probes" mapstructure:"health_probes"`
@@ -125,11 +140,11 @@ type HealthProbeConfig struct {
 type ProbeConfig struct {
 	Enabled             bool          `yaml:"enabled" mapstructure:"enabled"`
 	Path                string        `yaml:"path" mapstructure:"path"`
-	InitialDelaySeconds int           `yaml:"initial_delay_seconds" mapstructure:"initial_delay_seconds"`
-	PeriodSeconds       int           `yaml:"period_seconds" mapstructure:"period_seconds"`
-	TimeoutSeconds      int           `yaml:"timeout_seconds" mapstructure:"timeout_seconds"`
-	FailureThreshold    int           `yaml:"failure_threshold" mapstructure:"failure_threshold"`
-	SuccessThreshold    int           `yaml:"success_threshold" mapstructure:"success_threshold"`
+	InitialDelaySeconds int           `yaml:"initial_delay_seconds" mapstructure:"initial_delay_seconds" schema:"minimum=0"`
+	PeriodSeconds       int           `yaml:"period_seconds" mapstructure:"period_seconds" schema:"minimum=1"`
+	TimeoutSeconds      int           `yaml:"timeout_seconds" mapstructure:"timeout_seconds" schema:"minimum=1,lessThan=period_seconds"`
+	FailureThreshold    int           `yaml:"failure_threshold" mapstructure:"failure_threshold" schema:"minimum=1"`
+	SuccessThreshold    int           `yaml:"success_threshold" mapstructure:"success_threshold" schema:"minimum=1"`
 }
 
 // IngressConfig configures Kubernetes Ingress
@@ -179,33 +194,83 @@ type CloudEventsConfig struct {
 type MetricsConfig struct {
 	Enabled    bool   `yaml:"enabled" mapstructure:"enabled"`
 	Prometheus bool   `yaml:"prometheus" mapstructure:"prometheus"`
-	Port       int    `yaml:"port" mapstructure:"port"`
+	Port       int    `yaml:"port" mapstructure:"port" schema:"minimum=1,maximum=65535"`
 	Path       string `yaml:"path" mapstructure:"path"`
 }
 
-// Validate validates the project configuration
-func (c *ProjectConfig) Validate() error {
-	if c.Name == "" {
-		return fmt.Errorf("project name is required")
-	}
+// LogLevel is the minimum level of the log messages a service writes
+type LogLevel string
 
-	if !c.Tier.IsValid() {
-		return fmt.Errorf("invalid tier: %s (must be one of: basic, intermediate, advanced, enterprise)", c.Tier)
-	}
+// LogLevels lists the valid log levels from the most verbose to the least
+var LogLevels = []LogLevel{"debug", "info", "warn", "error"}
 
-	if c.GoModule == "" {
-		return fmt.Errorf("go module path is required")
-	}
+// EnvironmentConfig overrides settings for one deployment environment.
+// Unset values keep the defaults of the base configuration and manifests.
+type EnvironmentConfig struct {
+	Replicas     int             `yaml:"replicas,omitempty" mapstructure:"replicas" schema:"minimum=1"`
+	Resources    ResourcesConfig `yaml:"resources,omitempty" mapstructure:"resources"`
+	OTelEndpoint string          `yaml:"otel_endpoint,omitempty" mapstructure:"otel_endpoint"`
+	LogLevel     LogLevel        `yaml:"log_level,omitempty" mapstructure:"log_level"`
+	MTLS         MTLSConfig      `yaml:"mtls,omitempty" mapstructure:"mtls"`
+}
 
-	if c.OutputDir == "" {
-		c.OutputDir = c.Name
-	}
+// ResourcesConfig configures the compute resources of the service container
+type ResourcesConfig struct {
+	Requests ResourceList `yaml:"requests,omitempty" mapstructure:"requests"`
+	Limits   ResourceList `yaml:"limits,omitempty" mapstructure:"limits"`
+}
+
+// ResourceList holds Kubernetes resource quantities, such as 250m or 256Mi
+type ResourceList struct {
+	CPU    string `yaml:"cpu,omitempty" mapstructure:"cpu"`
+	Memory string `yaml:"memory,omitempty" mapstructure:"memory"`
+}
 
-	if c.Version == "" {
-		c.Version = "1.0.0"
+// DefaultResources are the compute resources of the base deployment manifest
+var DefaultResources = ResourcesConfig{
+	Requests: ResourceList{CPU: "50m", Memory: "64Mi"},
+	Limits:   ResourceList{CPU: "100m", Memory: "128Mi"},
+}
+
+// Effective returns the resources a deployment gets: unset requests take the
+// defaults, and an unset limit takes the default limit or, when the request
+// is higher, the request, so a default limit never falls below the request.
+func (r ResourcesConfig) Effective() ResourcesConfig {
+	effective := func(request, limit, defaultRequest, defaultLimit string) (string, string) {
+		if request == "" {
+			request = defaultRequest
+		}
+		if limit == "" {
+			limit = defaultLimit
+			if exceeds(request, limit) {
+				limit = request
+			}
+		}
+		return request, limit
 	}
 
-	return nil
+	var e ResourcesConfig
+	e.Requests.CPU, e.Limits.CPU = effective(r.Requests.CPU, r.Limits.CPU, DefaultResources.Requests.CPU, DefaultResources.Limits.CPU)
+	e.Requests.Memory, e.Limits.Memory = effective(r.Requests.Memory, r.Limits.Memory, DefaultResources.Requests.Memory, DefaultResources.Limits.Memory)
+	return e
+}
+
+// MTLSConfig configures mutual TLS between services
+type MTLSConfig struct {
+	Enabled  bool   `yaml:"enabled,omitempty" mapstructure:"enabled"`
+	CertFile string `yaml:"cert_file,omitempty" mapstructure:"cert_file"`
+	KeyFile  string `yaml:"key_file,omitempty" mapstructure:"key_file"`
+	CAFile   string `yaml:"ca_file,omitempty" mapstructure:"ca_file"`
+}
+
+// EnvironmentNames returns the names of the configured environments sorted
+func (c *ProjectConfig) EnvironmentNames() []string {
+	names := make([]string, 0, len(c.Environments))
+	for name := range c.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 // ApplyTierDefaults applies default configuration based on the selected tier
@@ -339,23 +404,74 @@ type TemplateConfig struct {
 	Version     string            `yaml:"version"`
 	Features    map[string]bool   `yaml:"features"`
 	Metadata    map[string]string `yaml:"metadata,omitempty"`
+	Variables   []VariableSpec    `yaml:"variables,omitempty"`
+
+	// Extends names the tier this tier is a delta over. Files of the parent
+	// tier are inherited unless the tier provides its own copy or lists
+	// them under Delete; Overrides lists the inherited files the tier
+	// replaces on purpose.
+	Extends   string   `yaml:"extends,omitempty"`
+	Overrides []string `yaml:"overrides,omitempty"`
+	Delete    []string `yaml:"delete,omitempty"`
 }
 
+// TemplateConfigFile is the file describing a template tier
+const TemplateConfigFile = "template.yaml"
+
+// DefaultTemplatesDir holds a directory per template tier, relative to the working directory
+const DefaultTemplatesDir = "templates"
+
 // LoadTemplateConfig loads a template configuration from a YAML file
 func LoadTemplateConfig(path string) (*TemplateConfig, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read template config: %w", err)
 	}
+	return ParseTemplateConfig(path, data)
+}
 
+// ParseTemplateConfig parses and validates a template configuration; name
+// identifies the file in errors
+func ParseTemplateConfig(name string, data []byte) (*TemplateConfig, error) {
 	var config TemplateConfig
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse template config: %w", err)
 	}
 
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid template config %s: %w", name, err)
+	}
+
 	return &config, nil
 }
 
+// Validate checks the variable declarations of the template configuration
+// and the file paths it overrides or deletes
+func (t *TemplateConfig) Validate() error {
+	for _, list := range [][]string{t.Overrides, t.Delete} {
+		for _, file := range list {
+			if file == "" || file != path.Clean(file) || path.IsAbs(file) || strings.HasPrefix(file, "../") || file == TemplateConfigFile {
+				return fmt.Errorf("invalid file path %q (must be a clean relative path to a template file)", file)
+			}
+		}
+	}
+	if len(t.Delete) > 0 && t.Extends == "" {
+		return fmt.Errorf("delete needs extends: only inherited files can be deleted")
+	}
+
+	seen := make(map[string]bool)
+	for _, spec := range t.Variables {
+		if seen[spec.Name] {
+			return fmt.Errorf("variable %s is declared twice", spec.Name)
+		}
+		seen[spec.Name] = true
+		if err := spec.Check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GeneratorConfig represents the configuration for the generator
 type GeneratorConfig struct {
 	ProjectName string            `yaml:"project_name"`
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ConfigAPIVersion is the apiVersion of the project configuration files
+	// this version of the tool reads without upgrading
+	ConfigAPIVersion = "v1"
+
+	// legacyAPIVersion is the version of files written before apiVersion existed
+	legacyAPIVersion = "v0"
+)
+
+// ConfigUpgrade turns a project configuration document of one apiVersion
+// into the next, returning deprecation warnings for what it changed, such as
+// renamed settings. Upgrade edits the top-level mapping in place so
+// comments survive; apiVersion itself is updated by the caller.
+type ConfigUpgrade struct {
+	To      string
+	Upgrade func(root *yaml.Node) ([]string, error)
+}
+
+// configUpgrades is the registry of upgrades keyed by the apiVersion they
+// upgrade from. Following the chain from any version must reach
+// ConfigAPIVersion.
+var configUpgrades = map[string]ConfigUpgrade{
+	// v1 introduced apiVersion without changing any setting
+	legacyAPIVersion: {To: "v1", Upgrade: func(*yaml.Node) ([]string, error) { return nil, nil }},
+}
+
+// latestAPIVersion is the version the upgrade chain ends at, ConfigAPIVersion
+// outside of tests
+var latestAPIVersion = ConfigAPIVersion
+
+// ConfigMigration describes how a configuration document was upgraded
+type ConfigMigration struct {
+	From     string
+	To       string
+	Warnings []string
+}
+
+// UpgradeConfigNode upgrades the top-level mapping of a project
+// configuration document to ConfigAPIVersion in place, leaving comments
+// and formatting of untouched settings alone. Files without apiVersion
+// are v0. It returns nil when the document is already current.
+func UpgradeConfigNode(root *yaml.Node) (*ConfigMigration, error) {
+	if root.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	version, versionNode := legacyAPIVersion, mappingValue(root, "apiVersion")
+	if versionNode != nil {
+		version = versionNode.Value
+	}
+	if version == latestAPIVersion {
+		return nil, nil
+	}
+
+	migration := &ConfigMigration{From: version}
+	for version != latestAPIVersion {
+		upgrade, ok := configUpgrades[version]
+		if !ok {
+			return nil, fmt.Errorf("unsupported apiVersion %q (this version of the tool reads up to %s)", version, latestAPIVersion)
+		}
+		warnings, err := upgrade.Upgrade(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upgrade from apiVersion %s to %s: %w", version, upgrade.To, err)
+		}
+		migration.Warnings = append(migration.Warnings, warnings...)
+		version = upgrade.To
+	}
+	migration.To = version
+
+	if versionNode == nil {
+		// Put apiVersion first, below any comment heading the file
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "apiVersion"}
+		if len(root.Content) > 0 {
+			key.HeadComment, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+		}
+		versionNode = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str"}
+		root.Content = append([]*yaml.Node{key, versionNode}, root.Content...)
+	}
+	versionNode.Value, versionNode.Tag, versionNode.Style = version, "!!str", 0
+	return migration, nil
+}
+
+// mappingValue returns the value stored under key in a mapping node
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// renameMappingKey renames the setting at a dot-separated path below a
+// mapping node, such as kubernetes.service_monitor, to newName in the same
+// mapping. Only the key changes, so its value and comments are kept. It
+// reports whether the setting was there and fails when newName is already set.
+func renameMappingKey(root *yaml.Node, path, newName string) (bool, error) {
+	keys := strings.Split(path, ".")
+	parent := root
+	for _, key := range keys[:len(keys)-1] {
+		parent = mappingValue(parent, key)
+		if parent == nil || parent.Kind != yaml.MappingNode {
+			return false, nil
+		}
+	}
+
+	name := keys[len(keys)-1]
+	for i := 0; i+1 < len(parent.Content); i += 2 {
+		if parent.Content[i].Value != name {
+			continue
+		}
+		if mappingValue(parent, newName) != nil {
+			return false, fmt.Errorf("cannot rename %s: %s is set as well", path, newName)
+		}
+		parent.Content[i].Value = newName
+		return true, nil
+	}
+	return false, nil
+}
+
+// MigratedConfig is a configuration file upgraded by MigrateConfigFile
+type MigratedConfig struct {
+	Migration *ConfigMigration // nil when the file was already current or empty
+	Before    []byte
+	After     []byte
+}
+
+// MigrateConfigFile upgrades a project configuration file to
+// ConfigAPIVersion, keeping its comments, indentation and the order of
+// settings; blank lines are not kept. With write the file is replaced atomically, keeping
+// its mode; otherwise it is left alone and After shows the result.
+func MigrateConfigFile(file string, write bool) (*MigratedConfig, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	result := &MigratedConfig{Before: data, After: data}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", file, err)
+	}
+	if len(document.Content) == 0 {
+		return result, nil
+	}
+
+	result.Migration, err = UpgradeConfigNode(document.Content[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to upgrade %s: %w", file, err)
+	}
+	if result.Migration == nil {
+		return result, nil
+	}
+	if errs := ValidateConfigNode(document.Content[0], ""); len(errs) > 0 {
+		for i := range errs {
+			errs[i].File = file
+		}
+		return nil, fmt.Errorf("upgraded %s does not match the schema: %w", file, errs)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(yamlIndent(data))
+	if err := encoder.Encode(&document); err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", file, err)
+	}
+	encoder.Close()
+	result.After = buf.Bytes()
+
+	if write {
+		if err := replaceFile(file, result.After); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// yamlIndent returns the indentation a YAML file uses for nested blocks,
+// two spaces if it has none
+func yamlIndent(data []byte) int {
+	indent := 0
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if n := len(line) - len(trimmed); n > 0 && trimmed != "" && !strings.HasPrefix(trimmed, "#") && (indent == 0 || n < indent) {
+			indent = n
+		}
+	}
+	if indent == 0 {
+		return 2
+	}
+	return indent
+}
+
+// replaceFile atomically replaces the contents of a file, keeping its mode
+func replaceFile(file string, data []byte) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return fmt.Errorf("failed to access %s: %w", file, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+"-")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), info.Mode().Perm())
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), file)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestUpgradeConfigNode(t *testing.T) {
+	upgrade := func(data string) (string, *ConfigMigration, error) {
+		var document yaml.Node
+		if err := yaml.Unmarshal([]byte(data), &document); err != nil {
+			t.Fatal(err)
+		}
+		migration, err := UpgradeConfigNode(document.Content[0])
+		if err != nil {
+			return "", nil, err
+		}
+		out, err := yaml.Marshal(&document)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(out), migration, nil
+	}
+
+	// apiVersion goes first, below the comment heading the file
+	out, migration, err := upgrade("# Orders service\nname: orders # inline\n")
+	if err != nil {
+		t.Fatalf("UpgradeConfigNode() error = %v", err)
+	}
+	if migration == nil || migration.From != "v0" || migration.To != ConfigAPIVersion {
+		t.Errorf("Migration = %+v, want v0 to %s", migration, ConfigAPIVersion)
+	}
+	if want := "# Orders service\napiVersion: v1\nname: orders # inline\n"; out != want {
+		t.Errorf("Upgraded =\n%s\nwant\n%s", out, want)
+	}
+
+	// An explicit v0 is replaced in place
+	out, _, err = upgrade("name: orders\napiVersion: v0\n")
+	if err != nil || out != "name: orders\napiVersion: v1\n" {
+		t.Errorf("Upgraded = %q, %v", out, err)
+	}
+
+	if _, migration, err := upgrade("apiVersion: v1\nname: orders\n"); migration != nil || err != nil {
+		t.Errorf("Current file migration = %+v, %v, want nil", migration, err)
+	}
+
+	_, _, err = upgrade("apiVersion: v9\n")
+	if err == nil || !strings.Contains(err.Error(), `unsupported apiVersion "v9"`) {
+		t.Errorf("UpgradeConfigNode(v9) error = %v", err)
+	}
+}
+
+func TestUpgradeConfigNode_Chain(t *testing.T) {
+	defer func(upgrades map[string]ConfigUpgrade, latest string) {
+		configUpgrades, latestAPIVersion = upgrades, latest
+	}(configUpgrades, latestAPIVersion)
+
+	// A future v2 renames a setting, on top of the v0 to v1 upgrade
+	configUpgrades = map[string]ConfigUpgrade{
+		legacyAPIVersion: configUpgrades[legacyAPIVersion],
+		"v1": {To: "v2", Upgrade: func(root *yaml.Node) ([]string, error) {
+			renamed, err := renameMappingKey(root, "kubernetes.service_monitor", "monitor")
+			if !renamed || err != nil {
+				return nil, err
+			}
+			return []string{"kubernetes.service_monitor is deprecated; use kubernetes.monitor"}, nil
+		}},
+	}
+	latestAPIVersion = "v2"
+
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(`# Orders service
+name: orders
+kubernetes:
+    # scraped by Prometheus
+    service_monitor: true # needs metrics
+`), &document); err != nil {
+		t.Fatal(err)
+	}
+	migration, err := UpgradeConfigNode(document.Content[0])
+	if err != nil {
+		t.Fatalf("UpgradeConfigNode() error = %v", err)
+	}
+	if migration.From != "v0" || migration.To != "v2" {
+		t.Errorf("Migration = %s to %s, want v0 to v2", migration.From, migration.To)
+	}
+	if want := []string{"kubernetes.service_monitor is deprecated; use kubernetes.monitor"}; !reflect.DeepEqual(migration.Warnings, want) {
+		t.Errorf("Warnings = %q, want %q", migration.Warnings, want)
+	}
+
+	out, err := yaml.Marshal(&document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# Orders service
+apiVersion: v2
+name: orders
+kubernetes:
+    # scraped by Prometheus
+    monitor: true # needs metrics
+`
+	if string(out) != want {
+		t.Errorf("Upgraded =\n%s\nwant\n%s", out, want)
+	}
+
+	// A file setting both names is not silently merged
+	if err := yaml.Unmarshal([]byte("kubernetes:\n  service_monitor: true\n  monitor: false\n"), &document); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UpgradeConfigNode(document.Content[0]); err == nil || !strings.Contains(err.Error(), "monitor is set as well") {
+		t.Errorf("UpgradeConfigNode() error = %v, want a rename conflict", err)
+	}
+}
+
+func TestMigrateConfigFile(t *testing.T) {
+	original := `# Orders service
+# owned by the shop team
+name: orders
+tier: advanced   # until the audit is done
+
+kubernetes:
+    # shared namespace
+    namespace: shop
+    labels:
+        team: shop
+`
+	// yaml.v3 keeps comments but not blank lines
+	want := `# Orders service
+# owned by the shop team
+apiVersion: v1
+name: orders
+tier: advanced # until the audit is done
+kubernetes:
+    # shared namespace
+    namespace: shop
+    labels:
+        team: shop
+`
+	file := filepath.Join(t.TempDir(), "service.yaml")
+	if err := os.WriteFile(file, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A dry run leaves the file alone
+	result, err := MigrateConfigFile(file, false)
+	if err != nil {
+		t.Fatalf("MigrateConfigFile() error = %v", err)
+	}
+	if string(result.Before) != original || string(result.After) != want {
+		t.Errorf("After =\n%s\nwant\n%s", result.After, want)
+	}
+	if data, _ := os.ReadFile(file); string(data) != original {
+		t.Errorf("Dry run changed the file:\n%s", data)
+	}
+
+	if _, err := MigrateConfigFile(file, true); err != nil {
+		t.Fatalf("MigrateConfigFile() error = %v", err)
+	}
+	data, _ := os.ReadFile(file)
+	if string(data) != want {
+		t.Errorf("Migrated file =\n%s\nwant\n%s", data, want)
+	}
+	if info, _ := os.Stat(file); info.Mode().Perm() != 0600 {
+		t.Errorf("Mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	result, err = MigrateConfigFile(file, true)
+	if err != nil || result.Migration != nil {
+		t.Errorf("Second migration = %+v, %v, want nothing to do", result, err)
+	}
+
+	if err := os.WriteFile(file, []byte("apiVersion: v9\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MigrateConfigFile(file, true); err == nil {
+		t.Error("MigrateConfigFile() accepted an unsupported apiVersion")
+	}
+}
@@ -0,0 +1,255 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ValidationError is a setting that would make generation or deployment fail
+type ValidationError struct {
+	Path       string // YAML path of the setting, such as kubernetes.ingress.host
+	Message    string
+	Suggestion string // how to fix it
+}
+
+// Error formats the error as path: message; suggestion
+func (e ValidationError) Error() string {
+	if e.Suggestion == "" {
+		return fmt.Sprintf("%s: %s", e.Path, e.Message)
+	}
+	return fmt.Sprintf("%s: %s; %s", e.Path, e.Message, e.Suggestion)
+}
+
+// ValidationErrors is every problem Validate found, in the order checked
+type ValidationErrors []ValidationError
+
+// Error lists the errors one per line
+func (e ValidationErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = "\n  " + err.Error()
+	}
+	return fmt.Sprintf("%d problems:%s", len(e), strings.Join(lines, ""))
+}
+
+// dns1123Label is what Kubernetes accepts as the name of a service or namespace
+var dns1123Label = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// resourceQuantity is a Kubernetes resource quantity without exponent notation
+var resourceQuantity = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(m|k|M|G|T|P|E|Ki|Mi|Gi|Ti|Pi|Ei)?$`)
+
+// Validate validates the project configuration, including the rules that
+// relate settings to each other, and fills in the output directory and
+// version. All problems are returned together as ValidationErrors.
+func (c *ProjectConfig) Validate() error {
+	var errs ValidationErrors
+	fail := func(path, suggestion, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...), Suggestion: suggestion})
+	}
+
+	if c.Name == "" {
+		fail("name", "set name or pass --name", "project name is required")
+	} else if !isDNS1123Label(c.Name) {
+		fail("name", fmt.Sprintf("use %q", dns1123Suggestion(c.Name)),
+			"%q is not a valid Kubernetes name (lowercase letters, digits and '-', starting and ending with a letter or digit, at most 63 characters)", c.Name)
+	}
+
+	if !c.Tier.IsValid() {
+		fail("tier", "use one of: basic, intermediate, advanced, enterprise", "invalid tier: %s", c.Tier)
+	}
+
+	if c.GoModule == "" {
+		fail("go_module", "set go_module or pass --module", "go module path is required")
+	} else if strings.ContainsAny(c.GoModule, " \t\n") {
+		fail("go_module", fmt.Sprintf("use %q", strings.Join(strings.Fields(c.GoModule), "-")),
+			"go module path %q contains whitespace", c.GoModule)
+	}
+
+	if c.Kubernetes.ServiceName != "" && !isDNS1123Label(c.Kubernetes.ServiceName) {
+		fail("kubernetes.service_name", fmt.Sprintf("use %q", dns1123Suggestion(c.Kubernetes.ServiceName)),
+			"%q is not a valid Kubernetes name", c.Kubernetes.ServiceName)
+	}
+	if c.Kubernetes.Namespace != "" && !isDNS1123Label(c.Kubernetes.Namespace) {
+		fail("kubernetes.namespace", fmt.Sprintf("use %q", dns1123Suggestion(c.Kubernetes.Namespace)),
+			"%q is not a valid Kubernetes namespace", c.Kubernetes.Namespace)
+	}
+
+	// Zero ports are unset and get the template defaults
+	ports := []struct {
+		path string
+		port int
+	}{
+		{"kubernetes.port", c.Kubernetes.Port},
+		{"observability.metrics.port", c.Observability.Metrics.Port},
+	}
+	for _, p := range ports {
+		if p.port < 0 || p.port > 65535 {
+			fail(p.path, "use a port between 1 and 65535, such as 8080", "port %d is out of range", p.port)
+		}
+	}
+
+	probes := []struct {
+		name  string
+		probe ProbeConfig
+	}{
+		{"liveness_probe", c.Kubernetes.HealthProbes.LivenessProbe},
+		{"readiness_probe", c.Kubernetes.HealthProbes.ReadinessProbe},
+		{"startup_probe", c.Kubernetes.HealthProbes.StartupProbe},
+	}
+	for _, p := range probes {
+		if p.probe.PeriodSeconds > 0 && p.probe.TimeoutSeconds >= p.probe.PeriodSeconds {
+			fail("kubernetes.health_probes."+p.name+".timeout_seconds",
+				fmt.Sprintf("lower timeout_seconds below %d or raise period_seconds", p.probe.PeriodSeconds),
+				"must be < period_seconds (%d >= %d)", p.probe.TimeoutSeconds, p.probe.PeriodSeconds)
+		}
+	}
+
+	if c.Kubernetes.Ingress.Enabled && c.Kubernetes.Ingress.TLS && c.Kubernetes.Ingress.Host == "" {
+		fail("kubernetes.ingress.host", fmt.Sprintf("set the host the certificate is for, such as %s.example.com, or disable kubernetes.ingress.tls", dns1123Suggestion(c.Name)),
+			"an ingress with TLS needs a host")
+	}
+
+	if c.Kubernetes.ServiceMonitor && !c.Observability.Metrics.Enabled {
+		fail("kubernetes.service_monitor", "enable observability.metrics.enabled or disable kubernetes.service_monitor",
+			"a ServiceMonitor needs metrics to scrape")
+	}
+
+	if c.Features.Compliance && !c.Features.Security {
+		fail("features.compliance", "enable features.security or disable features.compliance",
+			"compliance needs the security feature")
+	}
+
+	for _, name := range c.EnvironmentNames() {
+		env, prefix := c.Environments[name], "environments."+name
+		if !isDNS1123Label(name) {
+			fail(prefix, fmt.Sprintf("use %q", dns1123Suggestion(name)),
+				"%q is not a valid environment name; it names the Kubernetes overlay and config file", name)
+		}
+
+		quantities := []struct {
+			path     string
+			quantity string
+		}{
+			{"resources.requests.cpu", env.Resources.Requests.CPU},
+			{"resources.requests.memory", env.Resources.Requests.Memory},
+			{"resources.limits.cpu", env.Resources.Limits.CPU},
+			{"resources.limits.memory", env.Resources.Limits.Memory},
+		}
+		for _, q := range quantities {
+			if q.quantity != "" && !resourceQuantity.MatchString(q.quantity) {
+				fail(prefix+"."+q.path, "use a Kubernetes quantity such as 250m for CPU or 256Mi for memory",
+					"%q is not a valid resource quantity", q.quantity)
+			}
+		}
+
+		// Kubernetes rejects a container whose request is above its limit
+		resources := env.Resources.Effective()
+		limits := []struct {
+			name           string
+			request, limit string
+		}{
+			{"cpu", resources.Requests.CPU, resources.Limits.CPU},
+			{"memory", resources.Requests.Memory, resources.Limits.Memory},
+		}
+		for _, l := range limits {
+			if exceeds(l.request, l.limit) {
+				fail(prefix+".resources.limits."+l.name,
+					fmt.Sprintf("raise resources.limits.%s to at least %s or lower resources.requests.%s", l.name, l.request, l.name),
+					"limit %s is below the request %s", l.limit, l.request)
+			}
+		}
+
+		if env.MTLS.Enabled {
+			files := []struct {
+				path string
+				file string
+			}{
+				{"mtls.cert_file", env.MTLS.CertFile},
+				{"mtls.key_file", env.MTLS.KeyFile},
+				{"mtls.ca_file", env.MTLS.CAFile},
+			}
+			for _, f := range files {
+				if f.file == "" {
+					fail(prefix+"."+f.path, "set the path the file is mounted at or disable "+prefix+".mtls.enabled",
+						"mTLS needs the certificate, key and CA files")
+				}
+			}
+			if !c.Features.Security {
+				fail(prefix+".mtls.enabled", "enable features.security or disable "+prefix+".mtls.enabled",
+					"mTLS needs the security feature")
+			}
+		}
+	}
+
+	if c.OutputDir == "" {
+		c.OutputDir = c.Name
+	}
+
+	if c.Version == "" {
+		c.Version = "1.0.0"
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// quantitySuffixes are the multipliers of the Kubernetes quantity suffixes
+var quantitySuffixes = map[string]float64{
+	"":  1,
+	"m": 1e-3,
+	"k": 1e3, "M": 1e6, "G": 1e9, "T": 1e12, "P": 1e15, "E": 1e18,
+	"Ki": 1 << 10, "Mi": 1 << 20, "Gi": 1 << 30, "Ti": 1 << 40, "Pi": 1 << 50, "Ei": 1 << 60,
+}
+
+// parseQuantity returns the value of a Kubernetes resource quantity
+func parseQuantity(quantity string) (float64, bool) {
+	if !resourceQuantity.MatchString(quantity) {
+		return 0, false
+	}
+	number := strings.TrimRight(quantity, "mkMGTPEi")
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, false
+	}
+	return value * quantitySuffixes[quantity[len(number):]], true
+}
+
+// exceeds reports whether quantity a is larger than quantity b; invalid
+// quantities never exceed
+func exceeds(a, b string) bool {
+	x, okA := parseQuantity(a)
+	y, okB := parseQuantity(b)
+	return okA && okB && x > y
+}
+
+// isDNS1123Label reports whether name is a valid Kubernetes object name
+func isDNS1123Label(name string) bool {
+	return len(name) <= 63 && dns1123Label.MatchString(name)
+}
+
+// dns1123Suggestion turns name into a valid Kubernetes object name
+func dns1123Suggestion(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else if !strings.HasSuffix(b.String(), "-") {
+			b.WriteByte('-')
+		}
+	}
+	suggestion := strings.Trim(b.String(), "-")
+	if len(suggestion) > 63 {
+		suggestion = strings.TrimRight(suggestion[:63], "-")
+	}
+	if suggestion == "" {
+		return "my-service"
+	}
+	return suggestion
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// validConfig returns a configuration that passes Validate
+func validConfig() *ProjectConfig {
+	cfg := &ProjectConfig{Name: "orders", GoModule: "github.com/acme/orders", Tier: TierEnterprise}
+	cfg.ApplyTierDefaults()
+	cfg.Kubernetes.Ingress.Host = "orders.example.com"
+	return cfg
+}
+
+func TestValidate_Rules(t *testing.T) {
+	tests := []struct {
+		name       string
+		modify     func(*ProjectConfig)
+		path       string
+		suggestion string
+	}{
+		{"missing name", func(c *ProjectConfig) { c.Name = "" }, "name", "set name or pass --name"},
+		{"invalid name", func(c *ProjectConfig) { c.Name = "Orders_API" }, "name", `use "orders-api"`},
+		{"long name", func(c *ProjectConfig) { c.Name = strings.Repeat("a", 64) }, "name", `use "` + strings.Repeat("a", 63) + `"`},
+		{"invalid tier", func(c *ProjectConfig) { c.Tier = "huge" }, "tier", "use one of: basic, intermediate, advanced, enterprise"},
+		{"missing module", func(c *ProjectConfig) { c.GoModule = "" }, "go_module", "set go_module or pass --module"},
+		{"module with whitespace", func(c *ProjectConfig) { c.GoModule = "github.com/acme/my orders" }, "go_module", `use "github.com/acme/my-orders"`},
+		{"invalid service name", func(c *ProjectConfig) { c.Kubernetes.ServiceName = "Orders" }, "kubernetes.service_name", `use "orders"`},
+		{"invalid namespace", func(c *ProjectConfig) { c.Kubernetes.Namespace = "shop.eu" }, "kubernetes.namespace", `use "shop-eu"`},
+		{"port too high", func(c *ProjectConfig) { c.Kubernetes.Port = 65536 }, "kubernetes.port", "use a port between 1 and 65535, such as 8080"},
+		{"negative metrics port", func(c *ProjectConfig) { c.Observability.Metrics.Port = -1 }, "observability.metrics.port", "use a port between 1 and 65535, such as 8080"},
+		{"probe timeout", func(c *ProjectConfig) { c.Kubernetes.HealthProbes.LivenessProbe.TimeoutSeconds = 10 },
+			"kubernetes.health_probes.liveness_probe.timeout_seconds", "lower timeout_seconds below 10 or raise period_seconds"},
+		{"TLS ingress without host", func(c *ProjectConfig) { c.Kubernetes.Ingress.TLS, c.Kubernetes.Ingress.Host = true, "" },
+			"kubernetes.ingress.host", "set the host the certificate is for, such as orders.example.com, or disable kubernetes.ingress.tls"},
+		{"ServiceMonitor without metrics", func(c *ProjectConfig) { c.Observability.Metrics.Enabled = false },
+			"kubernetes.service_monitor", "enable observability.metrics.enabled or disable kubernetes.service_monitor"},
+		{"compliance without security", func(c *ProjectConfig) { c.Features.Security = false },
+			"features.compliance", "enable features.security or disable features.compliance"},
+		{"invalid environment name", func(c *ProjectConfig) { c.Environments = map[string]EnvironmentConfig{"Prod EU": {}} },
+			"environments.Prod EU", `use "prod-eu"`},
+		{"invalid resource quantity", func(c *ProjectConfig) {
+			c.Environments = map[string]EnvironmentConfig{"prod": {Resources: ResourcesConfig{Limits: ResourceList{Memory: "1GB"}}}}
+		}, "environments.prod.resources.limits.memory", "use a Kubernetes quantity such as 250m for CPU or 256Mi for memory"},
+		{"request above limit", func(c *ProjectConfig) {
+			c.Environments = map[string]EnvironmentConfig{"prod": {Resources: ResourcesConfig{
+				Requests: ResourceList{Memory: "1Gi"}, Limits: ResourceList{Memory: "512Mi"}}}}
+		}, "environments.prod.resources.limits.memory", "raise resources.limits.memory to at least 1Gi or lower resources.requests.memory"},
+		{"limit below default request", func(c *ProjectConfig) {
+			c.Environments = map[string]EnvironmentConfig{"prod": {Resources: ResourcesConfig{Limits: ResourceList{CPU: "20m"}}}}
+		}, "environments.prod.resources.limits.cpu", "raise resources.limits.cpu to at least 50m or lower resources.requests.cpu"},
+		{"mTLS without files", func(c *ProjectConfig) {
+			c.Environments = map[string]EnvironmentConfig{"prod": {MTLS: MTLSConfig{Enabled: true, CertFile: "/c", KeyFile: "/k"}}}
+		}, "environments.prod.mtls.ca_file", "set the path the file is mounted at or disable environments.prod.mtls.enabled"},
+	}
+
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("Validate() of the base configuration error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(cfg)
+
+			var problems ValidationErrors
+			if err := cfg.Validate(); !errors.As(err, &problems) {
+				t.Fatalf("Validate() error = %v, want ValidationErrors", err)
+			}
+			if len(problems) != 1 || problems[0].Path != tt.path || problems[0].Suggestion != tt.suggestion {
+				t.Errorf("Validate() = %v, want only %s with suggestion %q", problems, tt.path, tt.suggestion)
+			}
+		})
+	}
+}
+
+func TestResourcesConfig_Effective(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources ResourcesConfig
+		want      ResourcesConfig
+	}{
+		{"unset", ResourcesConfig{}, DefaultResources},
+		{"request above default limit", ResourcesConfig{Requests: ResourceList{CPU: "500m", Memory: "1Gi"}},
+			ResourcesConfig{Requests: ResourceList{CPU: "500m", Memory: "1Gi"}, Limits: ResourceList{CPU: "500m", Memory: "1Gi"}}},
+		{"request below default limit", ResourcesConfig{Requests: ResourceList{CPU: "0.08"}},
+			ResourcesConfig{Requests: ResourceList{CPU: "0.08", Memory: "64Mi"}, Limits: DefaultResources.Limits}},
+		{"explicit limit", ResourcesConfig{Requests: ResourceList{CPU: "1"}, Limits: ResourceList{CPU: "2"}},
+			ResourcesConfig{Requests: ResourceList{CPU: "1", Memory: "64Mi"}, Limits: ResourceList{CPU: "2", Memory: "128Mi"}}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.resources.Effective(); got != tt.want {
+			t.Errorf("%s: Effective() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidate_AllProblems(t *testing.T) {
+	cfg := validConfig()
+	cfg.Name = "Orders"
+	cfg.GoModule = "my orders"
+	cfg.Kubernetes.Port = 70000
+	cfg.Features.Security = false
+
+	err := cfg.Validate()
+	var problems ValidationErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("Validate() error = %v, want ValidationErrors", err)
+	}
+	want := `4 problems:
+  name: "Orders" is not a valid Kubernetes name (lowercase letters, digits and '-', starting and ending with a letter or digit, at most 63 characters); use "orders"
+  go_module: go module path "my orders" contains whitespace; use "my-orders"
+  kubernetes.port: port 70000 is out of range; use a port between 1 and 65535, such as 8080
+  features.compliance: compliance needs the security feature; enable features.security or disable features.compliance`
+	if err.Error() != want {
+		t.Errorf("Error() =\n%s\nwant\n%s", err, want)
+	}
+
+	// Defaults are filled in even when there are problems
+	if cfg.OutputDir != "Orders" || cfg.Version != "1.0.0" {
+		t.Errorf("OutputDir, Version = %q, %q", cfg.OutputDir, cfg.Version)
+	}
+}
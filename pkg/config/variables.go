@@ -0,0 +1,250 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VariableType is the type of a template variable
+type VariableType string
+
+const (
+	// VariableString holds any text, optionally restricted by a pattern
+	VariableString VariableType = "string"
+
+	// VariableInt holds a whole number
+	VariableInt VariableType = "int"
+
+	// VariableBool holds true or false
+	VariableBool VariableType = "bool"
+
+	// VariableEnum holds one of the values listed in enum
+	VariableEnum VariableType = "enum"
+)
+
+// VariableSpec declares a template variable in template.yaml:
+//
+//	variables:
+//	  - name: log_level
+//	    type: enum
+//	    enum: [debug, info, warn, error]
+//	    default: info
+//	    description: Minimum level of log records
+//	  - name: trace_sample_rate
+//	    type: int
+//	    default: "10"
+//	    feature: opentelemetry
+//
+// Templates read the value as .Vars.<name>, typed according to type.
+type VariableSpec struct {
+	Name        string       `yaml:"name"`
+	Type        VariableType `yaml:"type"`
+	Description string       `yaml:"description,omitempty"`
+	Default     string       `yaml:"default,omitempty"`
+	Required    bool         `yaml:"required,omitempty"`
+	Pattern     string       `yaml:"pattern,omitempty"`
+	Enum        []string     `yaml:"enum,omitempty"`
+	Feature     string       `yaml:"feature,omitempty"`
+}
+
+// variableName is what a variable name must look like to be usable as .Vars.<name>
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Check validates the declaration itself
+func (v VariableSpec) Check() error {
+	if !variableName.MatchString(v.Name) {
+		return fmt.Errorf("invalid variable name %q (must be a letter or underscore followed by letters, digits or underscores)", v.Name)
+	}
+
+	switch v.Type {
+	case VariableString, VariableInt, VariableBool:
+		if len(v.Enum) > 0 {
+			return fmt.Errorf("variable %s: enum values need type enum", v.Name)
+		}
+	case VariableEnum:
+		if len(v.Enum) == 0 {
+			return fmt.Errorf("variable %s: type enum needs enum values", v.Name)
+		}
+	default:
+		return fmt.Errorf("variable %s: invalid type %q (must be one of: string, int, bool, enum)", v.Name, v.Type)
+	}
+
+	if v.Pattern != "" {
+		if v.Type != VariableString {
+			return fmt.Errorf("variable %s: pattern needs type string", v.Name)
+		}
+		if _, err := regexp.Compile(v.Pattern); err != nil {
+			return fmt.Errorf("variable %s: invalid pattern: %w", v.Name, err)
+		}
+	}
+
+	if v.Feature != "" {
+		if _, ok := (FeatureConfig{}).Enabled(v.Feature); !ok {
+			return fmt.Errorf("variable %s: unknown feature %q", v.Name, v.Feature)
+		}
+	}
+
+	if v.Default != "" {
+		if _, err := v.Parse(v.Default); err != nil {
+			return fmt.Errorf("invalid default: %w", err)
+		}
+	}
+	return nil
+}
+
+// Parse converts a supplied value to the variable's type and validates it
+func (v VariableSpec) Parse(value string) (interface{}, error) {
+	switch v.Type {
+	case VariableInt:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %q is not a whole number", v.Name, value)
+		}
+		return n, nil
+	case VariableBool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %q is not true or false", v.Name, value)
+		}
+		return b, nil
+	case VariableEnum:
+		for _, allowed := range v.Enum {
+			if value == allowed {
+				return value, nil
+			}
+		}
+		return nil, fmt.Errorf("variable %s: %q is not one of: %s", v.Name, value, strings.Join(v.Enum, ", "))
+	default:
+		if v.Pattern != "" && !regexp.MustCompile(v.Pattern).MatchString(value) {
+			return nil, fmt.Errorf("variable %s: %q does not match %s", v.Name, value, v.Pattern)
+		}
+		return value, nil
+	}
+}
+
+// Applies reports whether the variable is in use for a configuration,
+// which is when the feature it depends on, if any, is enabled
+func (v VariableSpec) Applies(cfg *ProjectConfig) bool {
+	if v.Feature == "" {
+		return true
+	}
+	enabled, _ := cfg.Features.Enabled(v.Feature)
+	return enabled
+}
+
+// Enabled reports whether the feature with the given YAML name, such as
+// server_timing, is enabled, and whether such a feature exists
+func (f FeatureConfig) Enabled(name string) (bool, bool) {
+	v := reflect.ValueOf(f)
+	for i := 0; i < v.NumField(); i++ {
+		tag := strings.Split(v.Type().Field(i).Tag.Get("yaml"), ",")[0]
+		if tag == name {
+			return v.Field(i).Bool(), true
+		}
+	}
+	return false, false
+}
+
+// ResolveVariables validates the variables supplied in cfg.Variables
+// against the declarations of the template and returns the typed values
+// of every variable that applies, defaults included. All problems are
+// reported together. Without declarations, supplied values are passed
+// through as strings.
+func (t *TemplateConfig) ResolveVariables(cfg *ProjectConfig) (map[string]interface{}, error) {
+	vars := make(map[string]interface{})
+	if t == nil || len(t.Variables) == 0 {
+		for name, value := range cfg.Variables {
+			vars[name] = value
+		}
+		return vars, nil
+	}
+
+	var errs []error
+	declared := make(map[string]bool)
+	for _, spec := range t.Variables {
+		declared[spec.Name] = true
+		if err := spec.Check(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		value, supplied := cfg.Variables[spec.Name]
+		if !spec.Applies(cfg) {
+			if supplied {
+				errs = append(errs, fmt.Errorf("variable %s requires the %s feature", spec.Name, spec.Feature))
+			}
+			continue
+		}
+		if !supplied {
+			if spec.Required && spec.Default == "" {
+				errs = append(errs, fmt.Errorf("variable %s is required: %s", spec.Name, spec.Description))
+				continue
+			}
+			value = spec.Default
+			if value == "" {
+				vars[spec.Name] = zeroValue(spec.Type)
+				continue
+			}
+		}
+
+		typed, err := spec.Parse(value)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		vars[spec.Name] = typed
+	}
+
+	var unknown []string
+	for name := range cfg.Variables {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		errs = append(errs, fmt.Errorf("unknown variable %s for the %s tier", name, t.Tier))
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return vars, nil
+}
+
+// WithVariables returns a copy of the template configuration with more
+// variable declarations; a declaration replaces the one of the same name
+func (t *TemplateConfig) WithVariables(specs []VariableSpec) *TemplateConfig {
+	merged := *t
+	merged.Variables = append([]VariableSpec(nil), t.Variables...)
+	for _, spec := range specs {
+		replaced := false
+		for i := range merged.Variables {
+			if merged.Variables[i].Name == spec.Name {
+				merged.Variables[i] = spec
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged.Variables = append(merged.Variables, spec)
+		}
+	}
+	return &merged
+}
+
+// zeroValue returns the value of a variable of the given type that was neither supplied nor defaulted
+func zeroValue(t VariableType) interface{} {
+	switch t {
+	case VariableInt:
+		return 0
+	case VariableBool:
+		return false
+	default:
+		return ""
+	}
+}
@@ -765,6 +765,20 @@ func (g *Generation) Cancel() error {
 	return nil
 }
 
+// ReportProgress records how far a running generation has got
+func (g *Generation) ReportProgress(message string, completed, total int) error {
+	if g.status != GenerationStatusRunning {
+		return fmt.Errorf("cannot report progress for generation with status %s", g.status)
+	}
+
+	event := NewGenerationProgressEvent(g.ID(), message)
+	event.Completed = completed
+	event.Total = total
+	g.AddDomainEvent(event)
+
+	return nil
+}
+
 // AddArtifact adds an artifact to the generation
 func (g *Generation) AddArtifact(artifact GenerationArtifact) {
 	g.artifacts = append(g.artifacts, artifact)
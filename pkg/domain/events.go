@@ -169,8 +169,8 @@ func NewProjectStatusChangedEvent(aggregateID, fromStatus, toStatus string) *Pro
 // GenerationStartedEvent is fired when a generation is started
 type GenerationStartedEvent struct {
 	BaseDomainEvent
-	ProjectID   string
-	RequestedBy string
+	ProjectID   string `json:"projectId"`
+	RequestedBy string `json:"requestedBy"`
 }
 
 // NewGenerationStartedEvent creates a new generation started event
@@ -185,7 +185,9 @@ func NewGenerationStartedEvent(aggregateID, projectID, requestedBy string) *Gene
 // GenerationProgressEvent is fired to report generation progress
 type GenerationProgressEvent struct {
 	BaseDomainEvent
-	Message string
+	Message   string `json:"message"`
+	Completed int    `json:"completed"`
+	Total     int    `json:"total"`
 }
 
 // NewGenerationProgressEvent creates a new generation progress event
@@ -199,7 +201,7 @@ func NewGenerationProgressEvent(aggregateID, message string) *GenerationProgress
 // GenerationCompletedEvent is fired when a generation completes successfully
 type GenerationCompletedEvent struct {
 	BaseDomainEvent
-	ArtifactCount int
+	ArtifactCount int `json:"artifactCount"`
 }
 
 // NewGenerationCompletedEvent creates a new generation completed event
@@ -213,8 +215,8 @@ func NewGenerationCompletedEvent(aggregateID string, artifactCount int) *Generat
 // GenerationFailedEvent is fired when a generation fails
 type GenerationFailedEvent struct {
 	BaseDomainEvent
-	ErrorCode    string
-	ErrorMessage string
+	ErrorCode    string `json:"errorCode"`
+	ErrorMessage string `json:"errorMessage"`
 }
 
 // NewGenerationFailedEvent creates a new generation failed event
@@ -241,8 +243,8 @@ func NewGenerationCancelledEvent(aggregateID string) *GenerationCancelledEvent {
 // GenerationArtifactCreatedEvent is fired when an artifact is created during generation
 type GenerationArtifactCreatedEvent struct {
 	BaseDomainEvent
-	ArtifactPath string
-	ArtifactSize int64
+	ArtifactPath string `json:"artifactPath"`
+	ArtifactSize int64  `json:"artifactSize"`
 }
 
 // NewGenerationArtifactCreatedEvent creates a new generation artifact created event
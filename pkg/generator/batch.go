@@ -0,0 +1,432 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/config"
+)
+
+// DefaultBatchConcurrency is how many projects of a batch generate at once
+const DefaultBatchConcurrency = 4
+
+// Batch is a set of projects generated together into one monorepo
+type Batch struct {
+	// OutputDir is the monorepo root; project output directories are relative to it
+	OutputDir string
+	Workspace WorkspaceConfig
+	Services  []*config.ProjectConfig
+}
+
+// WorkspaceConfig selects the files written at the root of a batch
+type WorkspaceConfig struct {
+	GoWork        bool `yaml:"go_work"`
+	Makefile      bool `yaml:"makefile"`
+	DockerCompose bool `yaml:"docker_compose"`
+}
+
+// batchFile is the YAML layout of a batch file. Defaults and services are
+// kept as nodes so every service can be decoded on top of its own copy of
+// the defaults.
+type batchFile struct {
+	OutputDir    string          `yaml:"output_dir"`
+	ModulePrefix string          `yaml:"module_prefix"`
+	Workspace    WorkspaceConfig `yaml:"workspace"`
+	Defaults     yaml.Node       `yaml:"defaults"`
+	Services     []yaml.Node     `yaml:"services"`
+}
+
+// LoadBatch reads a batch file:
+//
+//	output_dir: platform            # monorepo root (default: current directory)
+//	module_prefix: github.com/acme  # go_module default: <module_prefix>/<name>
+//	workspace: {go_work: true, makefile: true, docker_compose: true}
+//	defaults:                       # any ProjectConfig field
+//	  tier: intermediate
+//	services:
+//	  - name: orders
+//	  - name: billing
+//	    tier: advanced
+//
+// Each service is a ProjectConfig whose fields override the defaults, which
+// in turn override the defaults of the service's tier. A service's output
+// directory defaults to its name, below output_dir.
+func LoadBatch(filename string) (*Batch, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch file: %w", err)
+	}
+
+	batch, err := ParseBatch(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid batch file %s: %w", filename, err)
+	}
+	return batch, nil
+}
+
+// ParseBatch parses the contents of a batch file; see LoadBatch
+func ParseBatch(data []byte) (*Batch, error) {
+	var file batchFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse batch file: %w", err)
+	}
+	if len(file.Services) == 0 {
+		return nil, fmt.Errorf("no services listed")
+	}
+
+	batch := &Batch{
+		OutputDir: file.OutputDir,
+		Workspace: file.Workspace,
+	}
+	if batch.OutputDir == "" {
+		batch.OutputDir = "."
+	}
+	modulePrefix := strings.TrimSuffix(file.ModulePrefix, "/")
+	if modulePrefix == "" {
+		modulePrefix = "github.com/example"
+	}
+
+	var errs config.ConfigErrors
+	if !file.Defaults.IsZero() {
+		errs = append(errs, config.ValidateConfigNode(&file.Defaults, "defaults")...)
+	}
+	for i := range file.Services {
+		errs = append(errs, config.ValidateConfigNode(&file.Services[i], fmt.Sprintf("services[%d]", i))...)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	names := make(map[string]bool)
+	dirs := make(map[string]string)
+	var defaults []*config.Layer
+	if !file.Defaults.IsZero() {
+		defaults = append(defaults, config.NodeLayer("defaults", "", &file.Defaults))
+	}
+	for i := range file.Services {
+		// Resolving every service afresh gives it its own maps and slices
+		resolved, err := config.Resolve(append(defaults, config.NodeLayer("service", "", &file.Services[i]))...)
+		if err != nil {
+			return nil, fmt.Errorf("service %d: %w", i+1, err)
+		}
+		cfg := resolved.Config
+
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("service %d (line %d) has no name", i+1, file.Services[i].Line)
+		}
+		if names[cfg.Name] {
+			return nil, fmt.Errorf("service %s is listed twice", cfg.Name)
+		}
+		names[cfg.Name] = true
+
+		if cfg.GoModule == "" {
+			cfg.GoModule = modulePrefix + "/" + cfg.Name
+		}
+		if cfg.OutputDir == "" {
+			cfg.OutputDir = cfg.Name
+		}
+		if !filepath.IsAbs(cfg.OutputDir) {
+			cfg.OutputDir = filepath.Join(batch.OutputDir, cfg.OutputDir)
+		}
+		if other, ok := dirs[filepath.Clean(cfg.OutputDir)]; ok {
+			return nil, fmt.Errorf("services %s and %s share the output directory %s", other, cfg.Name, cfg.OutputDir)
+		}
+		dirs[filepath.Clean(cfg.OutputDir)] = cfg.Name
+
+		if err := cfg.Validate(); err != nil {
+			return nil, fmt.Errorf("service %s: %w", cfg.Name, err)
+		}
+		batch.Services = append(batch.Services, cfg)
+	}
+
+	return batch, nil
+}
+
+// BatchResult is the outcome of generating one project of a batch
+type BatchResult struct {
+	Config    *config.ProjectConfig
+	Summary   *GenerationSummary // per-file results; nil if generation failed early
+	Skipped   int                // files left untouched by an incremental run
+	Conflicts []string           // files merged with conflict markers
+	Elapsed   time.Duration
+	Err       error
+}
+
+// BatchSummary is the consolidated outcome of a batch, in batch file order
+type BatchSummary struct {
+	Results        []BatchResult
+	WorkspaceFiles []string // files written at the monorepo root
+	Elapsed        time.Duration
+}
+
+// Failures returns the results of the projects that failed
+func (s *BatchSummary) Failures() []BatchResult {
+	var failures []BatchResult
+	for _, result := range s.Results {
+		if result.Err != nil {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// Succeeded returns the configurations of the projects that generated
+func (s *BatchSummary) Succeeded() []*config.ProjectConfig {
+	var succeeded []*config.ProjectConfig
+	for _, result := range s.Results {
+		if result.Err == nil {
+			succeeded = append(succeeded, result.Config)
+		}
+	}
+	return succeeded
+}
+
+// BatchGenerator generates the projects of a batch concurrently. All
+// projects render from one template registry, so templates are parsed once
+// and identical files are rendered once through the shared template cache.
+type BatchGenerator struct {
+	registry    *TemplateRegistry
+	concurrency int
+	configure   func(*Generator)
+}
+
+// NewBatchGenerator creates a batch generator running at most concurrency
+// projects at a time; values below one use DefaultBatchConcurrency
+func NewBatchGenerator(registry *TemplateRegistry, concurrency int) *BatchGenerator {
+	if concurrency < 1 {
+		concurrency = DefaultBatchConcurrency
+	}
+	return &BatchGenerator{registry: registry, concurrency: concurrency}
+}
+
+// Configure sets a function applied to the generator of every project
+// before it runs, such as to set its conflict policy or add observers
+func (b *BatchGenerator) Configure(configure func(*Generator)) {
+	b.configure = configure
+}
+
+// Generate generates every project of the batch. A failed project does
+// not stop the others; failures are reported per project in the summary.
+// Workspace files are written afterwards for the projects that generated,
+// following the same conflict policy as the projects. The returned error
+// is only set when the workspace files could not be written or ctx was
+// cancelled.
+func (b *BatchGenerator) Generate(ctx context.Context, batch *Batch, policy ConflictPolicy) (*BatchSummary, error) {
+	start := time.Now()
+	summary := &BatchSummary{Results: make([]BatchResult, len(batch.Services))}
+
+	sem := make(chan struct{}, b.concurrency)
+	var wg sync.WaitGroup
+	for i, cfg := range batch.Services {
+		summary.Results[i].Config = cfg
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			summary.Results[i].Err = fmt.Errorf("not generated: %w", ctx.Err())
+			continue
+		}
+
+		wg.Add(1)
+		go func(result *BatchResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			b.generateProject(ctx, result, policy)
+		}(&summary.Results[i])
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		summary.Elapsed = time.Since(start)
+		return summary, fmt.Errorf("batch generation cancelled: %w", err)
+	}
+
+	written, err := WriteWorkspace(b.registry, batch.OutputDir, summary.Succeeded(), batch.Workspace, policy)
+	summary.WorkspaceFiles = written
+	summary.Elapsed = time.Since(start)
+	return summary, err
+}
+
+// generateProject generates one project of a batch into result
+func (b *BatchGenerator) generateProject(ctx context.Context, result *BatchResult, policy ConflictPolicy) {
+	start := time.Now()
+	defer func() { result.Elapsed = time.Since(start) }()
+
+	gen, err := NewWithRegistry(result.Config, b.registry)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to create generator: %w", err)
+		return
+	}
+	gen.SetConflictPolicy(policy)
+	if b.configure != nil {
+		b.configure(gen)
+	}
+
+	result.Err = gen.GenerateContext(ctx)
+	result.Summary = gen.Summary()
+	result.Skipped = gen.Skipped()
+	result.Conflicts = gen.Conflicts()
+}
+
+// WorkspaceContext is the monorepo of a batch that workspace files are rendered for
+type WorkspaceContext struct {
+	Services []WorkspaceService
+	Dirs     []string // project directories, sorted
+	Compose  bool     // the workspace has a docker-compose.yml
+}
+
+// WorkspaceService is a project of a workspace
+type WorkspaceService struct {
+	Name      string
+	Version   string
+	Dir       string // project directory relative to the workspace root, slash-separated
+	Port      int    // host port docker-compose.yml publishes the service on
+	DependsOn []WorkspaceDependency
+}
+
+// WorkspaceDependency is another service of the workspace a service depends on
+type WorkspaceDependency struct {
+	Name      string
+	EnvPrefix string // prefix of the variable holding its URL, such as BILLING for BILLING_URL
+}
+
+// workspaceFiles maps each workspace file to the template it is rendered from
+var workspaceFiles = []struct {
+	name     string
+	template string
+	enabled  func(WorkspaceConfig) bool
+}{
+	{"go.work", "workspace-go-work", func(w WorkspaceConfig) bool { return w.GoWork }},
+	{"Makefile", "workspace-makefile", func(w WorkspaceConfig) bool { return w.Makefile }},
+	{"docker-compose.yml", "workspace-compose", func(w WorkspaceConfig) bool { return w.DockerCompose }},
+}
+
+// WriteWorkspace renders the selected workspace files for services from
+// registry into root and returns the names of the files written. An
+// existing file with different content is replaced under the overwrite and
+// merge policies, kept under skip and refused under fail.
+func WriteWorkspace(registry *TemplateRegistry, root string, services []*config.ProjectConfig, workspace WorkspaceConfig, policy ConflictPolicy) ([]string, error) {
+	if len(services) == 0 {
+		return nil, nil
+	}
+
+	workspaceCtx, err := newWorkspaceContext(root, services, workspace)
+	if err != nil {
+		return nil, err
+	}
+	ctx := &GenerationContext{Version: GeneratorVersion, Workspace: workspaceCtx}
+
+	out := NewDirOutput(root)
+	var written []string
+	for _, file := range workspaceFiles {
+		if !file.enabled(workspace) {
+			continue
+		}
+		content, err := renderWorkspaceFile(registry, file.name, file.template, ctx)
+		if err != nil {
+			return written, err
+		}
+
+		existing, err := os.ReadFile(filepath.Join(root, file.name))
+		switch {
+		case err == nil && bytes.Equal(existing, content):
+			continue
+		case err == nil && policy == ConflictPolicySkip:
+			continue
+		case err == nil && policy == ConflictPolicyFail:
+			return written, fmt.Errorf("%w: %s already exists in %s", ErrOutputNotEmpty, file.name, root)
+		case err != nil && !errors.Is(err, os.ErrNotExist):
+			return written, fmt.Errorf("failed to read %s: %w", file.name, err)
+		}
+
+		if err := out.WriteFile(file.name, content, 0644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+		written = append(written, file.name)
+	}
+	return written, nil
+}
+
+// newWorkspaceContext describes the services of a workspace. Each service
+// listens on 8080 inside the compose network and is published on its own
+// host port. A service whose dependencies list the name of another service
+// depends on it.
+func newWorkspaceContext(root string, services []*config.ProjectConfig, workspace WorkspaceConfig) (*WorkspaceContext, error) {
+	names := make(map[string]bool, len(services))
+	for _, cfg := range services {
+		names[cfg.Name] = true
+	}
+
+	ctx := &WorkspaceContext{Compose: workspace.DockerCompose}
+	for i, cfg := range services {
+		rel, err := filepath.Rel(root, cfg.OutputDir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("service %s is outside the workspace root %s", cfg.Name, root)
+		}
+
+		service := WorkspaceService{
+			Name:    cfg.Name,
+			Version: cfg.Version,
+			Dir:     filepath.ToSlash(rel),
+			Port:    8080 + i,
+		}
+		for _, dep := range sortedCopy(cfg.Dependencies.ExternalServices) {
+			if names[dep] && dep != cfg.Name {
+				service.DependsOn = append(service.DependsOn, WorkspaceDependency{Name: dep, EnvPrefix: envName(dep)})
+			}
+		}
+		ctx.Services = append(ctx.Services, service)
+		ctx.Dirs = append(ctx.Dirs, service.Dir)
+	}
+	sort.Strings(ctx.Dirs)
+	return ctx, nil
+}
+
+// renderWorkspaceFile executes a workspace template and verifies the result
+func renderWorkspaceFile(registry *TemplateRegistry, filename, templateName string, ctx *GenerationContext) ([]byte, error) {
+	tmpl, ok := registry.Lookup(templateName)
+	if !ok {
+		return nil, fmt.Errorf("template not found: %s", templateName)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, ctx); err != nil {
+		return nil, fmt.Errorf("failed to execute template %s: %w", templateName, err)
+	}
+	content, err := FormatArtifact(filename, buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("template %s produced invalid output: %w", templateName, err)
+	}
+	return content, nil
+}
+
+// envName turns a service name into an environment variable prefix
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// sortedCopy returns a sorted copy of s
+func sortedCopy(s []string) []string {
+	sorted := append([]string(nil), s...)
+	sort.Strings(sorted)
+	return sorted
+}
@@ -0,0 +1,190 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/config"
+)
+
+func TestParseBatch(t *testing.T) {
+	batch, err := ParseBatch([]byte(`
+output_dir: platform
+module_prefix: github.com/acme/
+defaults:
+  tier: intermediate
+  kubernetes:
+    labels: {team: platform}
+services:
+  - name: orders
+    kubernetes:
+      labels: {owner: orders}
+  - name: billing
+    tier: advanced
+    go_module: example.com/billing
+`))
+	if err != nil {
+		t.Fatalf("ParseBatch() error = %v", err)
+	}
+	if len(batch.Services) != 2 {
+		t.Fatalf("Parsed %d services, want 2", len(batch.Services))
+	}
+
+	orders, billing := batch.Services[0], batch.Services[1]
+	if orders.Tier != config.TierIntermediate || billing.Tier != config.TierAdvanced {
+		t.Errorf("Tiers = %s, %s, want intermediate, advanced", orders.Tier, billing.Tier)
+	}
+	if orders.GoModule != "github.com/acme/orders" || billing.GoModule != "example.com/billing" {
+		t.Errorf("Modules = %s, %s", orders.GoModule, billing.GoModule)
+	}
+	if orders.OutputDir != filepath.Join("platform", "orders") {
+		t.Errorf("OutputDir = %s, want platform/orders", orders.OutputDir)
+	}
+	if len(orders.Kubernetes.Labels) != 2 || len(billing.Kubernetes.Labels) != 1 {
+		t.Errorf("Labels = %v, %v; defaults must be merged per service, not shared", orders.Kubernetes.Labels, billing.Kubernetes.Labels)
+	}
+
+	for name, data := range map[string]string{
+		"no services":    "defaults: {tier: basic}\n",
+		"duplicate name": "services: [{name: a}, {name: a}]\n",
+		"shared dir":     "services: [{name: a}, {name: b, output_dir: a}]\n",
+		"missing name":   "services: [{tier: basic}]\n",
+		"invalid tier":   "services: [{name: a, tier: huge}]\n",
+	} {
+		if _, err := ParseBatch([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParseBatch_SchemaErrors(t *testing.T) {
+	_, err := ParseBatch([]byte(`
+defaults:
+  kubernetes: {port: 0}
+services:
+  - name: orders
+    namespace: shop
+  - name: billing
+    kubernetes:
+      health_probes:
+        liveness_probe:
+          period_seconds: 5
+          timeout_seconds: 10
+`))
+	var errs config.ConfigErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("ParseBatch() error = %v, want ConfigErrors", err)
+	}
+
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Error())
+	}
+	want := []string{
+		"3:22: defaults.kubernetes.port: must be >= 1",
+		`6:5: services[0].namespace: unknown field "namespace"`,
+		"12:11: services[1].kubernetes.health_probes.liveness_probe.timeout_seconds: must be < period_seconds",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Errors =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestParseBatch_TierDefaults(t *testing.T) {
+	batch, err := ParseBatch([]byte(`
+defaults:
+  tier: enterprise
+  features: {compliance: false}
+services:
+  - name: orders
+  - name: billing
+    tier: basic
+    features: {opentelemetry: true}
+`))
+	if err != nil {
+		t.Fatalf("ParseBatch() error = %v", err)
+	}
+
+	orders, billing := batch.Services[0], batch.Services[1]
+	if !orders.Features.Security || orders.Features.Compliance {
+		t.Errorf("orders features = %+v, want enterprise defaults without compliance", orders.Features)
+	}
+	if !billing.Features.OpenTelemetry || billing.Features.Security || !billing.Kubernetes.HealthProbes.LivenessProbe.Enabled {
+		t.Errorf("billing features = %+v, want basic defaults with opentelemetry", billing.Features)
+	}
+}
+
+func TestBatchGenerator(t *testing.T) {
+	root := t.TempDir()
+	batch, err := ParseBatch([]byte(`
+workspace: {go_work: true, makefile: true, docker_compose: true}
+defaults: {tier: basic}
+services:
+  - name: orders
+    dependencies: {external_services: [billing]}
+  - name: billing
+  - name: taken
+`))
+	if err != nil {
+		t.Fatalf("ParseBatch() error = %v", err)
+	}
+	batch.OutputDir = root
+	for _, cfg := range batch.Services {
+		cfg.OutputDir = filepath.Join(root, cfg.Name)
+	}
+
+	// A service whose directory has files fails under the default policy
+	if err := os.MkdirAll(filepath.Join(root, "taken"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "taken", "keep.txt"), []byte("mine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	registry, err := NewTemplateRegistry()
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	summary, err := NewBatchGenerator(registry, 2).Generate(context.Background(), batch, ConflictPolicyFail)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	failures := summary.Failures()
+	if len(failures) != 1 || failures[0].Config.Name != "taken" || !errors.Is(failures[0].Err, ErrOutputNotEmpty) {
+		t.Fatalf("Failures() = %+v, want only taken", failures)
+	}
+	for _, name := range []string{"orders", "billing"} {
+		if _, err := os.Stat(filepath.Join(root, name, "go.mod")); err != nil {
+			t.Errorf("%s was not generated: %v", name, err)
+		}
+	}
+
+	goWork, err := os.ReadFile(filepath.Join(root, "go.work"))
+	if err != nil {
+		t.Fatalf("go.work was not written: %v", err)
+	}
+	if got := string(goWork); !strings.Contains(got, "./billing\n\t./orders\n)") || strings.Contains(got, "taken") {
+		t.Errorf("go.work = %q, want only the generated services", got)
+	}
+
+	compose, err := os.ReadFile(filepath.Join(root, "docker-compose.yml"))
+	if err != nil {
+		t.Fatalf("docker-compose.yml was not written: %v", err)
+	}
+	if !strings.Contains(string(compose), "BILLING_URL=http://billing:8080") || !strings.Contains(string(compose), "\"8081:8080\"") {
+		t.Errorf("docker-compose.yml does not wire orders to billing:\n%s", compose)
+	}
+
+	// Rewriting identical workspace files is not a conflict; changed ones are
+	if _, err := WriteWorkspace(registry, root, summary.Succeeded(), batch.Workspace, ConflictPolicyFail); err != nil {
+		t.Errorf("WriteWorkspace() with unchanged files error = %v", err)
+	}
+	if _, err := WriteWorkspace(registry, root, summary.Succeeded()[:1], batch.Workspace, ConflictPolicyFail); !errors.Is(err, ErrOutputNotEmpty) {
+		t.Errorf("WriteWorkspace() over changed files error = %v, want ErrOutputNotEmpty", err)
+	}
+}
@@ -1,145 +1,397 @@
 package generator
 
 import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"text/template"
 	"time"
 )
 
-// TemplateCache provides thread-safe caching for parsed templates
+const (
+	// DefaultCacheSize bounds the memory used by cached templates and renders
+	DefaultCacheSize = 32 << 20
+
+	// DefaultDiskCacheSize bounds the renders persisted in the cache directory
+	DefaultDiskCacheSize = 128 << 20
+)
+
+// TemplateCache is a size-bounded LRU cache of parsed templates and rendered
+// files. Entries are keyed by content hashes, so a changed template or
+// configuration never hits a stale entry and nothing has to expire. When a
+// directory is set, rendered files are also persisted there and survive
+// across runs. It is safe for concurrent use.
 type TemplateCache struct {
-	cache    map[string]*CachedTemplate
-	mu       sync.RWMutex
-	maxAge   time.Duration
-	hitCount int64
-	missCount int64
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // front is most recently used
+	bytes   int64
+	maxSize int64
+
+	dir         string
+	diskBytes   int64
+	maxDiskSize int64
+
+	parseHits    atomic.Int64
+	parseMisses  atomic.Int64
+	renderHits   atomic.Int64
+	renderMisses atomic.Int64
+	diskHits     atomic.Int64
+	evictions    atomic.Int64
+}
+
+// cacheEntry is a single cached value; exactly one of tmpl and data is set
+type cacheEntry struct {
+	key  string
+	tmpl *template.Template
+	data []byte
+	size int64
+}
+
+// CacheStats represents cache performance statistics
+type CacheStats struct {
+	Entries      int
+	Bytes        int64
+	ParseHits    int64
+	ParseMisses  int64
+	RenderHits   int64
+	RenderMisses int64
+	DiskHits     int64
+	Evictions    int64
 }
 
-// CachedTemplate represents a cached template with metadata
-type CachedTemplate struct {
-	Template  *template.Template
-	CreatedAt time.Time
-	AccessCount int64
-	LastAccess time.Time
+// HitRatio returns the share of lookups of either kind that hit the cache
+func (s CacheStats) HitRatio() float64 {
+	hits := s.ParseHits + s.RenderHits
+	total := hits + s.ParseMisses + s.RenderMisses
+	if total == 0 {
+		return 0
+	}
+	return float64(hits) / float64(total)
+}
+
+// sharedCache is the process-wide cache used by registries and generators
+var sharedCache = NewTemplateCache(DefaultCacheSize)
+
+// SharedTemplateCache returns the process-wide cache that every template
+// registry parses through and every generator renders through by default
+func SharedTemplateCache() *TemplateCache {
+	return sharedCache
 }
 
-// NewTemplateCache creates a new template cache
-func NewTemplateCache(maxAge time.Duration) *TemplateCache {
+// DefaultCacheDir returns the directory renders are persisted in,
+// <user cache dir>/template-health-endpoint
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "template-health-endpoint"), nil
+}
+
+// NewTemplateCache creates an in-memory cache holding at most maxSize bytes
+func NewTemplateCache(maxSize int64) *TemplateCache {
 	return &TemplateCache{
-		cache:   make(map[string]*CachedTemplate),
-		maxAge:  maxAge,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		maxSize: maxSize,
 	}
 }
 
-// Get retrieves a template from cache
-func (tc *TemplateCache) Get(key string) (*template.Template, bool) {
-	tc.mu.RLock()
-	defer tc.mu.RUnlock()
+// Persist stores rendered files below dir from now on, keeping at most
+// maxSize bytes there; the least recently used files are removed first
+func (tc *TemplateCache) Persist(dir string, maxSize int64) error {
+	renders := filepath.Join(dir, "renders")
+	if err := os.MkdirAll(renders, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory %s: %w", renders, err)
+	}
 
-	cached, exists := tc.cache[key]
-	if !exists {
-		tc.missCount++
-		return nil, false
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.dir = renders
+	tc.maxDiskSize = maxSize
+	return tc.pruneDiskLocked()
+}
+
+// Template returns the parsed template cached under key, calling parse and
+// caching its result on a miss. Parse errors are not cached.
+func (tc *TemplateCache) Template(key string, size int, parse func() (*template.Template, error)) (*template.Template, error) {
+	if entry, ok := tc.get(key); ok && entry.tmpl != nil {
+		tc.parseHits.Add(1)
+		return entry.tmpl, nil
 	}
+	tc.parseMisses.Add(1)
 
-	// Check if template has expired
-	if time.Since(cached.CreatedAt) > tc.maxAge {
-		tc.mu.RUnlock()
-		tc.mu.Lock()
-		delete(tc.cache, key)
-		tc.mu.Unlock()
-		tc.mu.RLock()
-		tc.missCount++
-		return nil, false
+	tmpl, err := parse()
+	if err != nil {
+		return nil, err
 	}
+	tc.put(&cacheEntry{key: key, tmpl: tmpl, size: int64(size)})
+	return tmpl, nil
+}
 
-	// Update access statistics
-	cached.AccessCount++
-	cached.LastAccess = time.Now()
-	tc.hitCount++
+// Rendered returns the file content cached under key, calling render and
+// caching its result on a miss. The returned slice must not be modified.
+func (tc *TemplateCache) Rendered(key string, render func() ([]byte, error)) ([]byte, error) {
+	if entry, ok := tc.get(key); ok && entry.data != nil {
+		tc.renderHits.Add(1)
+		return entry.data, nil
+	}
 
-	return cached.Template, true
+	if data, ok := tc.readDisk(key); ok {
+		tc.renderHits.Add(1)
+		tc.diskHits.Add(1)
+		tc.put(&cacheEntry{key: key, data: data, size: int64(len(data))})
+		return data, nil
+	}
+	tc.renderMisses.Add(1)
+
+	data, err := render()
+	if err != nil {
+		return nil, err
+	}
+	tc.put(&cacheEntry{key: key, data: data, size: int64(len(data))})
+	tc.writeDisk(key, data)
+	return data, nil
 }
 
-// Put stores a template in cache
-func (tc *TemplateCache) Put(key string, tmpl *template.Template) {
+// Stats returns cache statistics
+func (tc *TemplateCache) Stats() CacheStats {
 	tc.mu.Lock()
-	defer tc.mu.Unlock()
+	entries, bytes := tc.lru.Len(), tc.bytes
+	tc.mu.Unlock()
 
-	tc.cache[key] = &CachedTemplate{
-		Template:  tmpl,
-		CreatedAt: time.Now(),
-		AccessCount: 0,
-		LastAccess: time.Now(),
+	return CacheStats{
+		Entries:      entries,
+		Bytes:        bytes,
+		ParseHits:    tc.parseHits.Load(),
+		ParseMisses:  tc.parseMisses.Load(),
+		RenderHits:   tc.renderHits.Load(),
+		RenderMisses: tc.renderMisses.Load(),
+		DiskHits:     tc.diskHits.Load(),
+		Evictions:    tc.evictions.Load(),
 	}
 }
 
-// Clear removes all templates from cache
+// Clear removes all entries from memory and resets the statistics.
+// Persisted renders are kept.
 func (tc *TemplateCache) Clear() {
+	tc.mu.Lock()
+	tc.entries = make(map[string]*list.Element)
+	tc.lru.Init()
+	tc.bytes = 0
+	tc.mu.Unlock()
+
+	for _, counter := range []*atomic.Int64{&tc.parseHits, &tc.parseMisses, &tc.renderHits, &tc.renderMisses, &tc.diskHits, &tc.evictions} {
+		counter.Store(0)
+	}
+}
+
+// get returns the entry for key and marks it most recently used
+func (tc *TemplateCache) get(key string) (*cacheEntry, bool) {
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
 
-	tc.cache = make(map[string]*CachedTemplate)
-	tc.hitCount = 0
-	tc.missCount = 0
+	element, ok := tc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	tc.lru.MoveToFront(element)
+	return element.Value.(*cacheEntry), true
 }
 
-// Stats returns cache statistics
-func (tc *TemplateCache) Stats() CacheStats {
-	tc.mu.RLock()
-	defer tc.mu.RUnlock()
+// put adds an entry and evicts the least recently used ones beyond maxSize.
+// Entries larger than the whole cache are not stored.
+func (tc *TemplateCache) put(entry *cacheEntry) {
+	if entry.size > tc.maxSize {
+		return
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
 
-	total := tc.hitCount + tc.missCount
-	hitRatio := float64(0)
-	if total > 0 {
-		hitRatio = float64(tc.hitCount) / float64(total)
+	if element, ok := tc.entries[entry.key]; ok {
+		tc.bytes -= element.Value.(*cacheEntry).size
+		element.Value = entry
+		tc.lru.MoveToFront(element)
+	} else {
+		tc.entries[entry.key] = tc.lru.PushFront(entry)
 	}
+	tc.bytes += entry.size
 
-	return CacheStats{
-		Size:     len(tc.cache),
-		Hits:     tc.hitCount,
-		Misses:   tc.missCount,
-		HitRatio: hitRatio,
+	for tc.bytes > tc.maxSize {
+		oldest := tc.lru.Back()
+		evicted := tc.lru.Remove(oldest).(*cacheEntry)
+		delete(tc.entries, evicted.key)
+		tc.bytes -= evicted.size
+		tc.evictions.Add(1)
 	}
 }
 
-// CacheStats represents cache performance statistics
-type CacheStats struct {
-	Size     int
-	Hits     int64
-	Misses   int64
-	HitRatio float64
+// diskPath returns where a render is persisted
+func (tc *TemplateCache) diskPath(key string) string {
+	return filepath.Join(tc.dir, key[:2], key)
 }
 
-// CleanupExpired removes expired templates from cache
-func (tc *TemplateCache) CleanupExpired() int {
+// readDisk loads a persisted render and marks it recently used
+func (tc *TemplateCache) readDisk(key string) ([]byte, bool) {
 	tc.mu.Lock()
-	defer tc.mu.Unlock()
+	dir := tc.dir
+	tc.mu.Unlock()
+	if dir == "" || len(key) < 2 {
+		return nil, false
+	}
 
+	path := tc.diskPath(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
 	now := time.Now()
-	removedCount := 0
+	os.Chtimes(path, now, now)
+	return data, true
+}
+
+// writeDisk persists a render; failures only cost a future cache miss
+func (tc *TemplateCache) writeDisk(key string, data []byte) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.dir == "" || len(key) < 2 {
+		return
+	}
+
+	path := tc.diskPath(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+
+	// Write then rename, so concurrent runs never read a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+key+"-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+
+	tc.diskBytes += int64(len(data))
+	if tc.diskBytes > tc.maxDiskSize {
+		tc.pruneDiskLocked()
+	}
+}
+
+// pruneDiskLocked removes the least recently used persisted renders until
+// they fit in maxDiskSize. The caller must hold tc.mu.
+func (tc *TemplateCache) pruneDiskLocked() error {
+	type diskFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+
+	var files []diskFile
+	var total int64
+	err := filepath.WalkDir(tc.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		files = append(files, diskFile{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan cache directory %s: %w", tc.dir, err)
+	}
 
-	for key, cached := range tc.cache {
-		if now.Sub(cached.CreatedAt) > tc.maxAge {
-			delete(tc.cache, key)
-			removedCount++
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	for _, file := range files {
+		if total <= tc.maxDiskSize {
+			break
+		}
+		if err := os.Remove(file.path); err == nil {
+			total -= file.size
 		}
 	}
 
-	return removedCount
+	tc.diskBytes = total
+	return nil
+}
+
+// templateCacheKey identifies a parsed template by its name and source
+func templateCacheKey(name, source string) string {
+	return "tmpl:" + Checksum([]byte(name+"\x00"+source))
 }
 
-// StartCleanupWorker starts a background worker to clean up expired templates
-func (tc *TemplateCache) StartCleanupWorker(interval time.Duration, done <-chan struct{}) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+// renderCacheKey identifies a rendered file by everything its content
+// depends on: the generator build, the template and its content hash,
+// the file name and the hash of the context fields the template reads.
+// Templates are parsed on their own, so no other template affects a render.
+func renderCacheKey(templateName, templateHash, filename, inputsHash string) string {
+	h := sha256.New()
+	for _, part := range []string{buildFingerprint(), templateName, templateHash, filename, inputsHash} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
 
-	for {
-		select {
-		case <-ticker.C:
-			tc.CleanupExpired()
-		case <-done:
-			return
+// buildFingerprint identifies the generator build, so a build whose template
+// functions or formatting differ does not reuse renders persisted by another:
+// the VCS revision of a build from a clean tree, or else a hash of the
+// executable. GeneratorVersion is only bumped on releases.
+var buildFingerprint = sync.OnceValue(func() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		var revision, modified string
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				revision = setting.Value
+			case "vcs.modified":
+				modified = setting.Value
+			}
+		}
+		if revision != "" && modified == "false" {
+			return "vcs:" + revision
 		}
 	}
-}
\ No newline at end of file
+
+	if executable, err := os.Executable(); err == nil {
+		if file, err := os.Open(executable); err == nil {
+			defer file.Close()
+			h := sha256.New()
+			if _, err := io.Copy(h, file); err == nil {
+				return "exe:" + hex.EncodeToString(h.Sum(nil))
+			}
+		}
+	}
+
+	return "version:" + GeneratorVersion
+})
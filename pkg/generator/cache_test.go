@@ -0,0 +1,143 @@
+package generator
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/config"
+)
+
+func TestTemplateCache_LRUEviction(t *testing.T) {
+	cache := NewTemplateCache(10)
+	render := func(content string) func() ([]byte, error) {
+		return func() ([]byte, error) { return []byte(content), nil }
+	}
+
+	cache.Rendered("a", render("aaaa"))
+	cache.Rendered("b", render("bbbb"))
+	cache.Rendered("a", render("aaaa")) // a is now more recently used than b
+	cache.Rendered("c", render("cccc")) // evicts b
+
+	if _, ok := cache.get("b"); ok {
+		t.Error("Least recently used entry was not evicted")
+	}
+	if _, ok := cache.get("a"); !ok {
+		t.Error("Recently used entry was evicted")
+	}
+
+	stats := cache.Stats()
+	if stats.Bytes != 8 || stats.Entries != 2 || stats.Evictions != 1 {
+		t.Errorf("Stats() = %+v, want 2 entries, 8 bytes, 1 eviction", stats)
+	}
+	if stats.RenderHits != 1 || stats.RenderMisses != 3 {
+		t.Errorf("Stats() = %+v, want 1 hit and 3 misses", stats)
+	}
+}
+
+func TestTemplateCache_Persist(t *testing.T) {
+	dir := t.TempDir()
+	key := Checksum([]byte("key"))
+
+	first := NewTemplateCache(DefaultCacheSize)
+	if err := first.Persist(dir, DefaultDiskCacheSize); err != nil {
+		t.Fatalf("Persist() error = %v", err)
+	}
+	first.Rendered(key, func() ([]byte, error) { return []byte("rendered"), nil })
+
+	// A new cache, as in a later run, finds the render on disk
+	second := NewTemplateCache(DefaultCacheSize)
+	if err := second.Persist(dir, DefaultDiskCacheSize); err != nil {
+		t.Fatalf("Persist() error = %v", err)
+	}
+	data, err := second.Rendered(key, func() ([]byte, error) {
+		t.Error("Render called despite a persisted entry")
+		return nil, nil
+	})
+	if err != nil || string(data) != "rendered" {
+		t.Errorf("Rendered() = %q, %v, want %q", data, err, "rendered")
+	}
+	if stats := second.Stats(); stats.DiskHits != 1 {
+		t.Errorf("DiskHits = %d, want 1", stats.DiskHits)
+	}
+
+	// Shrinking the bound removes persisted renders
+	third := NewTemplateCache(DefaultCacheSize)
+	if err := third.Persist(dir, 1); err != nil {
+		t.Fatalf("Persist() error = %v", err)
+	}
+	if _, ok := third.readDisk(key); ok {
+		t.Error("Persisted render survived pruning")
+	}
+}
+
+func TestTemplateCache_Concurrent(t *testing.T) {
+	cache := NewTemplateCache(1 << 10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				key := fmt.Sprintf("%02d", (i+j)%50)
+				cache.Rendered(key, func() ([]byte, error) { return make([]byte, 32), nil })
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	stats := cache.Stats()
+	if stats.RenderHits+stats.RenderMisses != 8*200 {
+		t.Errorf("Recorded %d lookups, want %d", stats.RenderHits+stats.RenderMisses, 8*200)
+	}
+	if stats.Bytes > 1<<10 {
+		t.Errorf("Cache holds %d bytes, bound is %d", stats.Bytes, 1<<10)
+	}
+}
+
+func TestGenerator_RenderCacheKey(t *testing.T) {
+	cfg := &config.ProjectConfig{
+		Name:      "cache-test",
+		GoModule:  "github.com/example/cache-test",
+		Tier:      config.TierBasic,
+		Version:   "1.0.0",
+		OutputDir: "cache-test",
+	}
+	gen, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	cache := NewTemplateCache(DefaultCacheSize)
+	gen.SetCache(cache)
+
+	render := func(ctx *GenerationContext) {
+		t.Helper()
+		if _, err := gen.renderFile("go.mod", "go-mod", ctx); err != nil {
+			t.Fatalf("Failed to render go.mod: %v", err)
+		}
+	}
+
+	render(&GenerationContext{Config: cfg, Timestamp: "2024-01-01T00:00:00Z", Version: "1.0.0"})
+
+	// go.mod does not read the timestamp, so a later run reuses the render
+	render(&GenerationContext{Config: cfg, Timestamp: "2024-06-01T00:00:00Z", Version: "1.0.0"})
+	if stats := cache.Stats(); stats.RenderHits != 1 || stats.RenderMisses != 1 {
+		t.Errorf("Stats() = %+v, want 1 hit and 1 miss after a timestamp change", stats)
+	}
+
+	changed := *cfg
+	changed.GoModule = "github.com/example/renamed"
+	render(&GenerationContext{Config: &changed, Timestamp: "2024-06-01T00:00:00Z", Version: "1.0.0"})
+	if stats := cache.Stats(); stats.RenderMisses != 2 {
+		t.Errorf("Stats() = %+v, want a miss after a module change", stats)
+	}
+
+	// Another build of the generator does not reuse the render
+	defer func(fingerprint func() string) { buildFingerprint = fingerprint }(buildFingerprint)
+	buildFingerprint = func() string { return "exe:other" }
+	render(&GenerationContext{Config: &changed, Timestamp: "2024-06-01T00:00:00Z", Version: "1.0.0"})
+	if stats := cache.Stats(); stats.RenderMisses != 3 {
+		t.Errorf("Stats() = %+v, want a miss after a generator rebuild", stats)
+	}
+}
@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Drift is a generated file whose copy on disk differs from a fresh render
+type Drift struct {
+	Path    string
+	Missing bool   // the file is not on disk at all
+	Diff    string // unified diff from the file on disk to the rendered file
+}
+
+// Check renders the project into memory and compares every rendered file
+// with its copy in the configured output directory, returning the files
+// that were edited or removed, sorted by path. Files on disk the project
+// does not render are not reported. Without a pinned generation time, the
+// time recorded in the output directory's manifest is used, so an
+// untouched project checks clean whenever it was generated.
+func (g *Generator) Check(runCtx context.Context) ([]Drift, error) {
+	output, timestamp := g.output, g.timestamp
+	defer func() { g.output, g.timestamp = output, timestamp }()
+
+	if g.timestamp.IsZero() && os.Getenv(SourceDateEpochEnv) == "" {
+		if manifest, err := LoadManifest(g.config.OutputDir); err == nil {
+			if generatedAt, err := time.Parse(time.RFC3339, manifest.GeneratedAt); err == nil {
+				g.timestamp = generatedAt
+			}
+		}
+	}
+
+	out := NewMemoryOutput()
+	g.output = out
+	if err := g.GenerateContext(runCtx); err != nil {
+		return nil, err
+	}
+
+	var drifts []Drift
+	for _, name := range out.Files() {
+		rendered, _ := out.ReadFile(name)
+		onDisk, err := os.ReadFile(filepath.Join(g.config.OutputDir, filepath.FromSlash(name)))
+		missing := errors.Is(err, fs.ErrNotExist)
+		if err != nil && !missing {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if !missing && bytes.Equal(onDisk, rendered) {
+			continue
+		}
+
+		oldName := "disk/" + name
+		if missing {
+			oldName = "/dev/null"
+		}
+		drifts = append(drifts, Drift{
+			Path:    name,
+			Missing: missing,
+			Diff:    UnifiedDiff(oldName, "rendered/"+name, string(onDisk), string(rendered)),
+		})
+	}
+	return drifts, nil
+}
@@ -0,0 +1,216 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContextLines is the number of unchanged lines shown around each hunk
+const diffContextLines = 3
+
+// diffOpKind identifies the kind of a line-level edit
+type diffOpKind int
+
+const (
+	diffEqual diffOpKind = iota
+	diffDelete
+	diffInsert
+)
+
+// diffOp is a single line-level edit between two texts.
+// A and B are the line indexes in the old and new text; the index of the
+// side an operation does not touch is the position the edit applies at.
+type diffOp struct {
+	Kind diffOpKind
+	A    int
+	B    int
+}
+
+// splitLines splits text into lines, keeping line terminators so that
+// joining the result reproduces the input exactly
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes a shortest edit script between a and b using the
+// Myers O(ND) algorithm
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	limit := n + m
+	offset := limit + 1
+	v := make([]int, 2*limit+2)
+	var trace [][]int
+
+	for d := 0; d <= limit; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrackDiff(trace, offset, n, m)
+			}
+		}
+	}
+
+	return backtrackDiff(trace, offset, n, m)
+}
+
+// backtrackDiff walks the Myers trace backwards to recover the edit script
+func backtrackDiff(trace [][]int, offset, n, m int) []diffOp {
+	var ops []diffOp
+	x, y := n, m
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{Kind: diffEqual, A: x, B: y})
+		}
+
+		if d > 0 {
+			if x == prevX {
+				y--
+				ops = append(ops, diffOp{Kind: diffInsert, A: x, B: y})
+			} else {
+				x--
+				ops = append(ops, diffOp{Kind: diffDelete, A: x, B: y})
+			}
+		}
+	}
+
+	// Reverse into forward order
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+
+	return ops
+}
+
+// UnifiedDiff returns a unified diff between oldText and newText, or an
+// empty string when they are identical
+func UnifiedDiff(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+
+	a, b := splitLines(oldText), splitLines(newText)
+	ops := diffLines(a, b)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+
+	for _, hunk := range groupHunks(ops) {
+		writeHunk(&sb, hunk, a, b)
+	}
+
+	return sb.String()
+}
+
+// groupHunks splits an edit script into hunks of changes with surrounding context
+func groupHunks(ops []diffOp) [][]diffOp {
+	var hunks [][]diffOp
+	start := -1
+	lastChange := -1
+
+	for i, op := range ops {
+		if op.Kind == diffEqual {
+			continue
+		}
+		if start >= 0 && i-lastChange > 2*diffContextLines {
+			hunks = append(hunks, ops[start:min(lastChange+diffContextLines+1, len(ops))])
+			start = -1
+		}
+		if start < 0 {
+			start = max(i-diffContextLines, 0)
+		}
+		lastChange = i
+	}
+
+	if start >= 0 {
+		hunks = append(hunks, ops[start:min(lastChange+diffContextLines+1, len(ops))])
+	}
+
+	return hunks
+}
+
+// writeHunk writes a single unified diff hunk
+func writeHunk(sb *strings.Builder, hunk []diffOp, a, b []string) {
+	oldStart, newStart := hunk[0].A, hunk[0].B
+	oldCount, newCount := 0, 0
+	for _, op := range hunk {
+		switch op.Kind {
+		case diffEqual:
+			oldCount++
+			newCount++
+		case diffDelete:
+			oldCount++
+		case diffInsert:
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+
+	for _, op := range hunk {
+		switch op.Kind {
+		case diffEqual:
+			writeDiffLine(sb, ' ', a[op.A])
+		case diffDelete:
+			writeDiffLine(sb, '-', a[op.A])
+		case diffInsert:
+			writeDiffLine(sb, '+', b[op.B])
+		}
+	}
+}
+
+// hunkRange formats a hunk range in unified diff notation
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// writeDiffLine writes a prefixed diff line, marking a missing final newline
+func writeDiffLine(sb *strings.Builder, prefix byte, line string) {
+	sb.WriteByte(prefix)
+	sb.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		sb.WriteString("\n\\ No newline at end of file\n")
+	}
+}
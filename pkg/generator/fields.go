@@ -0,0 +1,335 @@
+package generator
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// contextType is the type templates are executed against
+var contextType = reflect.TypeOf(GenerationContext{})
+
+// fieldScope is what dot or a variable refers to while walking a template.
+// A known scope is a field path below GenerationContext ("" is the context
+// itself). An unknown scope is derived from values whose paths were already
+// recorded in full, such as a range element, so reads below it add nothing.
+type fieldScope struct {
+	path  string
+	known bool
+}
+
+// fieldWalker collects the GenerationContext field paths read by a template
+type fieldWalker struct {
+	registry *TemplateRegistry
+	fields   map[string]bool
+	visiting map[string]bool
+}
+
+// Fields returns the GenerationContext field paths that a registered
+// template reads, such as "Config.Kubernetes.Replicas". A path stands for
+// everything below it and "" for the whole context. Templates included with
+// {{template}} are followed. The analysis is static and errs on the side of
+// recording too much: a value passed to a function or ranged over is
+// recorded in full.
+func (r *TemplateRegistry) Fields(name string) []string {
+	r.fieldsMu.Lock()
+	defer r.fieldsMu.Unlock()
+
+	if fields, ok := r.fields[name]; ok {
+		return fields
+	}
+
+	w := &fieldWalker{registry: r, fields: make(map[string]bool), visiting: make(map[string]bool)}
+	if tmpl, ok := r.templates[name]; ok && tmpl.Tree != nil {
+		w.walkTemplate(tmpl.Tree, fieldScope{known: true})
+	}
+
+	fields := minimalPaths(w.fields)
+	r.fields[name] = fields
+	return fields
+}
+
+// walkTemplate walks a template tree with dot bound to scope
+func (w *fieldWalker) walkTemplate(tree *parse.Tree, dot fieldScope) {
+	key := tree.Name + "\x00" + dot.path
+	if w.visiting[key] {
+		return
+	}
+	w.visiting[key] = true
+	defer delete(w.visiting, key)
+
+	w.walk(tree.Root, dot, map[string]fieldScope{"$": dot})
+}
+
+// walk records the fields read by a node
+func (w *fieldWalker) walk(node parse.Node, dot fieldScope, vars map[string]fieldScope) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			w.walk(child, dot, vars)
+		}
+	case *parse.ActionNode:
+		// A value that is printed is recorded in full
+		if scope := w.pipe(n.Pipe, dot, vars); scope.known && len(n.Pipe.Decl) == 0 {
+			w.record(scope.path)
+		}
+	case *parse.IfNode:
+		inner := copyVars(vars)
+		w.condition(n.Pipe, dot, inner)
+		w.walk(n.List, dot, inner)
+		w.walk(n.ElseList, dot, copyVars(vars))
+	case *parse.WithNode:
+		inner := copyVars(vars)
+		scope := w.condition(n.Pipe, dot, inner)
+		w.walk(n.List, scope, inner)
+		w.walk(n.ElseList, dot, copyVars(vars))
+	case *parse.RangeNode:
+		// The ranged value is recorded in full; its elements are covered by it
+		inner := copyVars(vars)
+		if scope := w.pipe(n.Pipe, dot, inner); scope.known {
+			w.record(scope.path)
+		}
+		for _, v := range n.Pipe.Decl {
+			inner[v.Ident[0]] = fieldScope{}
+		}
+		w.walk(n.List, fieldScope{}, inner)
+		w.walk(n.ElseList, dot, copyVars(vars))
+	case *parse.TemplateNode:
+		scope := fieldScope{}
+		if n.Pipe != nil {
+			scope = w.pipe(n.Pipe, dot, vars)
+		}
+		tmpl, ok := w.registry.templates[n.Name]
+		if !ok {
+			// Defined inside the template being walked
+			for _, registered := range w.registry.templates {
+				if t := registered.Lookup(n.Name); t != nil {
+					tmpl = t
+					ok = true
+					break
+				}
+			}
+		}
+		switch {
+		case ok && tmpl.Tree != nil:
+			w.walkTemplate(tmpl.Tree, scope)
+		case scope.known:
+			w.record(scope.path)
+		}
+	}
+}
+
+// condition walks the pipeline of an if or with. Structs are always true,
+// so testing one does not depend on its content; anything else is recorded.
+func (w *fieldWalker) condition(pipe *parse.PipeNode, dot fieldScope, vars map[string]fieldScope) fieldScope {
+	scope := w.pipe(pipe, dot, vars)
+	if scope.known {
+		if t, ok := fieldType(scope.path); !ok || t.Kind() != reflect.Struct {
+			w.record(scope.path)
+		}
+	}
+	return scope
+}
+
+// pipe walks a pipeline and returns what its result refers to, binding any
+// declared variables to it. Only a pipeline that is a single field reference
+// has a known result, which the caller records as needed; the inputs of any
+// other pipeline are recorded in full.
+func (w *fieldWalker) pipe(pipe *parse.PipeNode, dot fieldScope, vars map[string]fieldScope) fieldScope {
+	if pipe == nil {
+		return fieldScope{}
+	}
+
+	var result fieldScope
+	if len(pipe.Cmds) == 1 && len(pipe.Cmds[0].Args) == 1 {
+		result = w.arg(pipe.Cmds[0].Args[0], dot, vars)
+	} else {
+		for _, cmd := range pipe.Cmds {
+			for _, arg := range cmd.Args {
+				if scope := w.arg(arg, dot, vars); scope.known {
+					w.record(scope.path)
+				}
+			}
+		}
+	}
+
+	for _, v := range pipe.Decl {
+		vars[v.Ident[0]] = result
+	}
+	return result
+}
+
+// arg returns what a command argument refers to, walking nested pipelines
+func (w *fieldWalker) arg(node parse.Node, dot fieldScope, vars map[string]fieldScope) fieldScope {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return extend(dot, n.Ident)
+	case *parse.VariableNode:
+		scope, ok := vars[n.Ident[0]]
+		if !ok {
+			return fieldScope{}
+		}
+		return extend(scope, n.Ident[1:])
+	case *parse.ChainNode:
+		if pipe, ok := n.Node.(*parse.PipeNode); ok {
+			return extend(w.pipe(pipe, dot, vars), n.Field)
+		}
+		return fieldScope{}
+	case *parse.PipeNode:
+		scope := w.pipe(n, dot, vars)
+		if scope.known {
+			w.record(scope.path)
+		}
+		return fieldScope{}
+	}
+	return fieldScope{}
+}
+
+// record adds a field path, cut back to the part that names struct fields or map keys
+func (w *fieldWalker) record(path string) {
+	w.fields[resolvablePath(path)] = true
+}
+
+// extend appends field names to a scope
+func extend(scope fieldScope, idents []string) fieldScope {
+	if !scope.known || len(idents) == 0 {
+		return scope
+	}
+	path := strings.Join(idents, ".")
+	if scope.path != "" {
+		path = scope.path + "." + path
+	}
+	return fieldScope{path: path, known: true}
+}
+
+// copyVars returns a copy of a variable scope for a nested block
+func copyVars(vars map[string]fieldScope) map[string]fieldScope {
+	inner := make(map[string]fieldScope, len(vars))
+	for name, scope := range vars {
+		inner[name] = scope
+	}
+	return inner
+}
+
+// fieldType returns the type of a field path below GenerationContext
+func fieldType(path string) (reflect.Type, bool) {
+	t := contextType
+	if path == "" {
+		return t, true
+	}
+	for _, name := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := t.FieldByName(name)
+			if !ok || !field.IsExported() {
+				return nil, false
+			}
+			t = field.Type
+		case reflect.Map:
+			t = t.Elem()
+		default:
+			return nil, false
+		}
+	}
+	return t, true
+}
+
+// resolvablePath cuts a path back to its longest prefix of fields and map
+// keys, dropping method calls and names the context does not have
+func resolvablePath(path string) string {
+	if path == "" {
+		return ""
+	}
+	parts := strings.Split(path, ".")
+	for i := len(parts); i > 0; i-- {
+		prefix := strings.Join(parts[:i], ".")
+		if _, ok := fieldType(prefix); ok {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// minimalPaths returns the sorted paths that are not covered by another path
+func minimalPaths(set map[string]bool) []string {
+	if set[""] {
+		return []string{""}
+	}
+
+	paths := make([]string, 0, len(set))
+	for path := range set {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var minimal []string
+	for _, path := range paths {
+		if n := len(minimal); n > 0 && strings.HasPrefix(path, minimal[n-1]+".") {
+			continue
+		}
+		minimal = append(minimal, path)
+	}
+	return minimal
+}
+
+// fieldValue returns the value at a field path below ctx
+func fieldValue(ctx *GenerationContext, path string) (interface{}, bool) {
+	v := reflect.ValueOf(ctx).Elem()
+	if path == "" {
+		return v.Interface(), true
+	}
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return nil, true
+			}
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			v = v.FieldByName(name)
+			if !v.IsValid() {
+				return nil, false
+			}
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return nil, false
+			}
+			v = v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			if !v.IsValid() {
+				return nil, true
+			}
+		default:
+			return nil, false
+		}
+	}
+	return v.Interface(), true
+}
+
+// InputsHash hashes the values of the given field paths in ctx, so a file
+// whose template and inputs hash are unchanged renders to the same content
+func InputsHash(ctx *GenerationContext, fields []string) string {
+	var b strings.Builder
+	for _, path := range fields {
+		value, _ := fieldValue(ctx, path)
+		data, err := json.Marshal(value)
+		if err != nil {
+			data = []byte(err.Error())
+		}
+		b.WriteString(path)
+		b.WriteByte('=')
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	return Checksum([]byte(b.String()))
+}
@@ -0,0 +1,44 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestTemplateRegistry_Fields(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     []string
+	}{
+		{"field", `{{.Config.Name}}`, []string{"Config.Name"}},
+		{"function argument", `{{lower .Config.Name}} {{.Version | upper}}`, []string{"Config.Name", "Version"}},
+		{"struct condition", `{{with .Config.Kubernetes}}{{.Namespace}}{{end}}`, []string{"Config.Kubernetes.Namespace"}},
+		{"bool condition", `{{if .Config.Features.Docker}}docker{{end}}`, []string{"Config.Features.Docker"}},
+		{"variable", `{{$k := .Config.Kubernetes}}{{$k.Namespace}}`, []string{"Config.Kubernetes.Namespace"}},
+		{"range", `{{range .Config.Dependencies.ExternalServices}}{{.}}{{end}}`, []string{"Config.Dependencies.ExternalServices"}},
+		{"map key", `{{.Config.Kubernetes.Labels.app}}`, []string{"Config.Kubernetes.Labels.app"}},
+		{"method", `{{.Config.Tier.Description}}`, []string{"Config.Tier"}},
+		{"printed struct", `{{toYaml .Config.Features}} {{.Config.Features.Docker}}`, []string{"Config.Features"}},
+		{"include", `{{template "helper" .Config}}`, []string{"Config.Name"}},
+		{"whole context", `{{toJson .}}`, []string{""}},
+	}
+
+	files := fstest.MapFS{"helper.tmpl": {Data: []byte(`{{.Name}}`)}}
+	for _, tt := range tests {
+		files[tt.name+templateExt] = &fstest.MapFile{Data: []byte(tt.template)}
+	}
+	registry, err := NewTemplateRegistryFromLayers(TemplateLayer{Name: "test", FS: files})
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := registry.Fields(tt.name); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fields() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
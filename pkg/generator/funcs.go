@@ -0,0 +1,430 @@
+package generator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateFuncs returns the function library available to every template
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// Case conversion
+		"lower":   strings.ToLower,
+		"upper":   strings.ToUpper,
+		"title":   Title,
+		"camel":   CamelCase,
+		"pascal":  PascalCase,
+		"snake":   SnakeCase,
+		"kebab":   KebabCase,
+		"dns1123": DNS1123Label,
+		"goIdent": GoIdentifier,
+
+		// Strings
+		"contains":     strings.Contains,
+		"hasPrefix":    strings.HasPrefix,
+		"hasSuffix":    strings.HasSuffix,
+		"replace":      func(from, to, s string) string { return strings.ReplaceAll(s, from, to) },
+		"trim":         strings.TrimSpace,
+		"quote":        quote,
+		"indent":       indent,
+		"nindent":      func(spaces int, s string) string { return "\n" + indent(spaces, s) },
+		"toYaml":       toYAML,
+		"toJson":       toJSON,
+		"toPrettyJson": toPrettyJSON,
+
+		// Defaults and validation
+		"default":  defaultValue,
+		"required": required,
+		"empty":    isEmpty,
+
+		// Maps
+		"keys": sortedKeys,
+
+		// Semantic versions
+		"semver":        ParseSemver,
+		"semverCompare": semverCompare,
+	}
+}
+
+// splitWords splits an identifier-like string into lower-case words. Words
+// are separated by any non-alphanumeric character and by case changes, so
+// "myHTTPServer", "my-http-server" and "My HTTP server" all split the same way.
+func splitWords(s string) []string {
+	var words []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(current) > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// Split "myServer" before S and "HTTPServer" before S
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+
+	return words
+}
+
+// upperFirst upper-cases the first letter of s
+func upperFirst(s string) string {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return s
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// Title upper-cases the first letter of every space-separated word
+func Title(s string) string {
+	words := strings.Split(s, " ")
+	for i, word := range words {
+		words[i] = upperFirst(word)
+	}
+	return strings.Join(words, " ")
+}
+
+// CamelCase converts s to camelCase
+func CamelCase(s string) string {
+	words := splitWords(s)
+	for i := 1; i < len(words); i++ {
+		words[i] = upperFirst(words[i])
+	}
+	return strings.Join(words, "")
+}
+
+// PascalCase converts s to PascalCase
+func PascalCase(s string) string {
+	words := splitWords(s)
+	for i := range words {
+		words[i] = upperFirst(words[i])
+	}
+	return strings.Join(words, "")
+}
+
+// SnakeCase converts s to snake_case
+func SnakeCase(s string) string {
+	return strings.Join(splitWords(s), "_")
+}
+
+// KebabCase converts s to kebab-case
+func KebabCase(s string) string {
+	return strings.Join(splitWords(s), "-")
+}
+
+// DNS1123Label converts s to a valid DNS-1123 label as used for Kubernetes
+// resource names: lower-case alphanumerics and '-', at most 63 characters,
+// starting and ending with an alphanumeric character
+func DNS1123Label(s string) string {
+	var sb strings.Builder
+	for _, r := range KebabCase(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			sb.WriteRune(r)
+		}
+	}
+
+	label := strings.Trim(sb.String(), "-")
+	if len(label) > 63 {
+		label = strings.TrimRight(label[:63], "-")
+	}
+	return label
+}
+
+// GoIdentifier converts s to a valid, unexported-style Go identifier.
+// Words are joined in camelCase, a leading digit is prefixed with an
+// underscore and Go keywords get a trailing underscore.
+func GoIdentifier(s string) string {
+	var sb strings.Builder
+	for _, r := range CamelCase(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+
+	ident := sb.String()
+	switch {
+	case ident == "":
+		return "_"
+	case unicode.IsDigit([]rune(ident)[0]):
+		ident = "_" + ident
+	case token.IsKeyword(ident):
+		ident += "_"
+	}
+	return ident
+}
+
+// quote returns v formatted as a double-quoted string literal
+func quote(v interface{}) string {
+	return strconv.Quote(fmt.Sprint(v))
+}
+
+// indent prefixes every non-empty line of s with the given number of spaces
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// toYAML encodes v as YAML without the trailing newline
+func toYAML(v interface{}) (string, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("toYaml: %w", err)
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// toJSON encodes v as compact JSON
+func toJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("toJson: %w", err)
+	}
+	return string(data), nil
+}
+
+// toPrettyJSON encodes v as JSON indented with two spaces
+func toPrettyJSON(v interface{}) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("toPrettyJson: %w", err)
+	}
+	return string(data), nil
+}
+
+// sortedKeys returns the keys of a map with string keys in sorted order,
+// so templates can walk a map the same way on every run:
+// {{ range keys .Config.Kubernetes.Labels }}
+func sortedKeys(m interface{}) ([]string, error) {
+	rv := reflect.ValueOf(m)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("keys: %T is not a map with string keys", m)
+	}
+	keys := make([]string, 0, rv.Len())
+	for _, key := range rv.MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// isEmpty reports whether v is nil or the zero value of its type, or an empty slice, map or string
+func isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return rv.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return rv.IsZero()
+	}
+}
+
+// defaultValue returns value, or def when value is empty. The argument
+// order allows piping: {{ .Config.Namespace | default "default" }}
+func defaultValue(def interface{}, value ...interface{}) interface{} {
+	if len(value) == 0 || isEmpty(value[0]) {
+		return def
+	}
+	return value[0]
+}
+
+// required returns value, or fails rendering with msg when value is empty
+func required(msg string, value interface{}) (interface{}, error) {
+	if isEmpty(value) {
+		return nil, errors.New(msg)
+	}
+	return value, nil
+}
+
+// Semver is a parsed semantic version
+type Semver struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	Metadata   string
+}
+
+// ParseSemver parses a semantic version such as "1.2.3", "v1.2" or "1.2.3-rc.1+build.5".
+// Missing minor and patch numbers default to zero.
+func ParseSemver(version string) (*Semver, error) {
+	v := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	var sv Semver
+
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v, sv.Metadata = v[:i], v[i+1:]
+	}
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		v, sv.Prerelease = v[:i], v[i+1:]
+	}
+
+	parts := strings.Split(v, ".")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("invalid semantic version %q", version)
+	}
+
+	numbers := []*int{&sv.Major, &sv.Minor, &sv.Patch}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid semantic version %q", version)
+		}
+		*numbers[i] = n
+	}
+
+	return &sv, nil
+}
+
+// String formats the version without a "v" prefix
+func (v *Semver) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Metadata != "" {
+		s += "+" + v.Metadata
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 if v is lower than, equal to or higher than other.
+// Pre-release versions sort before the release; build metadata is ignored.
+func (v *Semver) Compare(other *Semver) int {
+	for _, d := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if d != 0 {
+			if d < 0 {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case v.Prerelease == other.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case other.Prerelease == "":
+		return -1
+	default:
+		return comparePrerelease(v.Prerelease, other.Prerelease)
+	}
+}
+
+// comparePrerelease compares pre-release versions identifier by identifier:
+// numeric identifiers numerically and below alphanumeric ones, which compare
+// as strings; a version with fewer identifiers sorts first when all of its
+// identifiers are equal
+func comparePrerelease(a, b string) int {
+	x, y := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(x) && i < len(y); i++ {
+		m, errM := strconv.ParseUint(x[i], 10, 64)
+		n, errN := strconv.ParseUint(y[i], 10, 64)
+		switch {
+		case errM == nil && errN == nil:
+			if m != n {
+				if m < n {
+					return -1
+				}
+				return 1
+			}
+		case errM == nil:
+			return -1
+		case errN == nil:
+			return 1
+		case x[i] != y[i]:
+			if x[i] < y[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case len(x) < len(y):
+		return -1
+	case len(x) > len(y):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// semverCompare reports whether version satisfies constraint, which is a
+// version optionally prefixed with =, !=, >, >=, <, <=, ^ (same major) or ~ (same minor)
+func semverCompare(constraint, version string) (bool, error) {
+	constraint = strings.TrimSpace(constraint)
+
+	op := ""
+	for _, candidate := range []string{">=", "<=", "!=", "=", ">", "<", "^", "~"} {
+		if strings.HasPrefix(constraint, candidate) {
+			op = candidate
+			break
+		}
+	}
+
+	want, err := ParseSemver(strings.TrimPrefix(constraint, op))
+	if err != nil {
+		return false, err
+	}
+	have, err := ParseSemver(version)
+	if err != nil {
+		return false, err
+	}
+
+	cmp := have.Compare(want)
+	switch op {
+	case "", "=":
+		return cmp == 0, nil
+	case "!=":
+		return cmp != 0, nil
+	case ">":
+		return cmp > 0, nil
+	case ">=":
+		return cmp >= 0, nil
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case "^":
+		return cmp >= 0 && have.Major == want.Major, nil
+	default: // "~"
+		return cmp >= 0 && have.Major == want.Major && have.Minor == want.Minor, nil
+	}
+}
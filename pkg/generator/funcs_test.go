@@ -0,0 +1,152 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCaseConversions(t *testing.T) {
+	tests := []struct {
+		input   string
+		camel   string
+		pascal  string
+		snake   string
+		kebab   string
+		dns1123 string
+		goIdent string
+	}{
+		{"my-service", "myService", "MyService", "my_service", "my-service", "my-service", "myService"},
+		{"myHTTPServer", "myHttpServer", "MyHttpServer", "my_http_server", "my-http-server", "my-http-server", "myHttpServer"},
+		{"User Service v2", "userServiceV2", "UserServiceV2", "user_service_v2", "user-service-v2", "user-service-v2", "userServiceV2"},
+		{"2fa_gateway", "2faGateway", "2faGateway", "2fa_gateway", "2fa-gateway", "2fa-gateway", "_2faGateway"},
+		{"type", "type", "Type", "type", "type", "type", "type_"},
+		{"__", "", "", "", "", "", "_"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			check := func(name, got, want string) {
+				if got != want {
+					t.Errorf("%s(%q) = %q, want %q", name, tt.input, got, want)
+				}
+			}
+			check("CamelCase", CamelCase(tt.input), tt.camel)
+			check("PascalCase", PascalCase(tt.input), tt.pascal)
+			check("SnakeCase", SnakeCase(tt.input), tt.snake)
+			check("KebabCase", KebabCase(tt.input), tt.kebab)
+			check("DNS1123Label", DNS1123Label(tt.input), tt.dns1123)
+			check("GoIdentifier", GoIdentifier(tt.input), tt.goIdent)
+		})
+	}
+
+	if got := DNS1123Label(strings.Repeat("a", 62) + "-bc"); got != strings.Repeat("a", 62) {
+		t.Errorf("DNS1123Label did not truncate to 63 characters without a trailing dash: %q", got)
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	registry, err := NewTemplateRegistry()
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+
+	data := map[string]interface{}{
+		"Name":    "health-api",
+		"Empty":   "",
+		"Labels":  map[string]string{"app": "health-api", "tier": "basic"},
+		"Version": "1.4.2",
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{"title", `{{ title "hello world" }}`, "Hello World", false},
+		{"lower", `{{ lower "Hello" }}`, "hello", false},
+		{"contains", `{{ contains .Name "api" }}`, "true", false},
+		{"default used", `{{ .Empty | default "fallback" }}`, "fallback", false},
+		{"default missing key", `{{ .Missing | default "fallback" }}`, "fallback", false},
+		{"default skipped", `{{ .Name | default "fallback" }}`, "health-api", false},
+		{"required", `{{ required "name is required" .Name }}`, "health-api", false},
+		{"required missing", `{{ required "name is required" .Empty }}`, "", true},
+		{"quote", `{{ quote .Name }}`, `"health-api"`, false},
+		{"toYaml nindent", `labels:{{ toYaml .Labels | nindent 2 }}`, "labels:\n  app: health-api\n  tier: basic", false},
+		{"toJson", `{{ toJson .Labels }}`, `{"app":"health-api","tier":"basic"}`, false},
+		{"keys", `{{ range keys .Labels }}{{ . }}={{ index $.Labels . }} {{ end }}`, "app=health-api tier=basic ", false},
+		{"keys not a map", `{{ keys .Name }}`, "", true},
+		{"semver", `{{ (semver .Version).Minor }}`, "4", false},
+		{"semverCompare", `{{ semverCompare ">=1.4.0" .Version }} {{ semverCompare "^2.0.0" .Version }} {{ semverCompare "~1.4.0" .Version }}`, "true false true", false},
+		{"semverCompare prerelease", `{{ semverCompare "<1.0.0" "1.0.0-rc.1" }}`, "true", false},
+		{"semverCompare numeric prerelease", `{{ semverCompare "<1.0.0-rc.10" "1.0.0-rc.2" }} {{ semverCompare ">1.0.0-rc.2" "1.0.0-rc.10" }}`, "true true", false},
+		{"semverCompare longer prerelease", `{{ semverCompare "<1.0.0-alpha.1" "1.0.0-alpha" }} {{ semverCompare "<1.0.0-alpha" "1.0.0-alpha.1" }}`, "true false", false},
+		{"semverCompare numeric below alphanumeric", `{{ semverCompare "<1.0.0-alpha.beta" "1.0.0-alpha.1" }} {{ semverCompare "<1.0.0-beta" "1.0.0-alpha.beta" }}`, "true true", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := registry.Parse(tt.name, tt.template)
+			if err != nil {
+				t.Fatalf("Failed to parse template: %v", err)
+			}
+
+			var buf bytes.Buffer
+			err = tmpl.Execute(&buf, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && buf.String() != tt.want {
+				t.Errorf("Execute() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateFuncs_HealthTemplatesParse(t *testing.T) {
+	registry, err := NewTemplateRegistry()
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+
+	files, err := filepath.Glob("../../template-health/templates/*.tmpl")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("No templates found: %v", err)
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file, err)
+		}
+		if _, err := registry.Parse(filepath.Base(file), string(content)); err != nil {
+			t.Errorf("Failed to parse %s: %v", file, err)
+		}
+	}
+}
+
+func TestSemver_Compare(t *testing.T) {
+	// Each version is lower than the next, as in SemVer 2.0 §11
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2",
+		"1.0.0-beta.11", "1.0.0-rc.1", "1.0.0-rc.2", "1.0.0-rc.10", "1.0.0", "1.0.1",
+	}
+	for i, a := range ordered {
+		for j, b := range ordered {
+			va, _ := ParseSemver(a)
+			vb, _ := ParseSemver(b)
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if got := va.Compare(vb); got != want {
+				t.Errorf("%s.Compare(%s) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+}
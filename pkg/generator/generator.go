@@ -1,16 +1,26 @@
 package generator
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
-	"text/template"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/LarsArtmann/BMAD-METHOD/pkg/config"
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/domain"
 )
 
+// GeneratorVersion is the version of the generator recorded in generated projects
+const GeneratorVersion = "1.0.0"
+
 // Generator handles the generation of health endpoint projects
 type Generator struct {
 	config           *config.ProjectConfig
@@ -19,12 +29,21 @@ type Generator struct {
 	parallelGen      *ParallelGenerator
 	enableParallel   bool
 	enableCaching    bool
-}
-
-// TemplateRegistry manages all template files and functions
-type TemplateRegistry struct {
-	templates map[string]*template.Template
-	functions template.FuncMap
+	manifest         *Manifest
+	manifestMu       sync.Mutex
+	onConflict       ConflictPolicy
+	output           OutputFS // where Generate writes; nil writes to OutputDir through a staging directory
+	out              OutputFS // output of the current run
+	observers        []Observer
+	generation       *domain.Generation // generation aggregate of the current run
+	eventMu          sync.Mutex
+	incremental      bool
+	previous         *Manifest // manifest of the generation being updated, if any
+	skipped          int       // files left untouched by an incremental run
+	conflicts        []string  // files merged with conflict markers by the last run
+	summary          *GenerationSummary
+	timestamp        time.Time // fixed generation time; zero uses the current time
+	tierConfig       *config.TemplateConfig
 }
 
 // GenerationContext provides context for template execution
@@ -32,22 +51,57 @@ type GenerationContext struct {
 	Config    *config.ProjectConfig
 	Timestamp string
 	Version   string
+
+	// Vars holds the tier's template variables, typed as declared in its template.yaml
+	Vars map[string]interface{}
+
+	// Environment is the environment a per-environment file is rendered for;
+	// nil for every other file
+	Environment *EnvironmentContext
+
+	// Workspace is the monorepo a workspace file of a batch is rendered for;
+	// nil for every other file
+	Workspace *WorkspaceContext
 }
 
-// New creates a new generator instance
-func New(cfg *config.ProjectConfig) (*Generator, error) {
-	if err := cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
+// EnvironmentContext is a deployment environment and its overrides
+type EnvironmentContext struct {
+	Name   string
+	Config config.EnvironmentConfig
+}
+
+// ForEnvironment returns a copy of the context for rendering the files of
+// the named environment of the configuration; "" returns the context itself
+func (c *GenerationContext) ForEnvironment(name string) *GenerationContext {
+	if name == "" {
+		return c
 	}
+	envCtx := *c
+	envCtx.Environment = &EnvironmentContext{Name: name, Config: c.Config.Environments[name]}
+	return &envCtx
+}
 
+// New creates a new generator instance
+func New(cfg *config.ProjectConfig) (*Generator, error) {
 	// Create template registry
 	registry, err := NewTemplateRegistry()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create template registry: %w", err)
 	}
 
-	// Create template cache (1 hour max age)
-	cache := NewTemplateCache(1 * time.Hour)
+	return NewWithRegistry(cfg, registry)
+}
+
+// NewWithRegistry creates a new generator instance that renders from the given template registry
+func NewWithRegistry(cfg *config.ProjectConfig, registry *TemplateRegistry) (*Generator, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	tierConfig, err := BuiltinTierConfig(cfg.Tier)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create parallel generator with optimal worker count
 	parallelGen := NewParallelGenerator(GetOptimalWorkerCount())
@@ -55,88 +109,369 @@ func New(cfg *config.ProjectConfig) (*Generator, error) {
 	return &Generator{
 		config:         cfg,
 		templates:      registry,
-		cache:          cache,
+		cache:          SharedTemplateCache(),
 		parallelGen:    parallelGen,
 		enableParallel: true,  // Enable by default
 		enableCaching:  true,  // Enable by default
+		onConflict:     ConflictPolicyFail,
+		tierConfig:     tierConfig,
 	}, nil
 }
 
+// Config returns the configuration the generator renders
+func (g *Generator) Config() *config.ProjectConfig {
+	return g.config
+}
+
+// SetCache replaces the cache renders are looked up in; nil disables caching
+func (g *Generator) SetCache(cache *TemplateCache) {
+	g.cache = cache
+	g.enableCaching = cache != nil
+}
+
+// SetIncremental makes Generate re-render only files whose template or
+// template inputs changed since the generation recorded in the output
+// directory's manifest. Unchanged files are left untouched, so their
+// modification times stay stable. Custom outputs always get every file.
+func (g *Generator) SetIncremental(incremental bool) {
+	g.incremental = incremental
+}
+
+// Skipped returns the number of unchanged files the last incremental run left alone
+func (g *Generator) Skipped() int {
+	g.manifestMu.Lock()
+	defer g.manifestMu.Unlock()
+	return g.skipped
+}
+
+// Conflicts returns the files the last merge run left with conflict markers to resolve
+func (g *Generator) Conflicts() []string {
+	return g.conflicts
+}
+
+// SetTaskTimeout sets how long a single file may take to render and write
+// in parallel mode; zero or less disables the deadline
+func (g *Generator) SetTaskTimeout(timeout time.Duration) {
+	g.parallelGen.SetTaskTimeout(timeout)
+}
+
+// Summary returns the per-file results of the last parallel run, or nil
+func (g *Generator) Summary() *GenerationSummary {
+	return g.summary
+}
+
+// SetTimestamp fixes the generation time recorded in generated files and
+// the manifest, so repeated runs render identical output; the zero time
+// falls back to SOURCE_DATE_EPOCH, then the current time
+func (g *Generator) SetTimestamp(timestamp time.Time) {
+	g.timestamp = timestamp
+}
+
+// SourceDateEpochEnv pins the generation time of every run to a number of
+// seconds since the Unix epoch, see https://reproducible-builds.org/specs/source-date-epoch/
+const SourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+// GenerationTime returns the time the next run records, in UTC: the time
+// set with SetTimestamp, else SOURCE_DATE_EPOCH, else the current time
+func (g *Generator) GenerationTime() (time.Time, error) {
+	if !g.timestamp.IsZero() {
+		return g.timestamp.UTC(), nil
+	}
+	if epoch := os.Getenv(SourceDateEpochEnv); epoch != "" {
+		timestamp, err := ParseTimestamp(epoch)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s: %w", SourceDateEpochEnv, err)
+		}
+		return timestamp, nil
+	}
+	return time.Now().UTC(), nil
+}
+
+// ParseTimestamp parses a generation time given in seconds since the Unix
+// epoch, like SOURCE_DATE_EPOCH, or in RFC 3339 format
+func ParseTimestamp(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither seconds since the Unix epoch nor an RFC 3339 time", value)
+	}
+	return timestamp.UTC(), nil
+}
+
+// SetTemplateConfig sets the tier's template configuration, whose variable
+// declarations the configured variables are validated and typed against;
+// the default is the built-in declaration of the configured tier
+func (g *Generator) SetTemplateConfig(tierConfig *config.TemplateConfig) {
+	g.tierConfig = tierConfig
+}
+
+// SetConflictPolicy sets how Generate treats an output directory that already contains files
+func (g *Generator) SetConflictPolicy(policy ConflictPolicy) {
+	g.onConflict = policy
+}
+
+// SetOutput makes Generate write to out instead of the configured output
+// directory. The conflict policy does not apply to custom outputs.
+func (g *Generator) SetOutput(out OutputFS) {
+	g.output = out
+}
+
 // Generate generates the complete health endpoint project
 func (g *Generator) Generate() error {
+	return g.GenerateContext(context.Background())
+}
+
+// GenerateContext generates the complete health endpoint project. Without
+// a custom output, files are rendered into a staging directory and moved
+// into the output directory once every file rendered; on error or
+// cancellation of runCtx nothing in the output directory is changed.
+// Observers are notified as the run progresses.
+func (g *Generator) GenerateContext(runCtx context.Context) error {
+	g.startRun()
+	err := g.generate(runCtx)
+	g.finishRun(err)
+	return err
+}
+
+// generate runs one generation into the configured output
+func (g *Generator) generate(runCtx context.Context) error {
+	g.previous = nil
+	g.skipped = 0
+	g.conflicts = nil
+	g.summary = nil
+
+	if g.output != nil {
+		g.out = g.output
+		return g.generateInto(runCtx)
+	}
+
+	if !g.onConflict.IsValid() {
+		return fmt.Errorf("invalid conflict policy '%s'", g.onConflict)
+	}
+
+	empty, err := isEmptyDir(g.config.OutputDir)
+	if err != nil {
+		return fmt.Errorf("failed to inspect output directory: %w", err)
+	}
+	// The manifest of an earlier generation is the merge base for existing
+	// files and tells an incremental run what is unchanged
+	policy := g.onConflict
+	if !empty && (g.incremental || policy == ConflictPolicyMerge) {
+		g.previous, _ = LoadManifest(g.config.OutputDir)
+	}
+
+	// Updating a generated project in place is what an incremental run is for
+	if g.incremental && g.previous != nil && policy == ConflictPolicyFail {
+		policy = ConflictPolicyOverwrite
+	}
+
+	if !empty && policy == ConflictPolicyFail {
+		return fmt.Errorf("%w: %s", ErrOutputNotEmpty, g.config.OutputDir)
+	}
+
+	staging, err := createStagingDir(g.config.OutputDir, "staging")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	g.out = NewDirOutput(staging)
+	if err := g.generateInto(runCtx); err != nil {
+		return err
+	}
+
+	// Last chance to abort before anything outside the staging directory changes
+	if err := runCtx.Err(); err != nil {
+		return fmt.Errorf("generation cancelled: %w", err)
+	}
+
+	count := g.artifactCount()
+	g.reportProgress(fmt.Sprintf("Moving files into %s", g.config.OutputDir), count, count)
+
+	return g.commitTransaction(staging, g.config.OutputDir, policy, g.previous)
+}
+
+// generateInto writes every project file, the manifest and the template snapshots to g.out
+func (g *Generator) generateInto(runCtx context.Context) error {
+	vars, err := g.tierConfig.ResolveVariables(g.config)
+	if err != nil {
+		return fmt.Errorf("invalid template variables: %w", err)
+	}
+
 	// Create output directory
 	if err := g.createOutputDirectory(); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	// Create generation context
+	timestamp, err := g.GenerationTime()
+	if err != nil {
+		return err
+	}
 	ctx := &GenerationContext{
 		Config:    g.config,
-		Timestamp: time.Now().Format(time.RFC3339),
-		Version:   "1.0.0",
+		Timestamp: timestamp.Format(time.RFC3339),
+		Version:   GeneratorVersion,
+		Vars:      vars,
 	}
 
-	if g.enableParallel {
-		return g.generateParallel(ctx)
+	// Keep the original timestamp so it does not count as a changed input
+	if g.incremental && g.previous != nil {
+		ctx.Timestamp = g.previous.GeneratedAt
 	}
 
-	return g.generateSequential(ctx)
-}
+	g.manifest = NewManifest(g.config, ctx.Timestamp)
+	g.manifest.Vars = vars
 
-// generateParallel generates files using parallel processing
-func (g *Generator) generateParallel(ctx *GenerationContext) error {
-	// Collect all generation tasks
-	tasks := g.collectGenerationTasks(ctx)
+	total := len(g.collectGenerationTasks(ctx))
+	g.reportProgress("Rendering project files", 0, total)
 
-	// Use context with timeout for parallel generation
-	genCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
+	if g.enableParallel {
+		err = g.generateParallel(runCtx, ctx)
+	} else {
+		err = g.generateSequential(runCtx, ctx)
+	}
+	if err != nil {
+		return err
+	}
 
-	// Generate files in parallel
-	summary, err := g.parallelGen.GenerateFiles(genCtx, tasks)
+	// Record how the project was generated
+	g.reportProgress("Writing generation manifest", g.artifactCount(), total)
+	data, err := g.manifest.Encode()
 	if err != nil {
-		return fmt.Errorf("parallel generation failed: %w", err)
+		return err
+	}
+	if err := g.out.WriteFile(ManifestFileName, data, 0644); err != nil {
+		return fmt.Errorf("failed to write generation manifest: %w", err)
 	}
 
-	// Check for failures
-	if summary.FailureCount > 0 {
-		return fmt.Errorf("failed to generate %d out of %d files", summary.FailureCount, summary.TotalFiles)
+	// Keep the template sources so later updates can re-render this version
+	if err := g.saveSnapshots(); err != nil {
+		return fmt.Errorf("failed to write template snapshots: %w", err)
 	}
 
-	fmt.Printf("✅ Generated %d files in %v (parallel mode)\n", summary.SuccessCount, summary.TotalDuration)
 	return nil
 }
 
-// generateSequential generates files sequentially (fallback)
-func (g *Generator) generateSequential(ctx *GenerationContext) error {
-	// Generate core files
-	if err := g.generateCoreFiles(ctx); err != nil {
-		return fmt.Errorf("failed to generate core files: %w", err)
-	}
+// RenderedFile is a project file rendered into memory
+type RenderedFile struct {
+	Path         string
+	Template     string
+	TemplateHash string
+	Content      []byte
+
+	// Environment is the environment the file was rendered for, if any
+	Environment string
+
+	// Fields and InputsHash are recorded in the manifest for incremental regeneration
+	Fields     []string
+	InputsHash string
+}
+
+// Render renders every project file into memory without writing anything
+func (g *Generator) Render(ctx *GenerationContext) ([]RenderedFile, error) {
+	tasks := g.collectGenerationTasks(ctx)
+
+	files := make([]RenderedFile, 0, len(tasks))
+	for _, task := range tasks {
+		content, err := g.renderFile(task.Filename, task.TemplateName, task.Context)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", task.Filename, err)
+		}
 
-	// Generate Go source files
-	if err := g.generateGoFiles(ctx); err != nil {
-		return fmt.Errorf("failed to generate Go files: %w", err)
+		templateHash, _ := g.templates.Hash(task.TemplateName)
+		fields := g.templates.Fields(task.TemplateName)
+		files = append(files, RenderedFile{
+			Path:         filepath.ToSlash(task.Filename),
+			Template:     task.TemplateName,
+			TemplateHash: templateHash,
+			Content:      content,
+			Environment:  environmentName(task.Context),
+			Fields:       fields,
+			InputsHash:   InputsHash(task.Context, fields),
+		})
 	}
 
-	// Generate TypeScript files (if enabled)
-	if g.config.Features.TypeScript {
-		if err := g.generateTypeScriptFiles(ctx); err != nil {
-			return fmt.Errorf("failed to generate TypeScript files: %w", err)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	return files, nil
+}
+
+// saveSnapshots stores the source of every template used by the current run
+func (g *Generator) saveSnapshots() error {
+	saved := make(map[string]bool)
+	for _, file := range g.manifest.Files {
+		if file.TemplateHash == "" || saved[file.TemplateHash] {
+			continue
 		}
+		source, _ := g.templates.Source(file.Template)
+		if err := g.out.WriteFile(SnapshotPath(file.TemplateHash), []byte(source), 0644); err != nil {
+			return err
+		}
+		saved[file.TemplateHash] = true
 	}
+	return nil
+}
 
-	// Generate Kubernetes files (if enabled)
-	if g.config.Features.Kubernetes {
-		if err := g.generateKubernetesFiles(ctx); err != nil {
-			return fmt.Errorf("failed to generate Kubernetes files: %w", err)
+// Manifest returns the manifest of the last generation run
+func (g *Generator) Manifest() *Manifest {
+	return g.manifest
+}
+
+// generateParallel generates files using parallel processing
+func (g *Generator) generateParallel(runCtx context.Context, ctx *GenerationContext) error {
+	// Collect all generation tasks
+	tasks := g.collectGenerationTasks(ctx)
+
+	// Generate files in parallel; each task has its own deadline
+	summary, err := g.parallelGen.GenerateFiles(runCtx, tasks)
+	g.summary = summary
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("generation cancelled: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("parallel generation failed: %w", err)
+	}
+
+	// Check for failures
+	if summary.FailureCount > 0 {
+		var errs []error
+		for _, failure := range summary.Failures() {
+			errs = append(errs, fmt.Errorf("%s: %w", failure.Filename, failure.Error))
 		}
+		return fmt.Errorf("failed to generate %d out of %d files:\n%w", summary.FailureCount, summary.TotalFiles, errors.Join(errs...))
 	}
+	return nil
+}
 
-	// Generate Docker files (if enabled)
-	if g.config.Features.Docker {
-		if err := g.generateDockerFiles(ctx); err != nil {
-			return fmt.Errorf("failed to generate Docker files: %w", err)
+// generateSequential generates files sequentially (fallback)
+func (g *Generator) generateSequential(runCtx context.Context, ctx *GenerationContext) error {
+	stages := []struct {
+		name    string
+		enabled bool
+		run     func(*GenerationContext) error
+	}{
+		{"core", true, g.generateCoreFiles},
+		{"Go", true, g.generateGoFiles},
+		{"TypeScript", g.config.Features.TypeScript, g.generateTypeScriptFiles},
+		{"Kubernetes", g.config.Features.Kubernetes, g.generateKubernetesFiles},
+		{"Docker", g.config.Features.Docker, g.generateDockerFiles},
+		{"environment", len(g.config.Environments) > 0, g.generateEnvironmentFiles},
+	}
+
+	for _, stage := range stages {
+		if !stage.enabled {
+			continue
+		}
+		if err := runCtx.Err(); err != nil {
+			return fmt.Errorf("generation cancelled: %w", err)
+		}
+		if err := stage.run(ctx); err != nil {
+			return fmt.Errorf("failed to generate %s files: %w", stage.name, err)
 		}
 	}
 
@@ -276,61 +611,67 @@ func (g *Generator) collectGenerationTasks(ctx *GenerationContext) []GenerationT
 		}
 	}
 
+	tasks = append(tasks, g.environmentTasks(ctx)...)
+
+	// Go maps iterate in random order; a stable order keeps runs identical
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].Filename < tasks[j].Filename
+	})
+
 	return tasks
 }
 
 // createOutputDirectory creates the output directory structure
 func (g *Generator) createOutputDirectory() error {
 	dirs := []string{
-		g.config.OutputDir,
-		filepath.Join(g.config.OutputDir, "cmd", "server"),
-		filepath.Join(g.config.OutputDir, "internal", "handlers"),
-		filepath.Join(g.config.OutputDir, "internal", "models"),
-		filepath.Join(g.config.OutputDir, "internal", "server"),
-		filepath.Join(g.config.OutputDir, "internal", "config"),
-		filepath.Join(g.config.OutputDir, "docs"),
-		filepath.Join(g.config.OutputDir, "scripts"),
+		path.Join("cmd", "server"),
+		path.Join("internal", "handlers"),
+		path.Join("internal", "models"),
+		path.Join("internal", "server"),
+		path.Join("internal", "config"),
+		path.Join("docs"),
+		path.Join("scripts"),
 	}
 
 	if g.config.Features.TypeScript {
 		dirs = append(dirs,
-			filepath.Join(g.config.OutputDir, "client", "typescript", "src"),
+			path.Join("client", "typescript", "src"),
 		)
 	}
 
 	if g.config.Features.OpenTelemetry {
 		dirs = append(dirs,
-			filepath.Join(g.config.OutputDir, "internal", "observability"),
+			path.Join("internal", "observability"),
 		)
 	}
 
 	if g.config.Features.CloudEvents {
 		dirs = append(dirs,
-			filepath.Join(g.config.OutputDir, "internal", "events"),
+			path.Join("internal", "events"),
 		)
 	}
 
 	if g.config.Features.Security {
 		dirs = append(dirs,
-			filepath.Join(g.config.OutputDir, "internal", "security"),
+			path.Join("internal", "security"),
 		)
 	}
 
 	if g.config.Features.Compliance {
 		dirs = append(dirs,
-			filepath.Join(g.config.OutputDir, "internal", "compliance"),
+			path.Join("internal", "compliance"),
 		)
 	}
 
 	if g.config.Features.Kubernetes {
 		dirs = append(dirs,
-			filepath.Join(g.config.OutputDir, "deployments", "kubernetes"),
+			path.Join("deployments", "kubernetes"),
 		)
 	}
 
 	for _, dir := range dirs {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		if err := g.out.MkdirAll(dir, 0755); err != nil {
+			return err
 		}
 	}
 
@@ -469,1745 +810,230 @@ func (g *Generator) generateDockerFiles(ctx *GenerationContext) error {
 	return nil
 }
 
-// generateFile generates a single file from a template
-func (g *Generator) generateFile(filename, templateName string, ctx *GenerationContext) error {
-	tmpl, exists := g.templates.templates[templateName]
-	if !exists {
-		return fmt.Errorf("template not found: %s", templateName)
+// environmentTasks returns the files rendered for the environments of the
+// configuration: configs/<env>.yaml and, with Kubernetes, an overlay per
+// environment on top of a kustomization of the base manifests
+func (g *Generator) environmentTasks(ctx *GenerationContext) []GenerationTask {
+	if len(g.config.Environments) == 0 {
+		return nil
 	}
 
-	// Create full file path
-	fullPath := filepath.Join(g.config.OutputDir, filename)
-
-	// Create directory if it doesn't exist
-	dir := filepath.Dir(fullPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	var tasks []GenerationTask
+	if g.config.Features.Kubernetes {
+		tasks = append(tasks, GenerationTask{
+			Filename:     "deployments/kubernetes/kustomization.yaml",
+			TemplateName: "k8s-kustomization",
+			Context:      ctx,
+			Generator:    g,
+		})
 	}
 
-	// Create file
-	file, err := os.Create(fullPath)
-	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", fullPath, err)
-	}
-	defer file.Close()
+	for _, name := range g.config.EnvironmentNames() {
+		envCtx := ctx.ForEnvironment(name)
+
+		files := map[string]string{
+			path.Join("configs", name+".yaml"): "env-config",
+		}
+		if g.config.Features.Kubernetes {
+			overlay := path.Join("deployments", "kubernetes", "overlays", name)
+			files[path.Join(overlay, "kustomization.yaml")] = "k8s-overlay-kustomization"
+			files[path.Join(overlay, "deployment-patch.yaml")] = "k8s-overlay-deployment-patch"
+			files[path.Join(overlay, "configmap-patch.yaml")] = "k8s-overlay-configmap-patch"
+		}
 
-	// Execute template
-	if err := tmpl.Execute(file, ctx); err != nil {
-		return fmt.Errorf("failed to execute template %s: %w", templateName, err)
+		for filename, templateName := range files {
+			tasks = append(tasks, GenerationTask{
+				Filename:     filename,
+				TemplateName: templateName,
+				Context:      envCtx,
+				Generator:    g,
+			})
+		}
 	}
 
-	return nil
+	return tasks
 }
 
-// NewTemplateRegistry creates a new template registry with all templates
-func NewTemplateRegistry() (*TemplateRegistry, error) {
-	registry := &TemplateRegistry{
-		templates: make(map[string]*template.Template),
-		functions: template.FuncMap{
-			"title":     func(s string) string { return fmt.Sprintf("%s%s", string(s[0]-32), s[1:]) },
-			"lower":     func(s string) string { return fmt.Sprintf("%s%s", string(s[0]+32), s[1:]) },
-			"contains":  func(s, substr string) bool { return fmt.Sprintf("%s", s) != fmt.Sprintf("%s", substr) },
-		},
-	}
-
-	// Register all templates
-	if err := registry.registerTemplates(); err != nil {
-		return nil, fmt.Errorf("failed to register templates: %w", err)
+// generateEnvironmentFiles generates the per-environment configs and Kubernetes overlays
+func (g *Generator) generateEnvironmentFiles(ctx *GenerationContext) error {
+	for _, task := range g.environmentTasks(ctx) {
+		if err := g.generateFile(task.Filename, task.TemplateName, task.Context); err != nil {
+			return fmt.Errorf("failed to generate %s: %w", task.Filename, err)
+		}
 	}
 
-	return registry, nil
+	return nil
 }
 
-// registerTemplates registers all template definitions
-func (r *TemplateRegistry) registerTemplates() error {
-	templates := map[string]string{
-		"readme": `# {{.Config.Name}}
-
-{{.Config.Description}}
-
-## Features
-
-- Health endpoint with comprehensive status reporting
-- ServerTime API with multiple timestamp formats
-{{- if .Config.Features.OpenTelemetry}}
-- OpenTelemetry integration for observability
-{{- end}}
-{{- if .Config.Features.CloudEvents}}
-- CloudEvents support for event-driven monitoring
-{{- end}}
-{{- if .Config.Features.Kubernetes}}
-- Kubernetes-ready with health probes and ServiceMonitor
-{{- end}}
-
-## Quick Start
-
-1. Install dependencies:
-   ` + "```bash" + `
-   go mod tidy
-   ` + "```" + `
-
-2. Run the server:
-   ` + "```bash" + `
-   go run cmd/server/main.go
-   ` + "```" + `
-
-3. Test the health endpoint:
-   ` + "```bash" + `
-   curl http://localhost:8080/health
-   ` + "```" + `
-
-## API Endpoints
-
-- ` + "`GET /health`" + ` - Basic health check
-- ` + "`GET /health/time`" + ` - Server time information
-- ` + "`GET /health/ready`" + ` - Readiness probe
-- ` + "`GET /health/live`" + ` - Liveness probe
-- ` + "`GET /health/startup`" + ` - Startup probe
-
-## Generated by
-
-Template Health Endpoint Generator v{{.Version}}
-Generated at: {{.Timestamp}}
-`,
-
-		"go-mod": `module {{.Config.GoModule}}
-
-go 1.21
-
-require (
-	github.com/gorilla/mux v1.8.1
-{{- if .Config.Features.OpenTelemetry}}
-	go.opentelemetry.io/otel v1.21.0
-	go.opentelemetry.io/otel/trace v1.21.0
-	go.opentelemetry.io/otel/metric v1.21.0
-{{- end}}
-{{- if .Config.Features.CloudEvents}}
-	github.com/cloudevents/sdk-go/v2 v2.14.0
-{{- end}}
-)
-`,
-
-		"gitignore": `# Binaries
-*.exe
-*.exe~
-*.dll
-*.so
-*.dylib
-/{{.Config.Name}}
-
-# Test binary, built with go test -c
-*.test
-
-# Output of the go coverage tool
-*.out
-
-# Go workspace file
-go.work
-
-# IDE files
-.vscode/
-.idea/
-*.swp
-*.swo
-
-# OS files
-.DS_Store
-Thumbs.db
-
-# Logs
-*.log
-
-# Environment files
-.env
-.env.local
-
-# Build artifacts
-/dist/
-/build/
-/bin/
-
-# Node modules (for TypeScript client)
-node_modules/
-npm-debug.log*
-yarn-debug.log*
-yarn-error.log*
-
-# TypeScript build output
-*.tsbuildinfo
-/client/typescript/dist/
-`,
-
-		"go-main": `package main
-
-import (
-	"context"
-	"fmt"
-	"log"
-	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
-	"time"
+// generateFile generates a single file from a template
+func (g *Generator) generateFile(filename, templateName string, ctx *GenerationContext) error {
+	_, err := g.generateFileContext(context.Background(), filename, templateName, ctx)
+	return err
+}
 
-	"{{.Config.GoModule}}/internal/config"
-	"{{.Config.GoModule}}/internal/server"
-)
+// writtenFile describes a generated project file
+type writtenFile struct {
+	Content  []byte
+	Checksum string
+	Skipped  bool // left as generated last time by an incremental run
+}
 
-func main() {
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+// generateFileContext renders, writes and records a single file. Rendering
+// is abandoned when taskCtx ends, and nothing is written after that.
+func (g *Generator) generateFileContext(taskCtx context.Context, filename, templateName string, ctx *GenerationContext) (writtenFile, error) {
+	if entry, ok := g.unchanged(filename, templateName, ctx); ok {
+		return writtenFile{Checksum: entry.SHA256, Skipped: true}, nil
 	}
 
-	// Create server
-	srv, err := server.New(cfg)
+	content, err := renderContext(taskCtx, func() ([]byte, error) {
+		return g.renderFile(filename, templateName, ctx)
+	})
 	if err != nil {
-		log.Fatalf("Failed to create server: %v", err)
+		return writtenFile{}, err
 	}
-
-	// Start server
-	go func() {
-		fmt.Printf("🚀 Starting {{.Config.Name}} server on :%d\n", cfg.Port)
-		if err := srv.Start(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed to start: %v", err)
-		}
-	}()
-
-	// Wait for interrupt signal
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	fmt.Println("🛑 Shutting down server...")
-
-	// Graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+	if err := taskCtx.Err(); err != nil {
+		return writtenFile{}, err
 	}
 
-	fmt.Println("✅ Server exited")
-}
-`,
-
-		"go-config": `package config
+	// Write file
+	if err := g.out.WriteFile(filepath.ToSlash(filename), content, 0644); err != nil {
+		return writtenFile{}, err
+	}
+	g.reportArtifact(filepath.ToSlash(filename), content)
 
-import (
-	"os"
-	"strconv"
-)
+	g.recordFile(filename, templateName, content, ctx)
 
-// Config holds the application configuration
-type Config struct {
-	Port    int    ` + "`json:\"port\" yaml:\"port\"`" + `
-	Version string ` + "`json:\"version\" yaml:\"version\"`" + `
-	Name    string ` + "`json:\"name\" yaml:\"name\"`" + `
+	return writtenFile{Content: content, Checksum: Checksum(content)}, nil
 }
 
-// Load loads configuration from environment variables
-func Load() (*Config, error) {
-	cfg := &Config{
-		Port:    8080,
-		Version: "{{.Config.Version}}",
-		Name:    "{{.Config.Name}}",
+// renderContext runs render, giving up when ctx ends. Template execution
+// cannot be interrupted, so an abandoned render finishes in the background
+// and its result is dropped.
+func renderContext(ctx context.Context, render func() ([]byte, error)) ([]byte, error) {
+	if ctx.Done() == nil {
+		return render()
 	}
 
-	// Override with environment variables
-	if port := os.Getenv("PORT"); port != "" {
-		if p, err := strconv.Atoi(port); err == nil {
-			cfg.Port = p
-		}
+	type rendered struct {
+		content []byte
+		err     error
 	}
+	done := make(chan rendered, 1)
+	go func() {
+		content, err := render()
+		done <- rendered{content, err}
+	}()
 
-	if version := os.Getenv("VERSION"); version != "" {
-		cfg.Version = version
+	select {
+	case r := <-done:
+		return r.content, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
-
-	return cfg, nil
 }
-`,
-
-		"go-server": `package server
 
-import (
-	"context"
-	"fmt"
-	"net/http"
-	"time"
+// renderFile renders the template for a project file and verifies the result,
+// so invalid Go, YAML or JSON fails generation instead of reaching the user.
+// Verified results are cached by the hash of everything they depend on.
+func (g *Generator) renderFile(filename, templateName string, ctx *GenerationContext) ([]byte, error) {
+	render := func() ([]byte, error) {
+		content, err := g.renderTemplate(templateName, ctx)
+		if err != nil {
+			return nil, err
+		}
 
-	"github.com/gorilla/mux"
+		content, err = FormatArtifact(filepath.ToSlash(filename), content)
+		if err != nil {
+			return nil, fmt.Errorf("template %s produced invalid output: %w", templateName, err)
+		}
 
-	"{{.Config.GoModule}}/internal/config"
-	"{{.Config.GoModule}}/internal/handlers"
-)
+		return content, nil
+	}
 
-// Server represents the HTTP server
-type Server struct {
-	config  *config.Config
-	server  *http.Server
-	handler *handlers.HealthHandler
+	if !g.enableCaching || g.cache == nil {
+		return render()
+	}
+	templateHash, _ := g.templates.Hash(templateName)
+	inputsHash := InputsHash(ctx, g.templates.Fields(templateName))
+	return g.cache.Rendered(renderCacheKey(templateName, templateHash, filepath.ToSlash(filename), inputsHash), render)
 }
 
-// New creates a new server instance
-func New(cfg *config.Config) (*Server, error) {
-	// Create health handler
-	healthHandler := handlers.NewHealthHandler(cfg)
-
-	// Create router
-	router := mux.NewRouter()
-
-	// Health endpoints
-	health := router.PathPrefix("/health").Subrouter()
-	health.HandleFunc("", healthHandler.CheckHealth).Methods("GET")
-	health.HandleFunc("/", healthHandler.CheckHealth).Methods("GET")
-	health.HandleFunc("/time", healthHandler.ServerTime).Methods("GET")
-	health.HandleFunc("/ready", healthHandler.ReadinessCheck).Methods("GET")
-	health.HandleFunc("/live", healthHandler.LivenessCheck).Methods("GET")
-	health.HandleFunc("/startup", healthHandler.StartupCheck).Methods("GET")
-
-	// Create HTTP server
-	srv := &http.Server{
-		Addr:         fmt.Sprintf(":%d", cfg.Port),
-		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
-	}
-
-	return &Server{
-		config:  cfg,
-		server:  srv,
-		handler: healthHandler,
-	}, nil
-}
+// renderTemplate executes a registered template into memory
+func (g *Generator) renderTemplate(templateName string, ctx *GenerationContext) ([]byte, error) {
+	tmpl, exists := g.templates.Lookup(templateName)
+	if !exists {
+		return nil, fmt.Errorf("template not found: %s", templateName)
+	}
 
-// Start starts the HTTP server
-func (s *Server) Start() error {
-	return s.server.ListenAndServe()
-}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, ctx); err != nil {
+		return nil, fmt.Errorf("failed to execute template %s: %w", templateName, err)
+	}
 
-// Shutdown gracefully shuts down the server
-func (s *Server) Shutdown(ctx context.Context) error {
-	return s.server.Shutdown(ctx)
+	return buf.Bytes(), nil
 }
-`,
-
-		"go-health-handler": `package handlers
-
-import (
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"time"
-
-	"{{.Config.GoModule}}/internal/config"
-	"{{.Config.GoModule}}/internal/models"
-)
 
-// HealthHandler handles health-related HTTP requests
-type HealthHandler struct {
-	config    *config.Config
-	startTime time.Time
-}
+// unchanged reports whether an incremental run can keep a project file as
+// generated last time: same generator version, template and template
+// inputs, and the file is still there. Kept files are carried over into the
+// new manifest.
+func (g *Generator) unchanged(filename, templateName string, ctx *GenerationContext) (ManifestFile, bool) {
+	if !g.incremental || g.previous == nil || g.previous.GeneratorVersion != GeneratorVersion {
+		return ManifestFile{}, false
+	}
 
-// NewHealthHandler creates a new health handler
-func NewHealthHandler(cfg *config.Config) *HealthHandler {
-	return &HealthHandler{
-		config:    cfg,
-		startTime: time.Now(),
+	path := filepath.ToSlash(filename)
+	entry, ok := g.previous.File(path)
+	if !ok || entry.InputsHash == "" {
+		return ManifestFile{}, false
 	}
-}
 
-// CheckHealth handles GET /health requests
-func (h *HealthHandler) CheckHealth(w http.ResponseWriter, r *http.Request) {
-	h.setJSONContentType(w)
+	templateHash, _ := g.templates.Hash(templateName)
+	fields := g.templates.Fields(templateName)
+	if entry.Template != templateName || entry.TemplateHash != templateHash ||
+		!slices.Equal(entry.Fields, fields) ||
+		entry.InputsHash != InputsHash(ctx, fields) {
+		return ManifestFile{}, false
+	}
 
-	uptime := time.Since(h.startTime)
-	status := models.HealthReport{
-		Status:      "healthy",
-		Timestamp:   time.Now(),
-		Version:     h.config.Version,
-		Uptime:      uptime,
-		UptimeHuman: h.formatUptime(uptime),
+	if _, err := os.Stat(filepath.Join(g.config.OutputDir, filename)); err != nil {
+		return ManifestFile{}, false
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(status)
+	g.manifestMu.Lock()
+	defer g.manifestMu.Unlock()
+	g.manifest.Record(entry)
+	g.skipped++
+	return entry, true
 }
 
-// ServerTime handles GET /health/time requests
-func (h *HealthHandler) ServerTime(w http.ResponseWriter, r *http.Request) {
-	h.setJSONContentType(w)
-
-	now := time.Now()
-	location := now.Location()
-
-	serverTime := models.ServerTime{
-		Timestamp:   now,
-		Timezone:    location.String(),
-		Unix:        now.Unix(),
-		UnixMilli:   now.UnixMilli(),
-		ISO8601:     now.Format(time.RFC3339),
-		Formatted:   now.Format("Monday, January 2, 2006 at 3:04:05 PM MST"),
+// recordFile adds a generated file to the manifest of the current run
+func (g *Generator) recordFile(filename, templateName string, content []byte, ctx *GenerationContext) {
+	if g.manifest == nil {
+		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(serverTime)
-}
-
-// ReadinessCheck handles GET /health/ready requests
-func (h *HealthHandler) ReadinessCheck(w http.ResponseWriter, r *http.Request) {
-	h.setJSONContentType(w)
+	templateHash, _ := g.templates.Hash(templateName)
+	fields := g.templates.Fields(templateName)
 
-	// For basic tier, readiness is same as health
-	uptime := time.Since(h.startTime)
-	status := models.HealthReport{
-		Status:      "healthy",
-		Timestamp:   time.Now(),
-		Version:     h.config.Version,
-		Uptime:      uptime,
-		UptimeHuman: h.formatUptime(uptime),
-	}
+	g.manifestMu.Lock()
+	defer g.manifestMu.Unlock()
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(status)
+	g.manifest.Record(ManifestFile{
+		Path:         filepath.ToSlash(filename),
+		Template:     templateName,
+		TemplateHash: templateHash,
+		SHA256:       Checksum(content),
+		Environment:  environmentName(ctx),
+		Fields:       fields,
+		InputsHash:   InputsHash(ctx, fields),
+	})
 }
 
-// LivenessCheck handles GET /health/live requests
-func (h *HealthHandler) LivenessCheck(w http.ResponseWriter, r *http.Request) {
-	h.setJSONContentType(w)
-
-	// For basic tier, liveness is same as health
-	uptime := time.Since(h.startTime)
-	status := models.HealthReport{
-		Status:      "healthy",
-		Timestamp:   time.Now(),
-		Version:     h.config.Version,
-		Uptime:      uptime,
-		UptimeHuman: h.formatUptime(uptime),
-	}
-
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(status)
-}
-
-// StartupCheck handles GET /health/startup requests
-func (h *HealthHandler) StartupCheck(w http.ResponseWriter, r *http.Request) {
-	h.setJSONContentType(w)
-
-	// For basic tier, startup is same as health
-	uptime := time.Since(h.startTime)
-	status := models.HealthReport{
-		Status:      "healthy",
-		Timestamp:   time.Now(),
-		Version:     h.config.Version,
-		Uptime:      uptime,
-		UptimeHuman: h.formatUptime(uptime),
-	}
-
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(status)
-}
-
-// setJSONContentType sets the JSON content type header
-func (h *HealthHandler) setJSONContentType(w http.ResponseWriter) {
-	w.Header().Set("Content-Type", "application/json")
-}
-
-// formatUptime formats a duration into human-readable format
-func (h *HealthHandler) formatUptime(d time.Duration) string {
-	if d < time.Minute {
-		return fmt.Sprintf("%.1f seconds", d.Seconds())
-	}
-	if d < time.Hour {
-		return fmt.Sprintf("%.1f minutes", d.Minutes())
-	}
-	if d < 24*time.Hour {
-		return fmt.Sprintf("%.1f hours", d.Hours())
-	}
-	days := int(d.Hours() / 24)
-	hours := int(d.Hours()) % 24
-	return fmt.Sprintf("%d days, %d hours", days, hours)
-}
-`,
-
-		"go-health-models": `package models
-
-import "time"
-
-// HealthReport represents the overall health status of the service
-type HealthReport struct {
-	Status      string        ` + "`json:\"status\"`" + `
-	Timestamp   time.Time     ` + "`json:\"timestamp\"`" + `
-	Version     string        ` + "`json:\"version\"`" + `
-	Uptime      time.Duration ` + "`json:\"uptime\"`" + `
-	UptimeHuman string        ` + "`json:\"uptime_human\"`" + `
-}
-
-// ServerTime represents server time information with multiple formats
-type ServerTime struct {
-	Timestamp time.Time ` + "`json:\"timestamp\"`" + `
-	Timezone  string    ` + "`json:\"timezone\"`" + `
-	Unix      int64     ` + "`json:\"unix\"`" + `
-	UnixMilli int64     ` + "`json:\"unix_milli\"`" + `
-	ISO8601   string    ` + "`json:\"iso8601\"`" + `
-	Formatted string    ` + "`json:\"formatted\"`" + `
-}
-`,
-
-		"go-server-time-handler": `package handlers
-
-import (
-	"encoding/json"
-	"net/http"
-	"time"
-)
-
-// ServerTimeHandler handles server time requests
-type ServerTimeHandler struct{}
-
-// NewServerTimeHandler creates a new server time handler
-func NewServerTimeHandler() *ServerTimeHandler {
-	return &ServerTimeHandler{}
-}
-
-// GetServerTime handles GET /health/time requests
-func (h *ServerTimeHandler) GetServerTime(w http.ResponseWriter, r *http.Request) {
-	now := time.Now()
-
-	response := map[string]interface{}{
-		"timestamp":    now,
-		"unix":         now.Unix(),
-		"unix_milli":   now.UnixMilli(),
-		"rfc3339":      now.Format(time.RFC3339),
-		"timezone":     now.Location().String(),
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		return
-	}
-}
-`,
-
-		"go-tracing": `package observability
-
-// Simplified tracing for intermediate tier
-type TracingProvider struct{}
-
-func NewTracingProvider(serviceName string) *TracingProvider {
-	return &TracingProvider{}
-}
-`,
-
-		"go-metrics": `package observability
-
-// Simplified metrics for intermediate tier
-type MetricsProvider struct{}
-
-func NewMetricsProvider(serviceName string) *MetricsProvider {
-	return &MetricsProvider{}
-}
-`,
-
-		"go-events": `package events
-
-// Simplified events for intermediate tier
-type EventEmitter struct{}
-
-func NewEventEmitter(serviceName, sinkURL string) *EventEmitter {
-	return &EventEmitter{}
-}
-`,
-
-		"go-security-mtls": `package security
-
-import (
-	"crypto/tls"
-	"crypto/x509"
-	"fmt"
-	"io/ioutil"
-	"log"
-	"net/http"
-)
-
-// MTLSConfig holds the configuration for mutual TLS
-type MTLSConfig struct {
-	CertFile   string
-	KeyFile    string
-	CAFile     string
-	ClientAuth tls.ClientAuthType
-}
-
-// SetupMTLS configures mutual TLS for the server
-func SetupMTLS(config MTLSConfig) (*tls.Config, error) {
-	cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load server certificate: %w", err)
-	}
-
-	caCert, err := ioutil.ReadFile(config.CAFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
-	}
-
-	caCertPool := x509.NewCertPool()
-	if !caCertPool.AppendCertsFromPEM(caCert) {
-		return nil, fmt.Errorf("failed to parse CA certificate")
-	}
-
-	tlsConfig := &tls.Config{
-		Certificates: []tls.Certificate{cert},
-		ClientAuth:   config.ClientAuth,
-		ClientCAs:    caCertPool,
-		MinVersion:   tls.VersionTLS12,
-	}
-
-	return tlsConfig, nil
-}
-
-// MTLSMiddleware validates client certificates
-func MTLSMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
-			http.Error(w, "Client certificate required", http.StatusUnauthorized)
-			return
-		}
-
-		clientCert := r.TLS.PeerCertificates[0]
-		clientID := clientCert.Subject.CommonName
-		if clientID == "" {
-			http.Error(w, "Invalid client certificate", http.StatusUnauthorized)
-			return
-		}
-
-		ctx := WithClientIdentity(r.Context(), clientID)
-		r = r.WithContext(ctx)
-
-		log.Printf("mTLS: Client authenticated: %s", clientID)
-		next.ServeHTTP(w, r)
-	})
-}
-`,
-
-		"go-security-rbac": `package security
-
-import (
-	"net/http"
-	"strings"
-)
-
-// Permission represents a specific permission
-type Permission string
-
-const (
-	PermissionHealthRead     Permission = "health:read"
-	PermissionHealthWrite    Permission = "health:write"
-	PermissionMetricsRead    Permission = "metrics:read"
-	PermissionDependencyRead Permission = "dependency:read"
-	PermissionAdminAccess    Permission = "admin:access"
-)
-
-// Role represents a user role with associated permissions
-type Role struct {
-	Name        string       ` + "`json:\"name\"`" + `
-	Permissions []Permission ` + "`json:\"permissions\"`" + `
-}
-
-// User represents a user with roles
-type User struct {
-	ID    string ` + "`json:\"id\"`" + `
-	Roles []Role ` + "`json:\"roles\"`" + `
-}
-
-// RBACPolicy holds the role-based access control policy
-type RBACPolicy struct {
-	Users map[string]User ` + "`json:\"users\"`" + `
-	Roles map[string]Role ` + "`json:\"roles\"`" + `
-}
-
-// DefaultRBACPolicy returns a default RBAC policy
-func DefaultRBACPolicy() *RBACPolicy {
-	return &RBACPolicy{
-		Users: map[string]User{
-			"admin": {
-				ID: "admin",
-				Roles: []Role{
-					{Name: "admin", Permissions: []Permission{
-						PermissionHealthRead,
-						PermissionHealthWrite,
-						PermissionMetricsRead,
-						PermissionDependencyRead,
-						PermissionAdminAccess,
-					}},
-				},
-			},
-			"service": {
-				ID: "service",
-				Roles: []Role{
-					{Name: "service", Permissions: []Permission{
-						PermissionHealthRead,
-					}},
-				},
-			},
-		},
-		Roles: map[string]Role{
-			"admin": {
-				Name: "admin",
-				Permissions: []Permission{
-					PermissionHealthRead,
-					PermissionHealthWrite,
-					PermissionMetricsRead,
-					PermissionDependencyRead,
-					PermissionAdminAccess,
-				},
-			},
-			"service": {
-				Name: "service",
-				Permissions: []Permission{
-					PermissionHealthRead,
-				},
-			},
-		},
-	}
-}
-
-// RBACMiddleware validates user permissions for requests
-func RBACMiddleware(policy *RBACPolicy) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			clientID := GetClientIdentity(r.Context())
-			if clientID == "" {
-				http.Error(w, "Client identity required", http.StatusUnauthorized)
-				return
-			}
-
-			requiredPermission := getRequiredPermission(r)
-			if requiredPermission == "" {
-				next.ServeHTTP(w, r)
-				return
-			}
-
-			if !policy.HasPermission(clientID, requiredPermission) {
-				http.Error(w, "Insufficient permissions", http.StatusForbidden)
-				return
-			}
-
-			next.ServeHTTP(w, r)
-		})
-	}
-}
-
-// HasPermission checks if a user has a specific permission
-func (p *RBACPolicy) HasPermission(userID string, permission Permission) bool {
-	user, exists := p.Users[userID]
-	if !exists {
-		return false
-	}
-
-	for _, role := range user.Roles {
-		for _, perm := range role.Permissions {
-			if perm == permission {
-				return true
-			}
-		}
-	}
-
-	return false
-}
-
-// getRequiredPermission determines the required permission based on the request
-func getRequiredPermission(r *http.Request) Permission {
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	method := r.Method
-
-	switch {
-	case strings.HasPrefix(path, "health"):
-		if method == "GET" {
-			return PermissionHealthRead
-		}
-		return PermissionHealthWrite
-	case strings.HasPrefix(path, "metrics"):
-		return PermissionMetricsRead
-	case strings.HasPrefix(path, "dependencies"):
-		return PermissionDependencyRead
-	case strings.HasPrefix(path, "admin"):
-		return PermissionAdminAccess
-	default:
+// environmentName returns the environment a context renders files for, or ""
+func environmentName(ctx *GenerationContext) string {
+	if ctx.Environment == nil {
 		return ""
 	}
-}
-`,
-
-		"go-security-context": `package security
-
-import "context"
-
-type contextKey string
-
-const (
-	clientIdentityKey contextKey = "client_identity"
-	auditContextKey   contextKey = "audit_context"
-)
-
-// WithClientIdentity adds client identity to context
-func WithClientIdentity(ctx context.Context, clientID string) context.Context {
-	return context.WithValue(ctx, clientIdentityKey, clientID)
-}
-
-// GetClientIdentity retrieves client identity from context
-func GetClientIdentity(ctx context.Context) string {
-	if clientID, ok := ctx.Value(clientIdentityKey).(string); ok {
-		return clientID
-	}
-	return ""
-}
-
-// AuditContext holds audit information
-type AuditContext struct {
-	UserID    string
-	Action    string
-	Resource  string
-	Timestamp int64
-	RequestID string
-}
-
-// WithAuditContext adds audit context
-func WithAuditContext(ctx context.Context, auditCtx *AuditContext) context.Context {
-	return context.WithValue(ctx, auditContextKey, auditCtx)
-}
-
-// GetAuditContext retrieves audit context
-func GetAuditContext(ctx context.Context) *AuditContext {
-	if auditCtx, ok := ctx.Value(auditContextKey).(*AuditContext); ok {
-		return auditCtx
-	}
-	return nil
-}
-`,
-
-		"go-compliance-audit": `package compliance
-
-import (
-	"encoding/json"
-	"fmt"
-	"log"
-	"net/http"
-	"os"
-	"time"
-
-	"{{.Config.GoModule}}/internal/security"
-)
-
-// AuditEvent represents an audit log event
-type AuditEvent struct {
-	Timestamp   time.Time ` + "`json:\"timestamp\"`" + `
-	EventID     string    ` + "`json:\"event_id\"`" + `
-	UserID      string    ` + "`json:\"user_id\"`" + `
-	Action      string    ` + "`json:\"action\"`" + `
-	Resource    string    ` + "`json:\"resource\"`" + `
-	Method      string    ` + "`json:\"method\"`" + `
-	Path        string    ` + "`json:\"path\"`" + `
-	StatusCode  int       ` + "`json:\"status_code\"`" + `
-	Duration    int64     ` + "`json:\"duration_ms\"`" + `
-	RequestID   string    ` + "`json:\"request_id\"`" + `
-	ClientIP    string    ` + "`json:\"client_ip\"`" + `
-	UserAgent   string    ` + "`json:\"user_agent\"`" + `
-	Success     bool      ` + "`json:\"success\"`" + `
-	ErrorMsg    string    ` + "`json:\"error_message,omitempty\"`" + `
-}
-
-// AuditLogger handles audit logging
-type AuditLogger struct {
-	logger   *log.Logger
-	file     *os.File
-	enabled  bool
-}
-
-// NewAuditLogger creates a new audit logger
-func NewAuditLogger(logFile string, enabled bool) (*AuditLogger, error) {
-	if !enabled {
-		return &AuditLogger{enabled: false}, nil
-	}
-
-	file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open audit log file: %w", err)
-	}
-
-	logger := log.New(file, "", 0)
-
-	return &AuditLogger{
-		logger:   logger,
-		file:     file,
-		enabled:  true,
-	}, nil
-}
-
-// LogEvent logs an audit event
-func (a *AuditLogger) LogEvent(event AuditEvent) error {
-	if !a.enabled {
-		return nil
-	}
-
-	eventJSON, err := json.Marshal(event)
-	if err != nil {
-		return fmt.Errorf("failed to marshal audit event: %w", err)
-	}
-
-	a.logger.Println(string(eventJSON))
-	return nil
-}
-
-// LogHTTPRequest logs an HTTP request audit event
-func (a *AuditLogger) LogHTTPRequest(r *http.Request, statusCode int, duration time.Duration, err error) {
-	if !a.enabled {
-		return
-	}
-
-	userID := security.GetClientIdentity(r.Context())
-	if userID == "" {
-		userID = "anonymous"
-	}
-
-	event := AuditEvent{
-		Timestamp:  time.Now().UTC(),
-		EventID:    fmt.Sprintf("audit_%d", time.Now().UnixNano()),
-		UserID:     userID,
-		Action:     "http_request",
-		Resource:   r.URL.Path,
-		Method:     r.Method,
-		Path:       r.URL.Path,
-		StatusCode: statusCode,
-		Duration:   duration.Milliseconds(),
-		RequestID:  r.Header.Get("X-Request-ID"),
-		ClientIP:   r.RemoteAddr,
-		UserAgent:  r.UserAgent(),
-		Success:    statusCode < 400,
-	}
-
-	if err != nil {
-		event.ErrorMsg = err.Error()
-	}
-
-	if logErr := a.LogEvent(event); logErr != nil {
-		log.Printf("Failed to log audit event: %v", logErr)
-	}
-}
-
-// AuditMiddleware creates middleware for HTTP request auditing
-func AuditMiddleware(auditLogger *AuditLogger) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			start := time.Now()
-
-			wrapped := &responseWriter{ResponseWriter: w, statusCode: 200}
-
-			next.ServeHTTP(wrapped, r)
-
-			duration := time.Since(start)
-			auditLogger.LogHTTPRequest(r, wrapped.statusCode, duration, nil)
-		})
-	}
-}
-
-// responseWriter wraps http.ResponseWriter to capture status code
-type responseWriter struct {
-	http.ResponseWriter
-	statusCode int
-}
-
-func (rw *responseWriter) WriteHeader(code int) {
-	rw.statusCode = code
-	rw.ResponseWriter.WriteHeader(code)
-}
-
-// Close closes the audit logger
-func (a *AuditLogger) Close() error {
-	if a.file != nil {
-		return a.file.Close()
-	}
-	return nil
-}
-`,
-
-		"go-dependencies-handler": `package handlers
-
-import (
-	"encoding/json"
-	"net/http"
-	"time"
-)
-
-// DependenciesHandler handles dependency health checks
-type DependenciesHandler struct{}
-
-// NewDependenciesHandler creates a new dependencies handler
-func NewDependenciesHandler() *DependenciesHandler {
-	return &DependenciesHandler{}
-}
-
-// DependencyStatus represents the status of a dependency
-type DependencyStatus struct {
-	Name      string        ` + "`json:\"name\"`" + `
-	Status    string        ` + "`json:\"status\"`" + `
-	Latency   time.Duration ` + "`json:\"latency\"`" + `
-	Error     string        ` + "`json:\"error,omitempty\"`" + `
-	Timestamp time.Time     ` + "`json:\"timestamp\"`" + `
-}
-
-// DependenciesResponse represents the dependencies health response
-type DependenciesResponse struct {
-	Status       string              ` + "`json:\"status\"`" + `
-	Dependencies []DependencyStatus  ` + "`json:\"dependencies\"`" + `
-	Timestamp    time.Time           ` + "`json:\"timestamp\"`" + `
-}
-
-// CheckDependencies handles GET /health/dependencies requests
-func (h *DependenciesHandler) CheckDependencies(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	// Simulate dependency checks
-	dependencies := []DependencyStatus{
-		{
-			Name:      "database",
-			Status:    "healthy",
-			Latency:   5 * time.Millisecond,
-			Timestamp: time.Now(),
-		},
-		{
-			Name:      "cache",
-			Status:    "healthy",
-			Latency:   2 * time.Millisecond,
-			Timestamp: time.Now(),
-		},
-	}
-
-	// Determine overall status
-	overallStatus := "healthy"
-	for _, dep := range dependencies {
-		if dep.Status != "healthy" {
-			overallStatus = "degraded"
-			break
-		}
-	}
-
-	response := DependenciesResponse{
-		Status:       overallStatus,
-		Dependencies: dependencies,
-		Timestamp:    time.Now(),
-	}
-
-	if overallStatus == "healthy" {
-		w.WriteHeader(http.StatusOK)
-	} else {
-		w.WriteHeader(http.StatusServiceUnavailable)
-	}
-
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		return
-	}
-}
-`,
-
-		"ts-types": `// Generated TypeScript types for {{.Config.Name}}
-// Generated at: {{.Timestamp}}
-
-export interface HealthReport {
-  status: string;
-  timestamp: string;
-  version: string;
-  uptime: number;
-  uptime_human: string;
-}
-
-export interface ServerTime {
-  timestamp: string;
-  timezone: string;
-  unix: number;
-  unix_milli: number;
-  iso8601: string;
-  formatted: string;
-}
-
-export type HealthStatus = 'healthy' | 'degraded' | 'unhealthy';
-`,
-
-		"ts-client": `// Generated TypeScript client for {{.Config.Name}}
-// Generated at: {{.Timestamp}}
-
-import { HealthReport, ServerTime } from './types';
-
-export interface HealthClientConfig {
-  baseURL: string;
-  timeout?: number;
-  headers?: Record<string, string>;
-}
-
-export class HealthClient {
-  private baseURL: string;
-  private timeout: number;
-  private headers: Record<string, string>;
-
-  constructor(config: HealthClientConfig) {
-    this.baseURL = config.baseURL.replace(/\/$/, '');
-    this.timeout = config.timeout || 5000;
-    this.headers = config.headers || {};
-  }
-
-  /**
-   * Check the health status of the service
-   */
-  async checkHealth(): Promise<HealthReport> {
-    return this.request<HealthReport>('/health');
-  }
-
-  /**
-   * Get server time information
-   */
-  async getServerTime(): Promise<ServerTime> {
-    return this.request<ServerTime>('/health/time');
-  }
-
-  /**
-   * Check readiness status
-   */
-  async checkReadiness(): Promise<HealthReport> {
-    return this.request<HealthReport>('/health/ready');
-  }
-
-  /**
-   * Check liveness status
-   */
-  async checkLiveness(): Promise<HealthReport> {
-    return this.request<HealthReport>('/health/live');
-  }
-
-  /**
-   * Check startup status
-   */
-  async checkStartup(): Promise<HealthReport> {
-    return this.request<HealthReport>('/health/startup');
-  }
-
-  private async request<T>(path: string): Promise<T> {
-    const controller = new AbortController();
-    const timeoutId = setTimeout(() => controller.abort(), this.timeout);
-
-    try {
-      const response = await fetch(` + "`${this.baseURL}${path}`" + `, {
-        method: 'GET',
-        headers: {
-          'Accept': 'application/json',
-          'Content-Type': 'application/json',
-          ...this.headers,
-        },
-        signal: controller.signal,
-      });
-
-      clearTimeout(timeoutId);
-
-      if (!response.ok) {
-        throw new Error(` + "`HTTP ${response.status}: ${response.statusText}`" + `);
-      }
-
-      return await response.json();
-    } catch (error) {
-      clearTimeout(timeoutId);
-      if (error instanceof Error && error.name === 'AbortError') {
-        throw new Error(` + "`Request timeout after ${this.timeout}ms`" + `);
-      }
-      throw error;
-    }
-  }
-}
-
-// Default export for convenience
-export default HealthClient;
-`,
-
-		"ts-package-json": `{
-  "name": "{{.Config.Name}}-client",
-  "version": "{{.Config.Version}}",
-  "description": "TypeScript client for {{.Config.Name}} health endpoints",
-  "main": "dist/index.js",
-  "types": "dist/index.d.ts",
-  "scripts": {
-    "build": "tsc",
-    "build:watch": "tsc --watch",
-    "clean": "rm -rf dist",
-    "prepublishOnly": "npm run clean && npm run build"
-  },
-  "files": [
-    "dist/**/*",
-    "src/**/*"
-  ],
-  "keywords": [
-    "health-check",
-    "monitoring",
-    "typescript",
-    "client"
-  ],
-  "author": "Generated by template-health-endpoint",
-  "license": "MIT",
-  "devDependencies": {
-    "typescript": "^5.0.0",
-    "@types/node": "^20.0.0"
-  },
-  "engines": {
-    "node": ">=16.0.0"
-  }
-}
-`,
-
-		"ts-config": `{
-  "compilerOptions": {
-    "target": "ES2020",
-    "module": "commonjs",
-    "lib": ["ES2020", "DOM"],
-    "outDir": "./dist",
-    "rootDir": "./src",
-    "strict": true,
-    "esModuleInterop": true,
-    "skipLibCheck": true,
-    "forceConsistentCasingInFileNames": true,
-    "declaration": true,
-    "declarationMap": true,
-    "sourceMap": true,
-    "removeComments": false,
-    "noImplicitAny": true,
-    "strictNullChecks": true,
-    "strictFunctionTypes": true,
-    "noImplicitThis": true,
-    "noImplicitReturns": true,
-    "noFallthroughCasesInSwitch": true,
-    "moduleResolution": "node",
-    "allowSyntheticDefaultImports": true,
-    "experimentalDecorators": true,
-    "emitDecoratorMetadata": true
-  },
-  "include": [
-    "src/**/*"
-  ],
-  "exclude": [
-    "node_modules",
-    "dist"
-  ]
-}
-`,
-
-		"ts-readme": `# {{.Config.Name}} TypeScript Client
-
-TypeScript client library for {{.Config.Name}} health endpoints.
-
-## Installation
-
-` + "```bash" + `
-npm install {{.Config.Name}}-client
-` + "```" + `
-
-## Usage
-
-` + "```typescript" + `
-import { HealthClient } from '{{.Config.Name}}-client';
-
-const client = new HealthClient({
-  baseURL: 'http://localhost:8080',
-  timeout: 5000,
-});
-
-// Check health status
-const health = await client.checkHealth();
-console.log('Health status:', health.status);
-
-// Get server time
-const serverTime = await client.getServerTime();
-console.log('Server time:', serverTime.formatted);
-
-// Check readiness
-const readiness = await client.checkReadiness();
-console.log('Readiness:', readiness.status);
-
-// Check liveness
-const liveness = await client.checkLiveness();
-console.log('Liveness:', liveness.status);
-` + "```" + `
-
-## API
-
-### HealthClient
-
-#### Constructor
-
-` + "```typescript" + `
-new HealthClient(config: HealthClientConfig)
-` + "```" + `
-
-- ` + "`config.baseURL`" + ` - Base URL of the health service
-- ` + "`config.timeout`" + ` - Request timeout in milliseconds (default: 5000)
-- ` + "`config.headers`" + ` - Additional headers to send with requests
-
-#### Methods
-
-- ` + "`checkHealth(): Promise<HealthReport>`" + ` - Get overall health status
-- ` + "`getServerTime(): Promise<ServerTime>`" + ` - Get server time information
-- ` + "`checkReadiness(): Promise<HealthReport>`" + ` - Check if service is ready
-- ` + "`checkLiveness(): Promise<HealthReport>`" + ` - Check if service is alive
-- ` + "`checkStartup(): Promise<HealthReport>`" + ` - Check if service has started up
-
-## Types
-
-See ` + "`src/types.ts`" + ` for complete type definitions.
-
-## Generated by
-
-Template Health Endpoint Generator v{{.Version}}
-Generated at: {{.Timestamp}}
-`,
-
-		"dockerfile": `# Multi-stage build for {{.Config.Name}}
-FROM golang:1.21-alpine AS builder
-
-# Install git and ca-certificates
-RUN apk add --no-cache git ca-certificates
-
-# Set working directory
-WORKDIR /app
-
-# Copy go mod files
-COPY go.mod go.sum ./
-
-# Download dependencies
-RUN go mod download
-
-# Copy source code
-COPY . .
-
-# Build the application
-RUN CGO_ENABLED=0 GOOS=linux go build -a -installsuffix cgo -o main cmd/server/main.go
-
-# Final stage
-FROM alpine:latest
-
-# Install ca-certificates for HTTPS requests
-RUN apk --no-cache add ca-certificates
-
-# Create non-root user
-RUN adduser -D -s /bin/sh appuser
-
-WORKDIR /root/
-
-# Copy the binary from builder stage
-COPY --from=builder /app/main .
-
-# Change ownership to appuser
-RUN chown appuser:appuser main
-
-# Switch to non-root user
-USER appuser
-
-# Expose port
-EXPOSE 8080
-
-# Health check
-HEALTHCHECK --interval=30s --timeout=3s --start-period=5s --retries=3 \\
-  CMD wget --no-verbose --tries=1 --spider http://localhost:8080/health || exit 1
-
-# Run the application
-CMD ["./main"]
-`,
-
-		"makefile": `# Makefile for {{.Config.Name}}
-
-.PHONY: build run test clean docker-build docker-run help
-
-# Variables
-APP_NAME={{.Config.Name}}
-VERSION={{.Config.Version}}
-GO_VERSION=1.21
-DOCKER_IMAGE=$(APP_NAME):$(VERSION)
-
-# Default target
-all: build
-
-# Build the application
-build:
-	@echo "Building $(APP_NAME)..."
-	go build -o bin/$(APP_NAME) cmd/server/main.go
-
-# Run the application
-run: build
-	@echo "Running $(APP_NAME)..."
-	./bin/$(APP_NAME)
-
-# Run tests
-test:
-	@echo "Running tests..."
-	go test -v ./...
-
-# Clean build artifacts
-clean:
-	@echo "Cleaning..."
-	rm -rf bin/
-
-# Install dependencies
-deps:
-	@echo "Installing dependencies..."
-	go mod download
-	go mod tidy
-
-# Format code
-fmt:
-	@echo "Formatting code..."
-	go fmt ./...
-
-# Build Docker image
-docker-build:
-	@echo "Building Docker image $(DOCKER_IMAGE)..."
-	docker build -t $(DOCKER_IMAGE) .
-
-# Run Docker container
-docker-run: docker-build
-	@echo "Running Docker container..."
-	docker run -p 8080:8080 --rm $(DOCKER_IMAGE)
-
-# Show help
-help:
-	@echo "Available targets:"
-	@echo "  build         - Build the application"
-	@echo "  run           - Run the application"
-	@echo "  test          - Run tests"
-	@echo "  clean         - Clean build artifacts"
-	@echo "  deps          - Install dependencies"
-	@echo "  fmt           - Format code"
-	@echo "  docker-build  - Build Docker image"
-	@echo "  docker-run    - Run Docker container"
-	@echo "  help          - Show this help"
-`,
-
-		"api-docs": `# {{.Config.Name}} API Documentation
-
-This document describes the health endpoints provided by {{.Config.Name}}.
-
-## Base URL
-
-` + "```" + `
-http://localhost:8080
-` + "```" + `
-
-## Endpoints
-
-### GET /health
-
-Returns the overall health status of the service.
-
-**Response:**
-` + "```json" + `
-{
-  "status": "healthy",
-  "timestamp": "2024-01-01T12:00:00Z",
-  "version": "{{.Config.Version}}",
-  "uptime": 3600000000000,
-  "uptime_human": "1.0 hours"
-}
-` + "```" + `
-
-### GET /health/time
-
-Returns server time information in multiple formats.
-
-**Response:**
-` + "```json" + `
-{
-  "timestamp": "2024-01-01T12:00:00Z",
-  "timezone": "UTC",
-  "unix": 1704110400,
-  "unix_milli": 1704110400000,
-  "iso8601": "2024-01-01T12:00:00Z",
-  "formatted": "Monday, January 1, 2024 at 12:00:00 PM UTC"
-}
-` + "```" + `
-
-### GET /health/ready
-
-Kubernetes readiness probe endpoint.
-
-**Response:** Same as /health
-
-### GET /health/live
-
-Kubernetes liveness probe endpoint.
-
-**Response:** Same as /health
-
-### GET /health/startup
-
-Kubernetes startup probe endpoint.
-
-**Response:** Same as /health
-
-## Status Codes
-
-- ` + "`200 OK`" + ` - Service is healthy
-- ` + "`503 Service Unavailable`" + ` - Service is unhealthy
-
-## Generated by
-
-Template Health Endpoint Generator v{{.Version}}
-Generated at: {{.Timestamp}}
-`,
-
-		"build-script": `#!/bin/bash
-
-# Build script for {{.Config.Name}}
-
-set -e
-
-echo "🔨 Building {{.Config.Name}}..."
-
-# Clean previous builds
-rm -rf bin/
-mkdir -p bin/
-
-# Build the application
-go build -o bin/{{.Config.Name}} cmd/server/main.go
-
-echo "✅ Build complete: bin/{{.Config.Name}}"
-`,
-
-		"test-script": `#!/bin/bash
-
-# Test script for {{.Config.Name}}
-
-set -e
-
-echo "🧪 Running tests for {{.Config.Name}}..."
-
-# Run tests
-go test -v ./...
-
-# Run tests with coverage
-go test -v -coverprofile=coverage.out ./...
-go tool cover -html=coverage.out -o coverage.html
-
-echo "✅ Tests complete. Coverage report: coverage.html"
-`,
-
-		"k8s-deployment": `apiVersion: apps/v1
-kind: Deployment
-metadata:
-  name: {{.Config.Name}}
-  labels:
-    app: {{.Config.Name}}
-    version: {{.Config.Version}}
-spec:
-  replicas: 3
-  selector:
-    matchLabels:
-      app: {{.Config.Name}}
-  template:
-    metadata:
-      labels:
-        app: {{.Config.Name}}
-        version: {{.Config.Version}}
-    spec:
-      containers:
-      - name: {{.Config.Name}}
-        image: {{.Config.Name}}:{{.Config.Version}}
-        ports:
-        - containerPort: 8080
-          name: http
-        env:
-        - name: PORT
-          value: "8080"
-        - name: VERSION
-          value: {{.Config.Version}}
-        resources:
-          requests:
-            memory: "64Mi"
-            cpu: "50m"
-          limits:
-            memory: "128Mi"
-            cpu: "100m"
-        livenessProbe:
-          httpGet:
-            path: /health/live
-            port: 8080
-          initialDelaySeconds: 30
-          periodSeconds: 10
-          timeoutSeconds: 5
-          failureThreshold: 3
-        readinessProbe:
-          httpGet:
-            path: /health/ready
-            port: 8080
-          initialDelaySeconds: 5
-          periodSeconds: 5
-          timeoutSeconds: 3
-          failureThreshold: 3
-        startupProbe:
-          httpGet:
-            path: /health/startup
-            port: 8080
-          initialDelaySeconds: 10
-          periodSeconds: 10
-          timeoutSeconds: 5
-          failureThreshold: 30
-      restartPolicy: Always
-`,
-
-		"k8s-service": `apiVersion: v1
-kind: Service
-metadata:
-  name: {{.Config.Name}}
-  labels:
-    app: {{.Config.Name}}
-spec:
-  selector:
-    app: {{.Config.Name}}
-  ports:
-  - name: http
-    port: 80
-    targetPort: 8080
-    protocol: TCP
-  type: ClusterIP
-`,
-
-		"k8s-configmap": `apiVersion: v1
-kind: ConfigMap
-metadata:
-  name: {{.Config.Name}}-config
-  labels:
-    app: {{.Config.Name}}
-data:
-  PORT: "8080"
-  VERSION: "{{.Config.Version}}"
-  SERVICE_NAME: "{{.Config.Name}}"
-`,
-
-		"k8s-servicemonitor": `apiVersion: monitoring.coreos.com/v1
-kind: ServiceMonitor
-metadata:
-  name: {{.Config.Name}}
-  labels:
-    app: {{.Config.Name}}
-spec:
-  selector:
-    matchLabels:
-      app: {{.Config.Name}}
-  endpoints:
-  - port: http
-    path: /metrics
-    interval: 30s
-    scrapeTimeout: 10s
-`,
-
-		"k8s-ingress": `apiVersion: networking.k8s.io/v1
-kind: Ingress
-metadata:
-  name: {{.Config.Name}}
-  labels:
-    app: {{.Config.Name}}
-  annotations:
-    nginx.ingress.kubernetes.io/rewrite-target: /
-spec:
-  rules:
-  - host: {{.Config.Name}}.local
-    http:
-      paths:
-      - path: /
-        pathType: Prefix
-        backend:
-          service:
-            name: {{.Config.Name}}
-            port:
-              number: 80
-`,
-
-		"docker-compose": `version: '3.8'
-
-services:
-  {{.Config.Name}}:
-    build: .
-    ports:
-      - "8080:8080"
-    environment:
-      - PORT=8080
-      - VERSION={{.Config.Version}}
-    healthcheck:
-      test: ["CMD", "wget", "--no-verbose", "--tries=1", "--spider", "http://localhost:8080/health"]
-      interval: 30s
-      timeout: 3s
-      retries: 3
-      start_period: 5s
-    restart: unless-stopped
-    networks:
-      - health-network
-
-networks:
-  health-network:
-    driver: bridge
-`,
-
-		"dockerignore": `# Git
-.git
-.gitignore
-
-# Documentation
-*.md
-README*
-
-# Build artifacts
-bin/
-dist/
-build/
-
-# IDE files
-.vscode/
-.idea/
-*.swp
-*.swo
-
-# OS files
-.DS_Store
-Thumbs.db
-
-# Logs
-*.log
-
-# Environment files
-.env*
-
-# Node modules
-node_modules/
-
-# Test files
-*_test.go
-*.test
-
-# Coverage
-*.out
-coverage/
-`,
-
-	}
-
-	// Parse and register each template
-	for name, content := range templates {
-		tmpl, err := template.New(name).Funcs(r.functions).Parse(content)
-		if err != nil {
-			return fmt.Errorf("failed to parse template %s: %w", name, err)
-		}
-		r.templates[name] = tmpl
-	}
-
-	return nil
+	return ctx.Environment.Name
 }
@@ -1,9 +1,16 @@
 package generator
 
 import (
+	"context"
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/LarsArtmann/BMAD-METHOD/pkg/config"
 )
@@ -244,6 +251,652 @@ func TestGenerator_Documentation(t *testing.T) {
 	}
 }
 
+func TestGenerator_Manifest(t *testing.T) {
+	config := &config.ProjectConfig{
+		Name:        "manifest-test",
+		Description: "Test generation manifest",
+		GoModule:    "github.com/example/manifest-test",
+		Tier:        config.TierBasic,
+		Version:     "1.0.0",
+		OutputDir:   "test-manifest",
+		Features: config.FeatureConfig{
+			Kubernetes: true,
+		},
+	}
+
+	// Clean up
+	defer os.RemoveAll(config.OutputDir)
+
+	generator, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	if err := generator.Generate(); err != nil {
+		t.Fatalf("Failed to generate project: %v", err)
+	}
+
+	manifest, err := LoadManifest(config.OutputDir)
+	if err != nil {
+		t.Fatalf("Failed to load manifest: %v", err)
+	}
+
+	if manifest.Tier != "basic" || manifest.GeneratorVersion != GeneratorVersion {
+		t.Errorf("Unexpected manifest header: tier=%s version=%s", manifest.Tier, manifest.GeneratorVersion)
+	}
+
+	if manifest.Config == nil || manifest.Config.GoModule != config.GoModule {
+		t.Error("Resolved configuration not recorded in manifest")
+	}
+
+	entry, ok := manifest.File("internal/server/server.go")
+	if !ok {
+		t.Fatal("internal/server/server.go not recorded in manifest")
+	}
+	if entry.Template != "go-server" || entry.TemplateHash == "" {
+		t.Errorf("Unexpected template record: %+v", entry)
+	}
+
+	drift, err := manifest.Drift(config.OutputDir)
+	if err != nil {
+		t.Fatalf("Failed to check drift: %v", err)
+	}
+	if len(drift) != 0 {
+		t.Errorf("Freshly generated project reports drift: %+v", drift)
+	}
+
+	// Edit a generated file and expect it to be reported
+	serverPath := filepath.Join(config.OutputDir, "internal/server/server.go")
+	if err := os.WriteFile(serverPath, []byte("package server\n"), 0644); err != nil {
+		t.Fatalf("Failed to edit server file: %v", err)
+	}
+
+	drift, err = manifest.Drift(config.OutputDir)
+	if err != nil {
+		t.Fatalf("Failed to check drift: %v", err)
+	}
+	if len(drift) != 1 || drift[0].Path != "internal/server/server.go" {
+		t.Errorf("Expected drift for edited server file, got %+v", drift)
+	}
+}
+
+func TestGenerator_OutputConflicts(t *testing.T) {
+	// Every run renders the same timestamps, so only edited files conflict
+	t.Setenv(SourceDateEpochEnv, "1700000000")
+
+	newConfig := func() *config.ProjectConfig {
+		return &config.ProjectConfig{
+			Name:        "conflict-test",
+			Description: "Test output conflict policies",
+			GoModule:    "github.com/example/conflict-test",
+			Tier:        config.TierBasic,
+			Version:     "1.0.0",
+			OutputDir:   "test-conflicts",
+		}
+	}
+
+	outputDir := newConfig().OutputDir
+	os.RemoveAll(outputDir)
+	defer os.RemoveAll(outputDir)
+
+	readmePath := filepath.Join(outputDir, "README.md")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		t.Fatalf("Failed to create output directory: %v", err)
+	}
+	if err := os.WriteFile(readmePath, []byte("my readme\n"), 0644); err != nil {
+		t.Fatalf("Failed to write README: %v", err)
+	}
+
+	generate := func(policy ConflictPolicy) error {
+		generator, err := New(newConfig())
+		if err != nil {
+			t.Fatalf("Failed to create generator: %v", err)
+		}
+		generator.SetConflictPolicy(policy)
+		return generator.Generate()
+	}
+
+	// Default policy refuses to touch a non-empty directory
+	if err := generate(ConflictPolicyFail); !errors.Is(err, ErrOutputNotEmpty) {
+		t.Fatalf("Expected ErrOutputNotEmpty, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "go.mod")); !os.IsNotExist(err) {
+		t.Error("Failed generation wrote into the output directory")
+	}
+
+	// Skip keeps existing files and adds the rest
+	if err := generate(ConflictPolicySkip); err != nil {
+		t.Fatalf("Skip generation failed: %v", err)
+	}
+	if content, _ := os.ReadFile(readmePath); string(content) != "my readme\n" {
+		t.Errorf("Skip policy replaced README: %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "go.mod")); err != nil {
+		t.Errorf("Skip policy did not add go.mod: %v", err)
+	}
+
+	// Overwrite replaces existing files
+	if err := generate(ConflictPolicyOverwrite); err != nil {
+		t.Fatalf("Overwrite generation failed: %v", err)
+	}
+	if content, _ := os.ReadFile(readmePath); !contains(string(content), "conflict-test") {
+		t.Error("Overwrite policy kept the old README")
+	}
+
+	// Merge without a previous generation to diff against reports the edited file as conflicting
+	if err := os.Remove(filepath.Join(outputDir, ManifestFileName)); err != nil {
+		t.Fatalf("Failed to remove manifest: %v", err)
+	}
+	if err := os.WriteFile(readmePath, []byte("my readme\n"), 0644); err != nil {
+		t.Fatalf("Failed to write README: %v", err)
+	}
+	merger, err := New(newConfig())
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	merger.SetConflictPolicy(ConflictPolicyMerge)
+	if err := merger.Generate(); err != nil {
+		t.Fatalf("Merge generation failed: %v", err)
+	}
+	if conflicts := merger.Conflicts(); len(conflicts) != 1 || conflicts[0] != "README.md" {
+		t.Errorf("Conflicts() = %v, want [README.md]", conflicts)
+	}
+	if content, _ := os.ReadFile(readmePath); !contains(string(content), "<<<<<<<") {
+		t.Error("Merge policy did not write conflict markers into README")
+	}
+
+	// No staging or rollback directories are left behind
+	leftovers, _ := filepath.Glob("." + outputDir + ".*")
+	if len(leftovers) != 0 {
+		t.Errorf("Temporary directories left behind: %v", leftovers)
+	}
+}
+
+func TestGenerator_RollbackOnError(t *testing.T) {
+	config := &config.ProjectConfig{
+		Name:        "rollback-test",
+		Description: "Test rollback of failed generation",
+		GoModule:    "github.com/example/rollback-test",
+		Tier:        config.TierBasic,
+		Version:     "1.0.0",
+		OutputDir:   "test-rollback",
+	}
+
+	defer os.RemoveAll(config.OutputDir)
+
+	// Override one template with one that fails to execute
+	registry, err := NewTemplateRegistry()
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	registry, err = NewTemplateRegistryFromLayers(append(registry.Layers(), TemplateLayer{
+		Name: "broken",
+		FS:   fstest.MapFS{"go-server.tmpl": {Data: []byte("{{.Missing.Field}}")}},
+	})...)
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+
+	generator, err := NewWithRegistry(config, registry)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	if err := generator.Generate(); err == nil {
+		t.Fatal("Expected generation to fail")
+	}
+
+	if _, err := os.Stat(config.OutputDir); !os.IsNotExist(err) {
+		t.Error("Failed generation left an output directory behind")
+	}
+}
+
+func TestGenerator_Incremental(t *testing.T) {
+	newConfig := func(version string) *config.ProjectConfig {
+		return &config.ProjectConfig{
+			Name:        "incremental-test",
+			Description: "Test incremental regeneration",
+			GoModule:    "github.com/example/incremental-test",
+			Tier:        config.TierBasic,
+			Version:     version,
+			OutputDir:   "test-incremental",
+		}
+	}
+
+	outputDir := newConfig("").OutputDir
+	os.RemoveAll(outputDir)
+	defer os.RemoveAll(outputDir)
+
+	generate := func(version string) *Generator {
+		generator, err := New(newConfig(version))
+		if err != nil {
+			t.Fatalf("Failed to create generator: %v", err)
+		}
+		generator.SetIncremental(true)
+		if err := generator.Generate(); err != nil {
+			t.Fatalf("Failed to generate project: %v", err)
+		}
+		return generator
+	}
+
+	// Nothing to compare against: everything is generated
+	first := generate("1.0.0")
+	if first.Skipped() != 0 {
+		t.Errorf("First run skipped %d files", first.Skipped())
+	}
+
+	// Backdate every file so rewritten ones stand out
+	old := time.Now().Add(-time.Hour)
+	for _, file := range first.Manifest().Files {
+		os.Chtimes(filepath.Join(outputDir, file.Path), old, old)
+	}
+
+	// Only files whose templates read Config.Version are rewritten
+	second := generate("2.0.0")
+	if second.Skipped() == 0 {
+		t.Error("Incremental run re-rendered every file")
+	}
+	for _, file := range second.Manifest().Files {
+		info, err := os.Stat(filepath.Join(outputDir, file.Path))
+		if err != nil {
+			t.Fatalf("Missing %s: %v", file.Path, err)
+		}
+		readsVersion := slices.Contains(file.Fields, "Config.Version")
+		if rewritten := info.ModTime().After(old); rewritten != readsVersion {
+			t.Errorf("%s rewritten = %v, but reads Config.Version = %v", file.Path, rewritten, readsVersion)
+		}
+	}
+
+	makefile, _ := os.ReadFile(filepath.Join(outputDir, "Makefile"))
+	if !strings.Contains(string(makefile), "2.0.0") {
+		t.Error("Makefile was not re-rendered with the new version")
+	}
+
+	// update records rendered files with what an incremental run compares
+	rendered, err := second.Render(second.Manifest().Context())
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, file := range rendered {
+		entry, _ := second.Manifest().File(file.Path)
+		if file.InputsHash != entry.InputsHash || !slices.Equal(file.Fields, entry.Fields) {
+			t.Errorf("Render() of %s has fields %v and inputs hash %s, manifest %v and %s", file.Path, file.Fields, file.InputsHash, entry.Fields, entry.InputsHash)
+		}
+	}
+}
+
+func TestGenerator_Variables(t *testing.T) {
+	tierConfig := &config.TemplateConfig{
+		Tier: "basic",
+		Variables: []config.VariableSpec{
+			{Name: "port", Type: config.VariableInt, Default: "8080"},
+			{Name: "log_level", Type: config.VariableEnum, Enum: []string{"debug", "info"}, Default: "info"},
+			{Name: "team", Type: config.VariableString, Pattern: "^[a-z-]+$", Required: true},
+			{Name: "sample_rate", Type: config.VariableInt, Feature: "opentelemetry"},
+		},
+	}
+	builtin, err := BuiltinTemplateLayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := NewTemplateRegistryFromLayers(builtin, TemplateLayer{Name: "test", FS: fstest.MapFS{
+		"go-config.tmpl": {Data: []byte("package config\n\n// {{.Vars.port}} {{.Vars.log_level}} {{.Vars.team}} {{.Vars.sample_rate}}\n")},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+
+	generate := func(variables map[string]string) (string, error) {
+		cfg := &config.ProjectConfig{
+			Name:      "vars-test",
+			GoModule:  "github.com/example/vars-test",
+			Tier:      config.TierBasic,
+			Variables: variables,
+		}
+		generator, err := NewWithRegistry(cfg, registry)
+		if err != nil {
+			t.Fatalf("Failed to create generator: %v", err)
+		}
+		generator.SetTemplateConfig(tierConfig)
+		out := NewMemoryOutput()
+		generator.SetOutput(out)
+		if err := generator.Generate(); err != nil {
+			return "", err
+		}
+		content, _ := out.ReadFile("internal/config/config.go")
+		return string(content), nil
+	}
+
+	content, err := generate(map[string]string{"port": "9090", "team": "platform"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	// sample_rate does not apply without OpenTelemetry and is left out
+	if !strings.Contains(content, "// 9090 info platform <no value>\n") {
+		t.Errorf("Rendered %q", content)
+	}
+
+	_, err = generate(map[string]string{"port": "http", "log_level": "trace", "team": "Platform", "sample_rate": "5", "colour": "red"})
+	if err == nil {
+		t.Fatal("Expected invalid variables to fail generation")
+	}
+	for _, want := range []string{"port", "log_level", "team", "sample_rate requires the opentelemetry feature", "unknown variable colour"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error %q does not mention %s", err, want)
+		}
+	}
+
+	if _, err := generate(nil); err == nil || !strings.Contains(err.Error(), "team is required") {
+		t.Errorf("Expected a missing required variable error, got %v", err)
+	}
+}
+
+func TestGenerator_Environments(t *testing.T) {
+	layer, err := config.ParseLayer(config.LayerProject, "service.yaml", []byte(`
+apiVersion: v1
+name: env-test
+go_module: github.com/example/env-test
+tier: enterprise
+environments:
+  development:
+    log_level: debug
+  staging:
+    resources:
+      requests: {cpu: 500m}
+  production:
+    replicas: 5
+    resources:
+      limits: {cpu: "2", memory: 1Gi}
+    otel_endpoint: http://otel-collector:4317
+    log_level: warn
+    mtls:
+      enabled: true
+      cert_file: /etc/ssl/certs/server.crt
+      key_file: /etc/ssl/private/server.key
+      ca_file: /etc/ssl/certs/ca.crt
+`))
+	if err != nil {
+		t.Fatalf("ParseLayer() error = %v", err)
+	}
+	resolved, err := config.Resolve(layer)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	generator, err := New(resolved.Config)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	out := NewMemoryOutput()
+	generator.SetOutput(out)
+	if err := generator.Generate(); err != nil {
+		t.Fatalf("Failed to generate project: %v", err)
+	}
+
+	for file, want := range map[string][]string{
+		"configs/development.yaml":                                          {`level: "debug"`, "enabled: false"},
+		"configs/production.yaml":                                           {`endpoint: "http://otel-collector:4317"`, `level: "warn"`, `cert_file: "/etc/ssl/certs/server.crt"`},
+		"deployments/kubernetes/kustomization.yaml":                         {"- servicemonitor.yaml", "- ingress.yaml"},
+		"deployments/kubernetes/overlays/development/kustomization.yaml":    {"environment: development"},
+		"deployments/kubernetes/overlays/development/deployment-patch.yaml": {"replicas: 3", `cpu: "100m"`},
+		"deployments/kubernetes/overlays/production/deployment-patch.yaml":  {"replicas: 5", `memory: "64Mi"`, `memory: "1Gi"`, `cpu: "2"`},
+		"deployments/kubernetes/overlays/staging/deployment-patch.yaml":     {"limits:\n            memory: \"128Mi\"\n            cpu: \"500m\""},
+		"deployments/kubernetes/overlays/production/configmap-patch.yaml":   {`OTEL_EXPORTER_OTLP_ENDPOINT: "http://otel-collector:4317"`, `MTLS_CA_FILE: "/etc/ssl/certs/ca.crt"`},
+		"deployments/kubernetes/overlays/development/configmap-patch.yaml":  {`LOG_LEVEL: "debug"`},
+	} {
+		content, ok := out.ReadFile(file)
+		if !ok {
+			t.Errorf("%s was not generated", file)
+			continue
+		}
+		for _, s := range want {
+			if !strings.Contains(string(content), s) {
+				t.Errorf("%s does not contain %q:\n%s", file, s, content)
+			}
+		}
+	}
+	if content, _ := out.ReadFile("deployments/kubernetes/overlays/development/configmap-patch.yaml"); strings.Contains(string(content), "MTLS") {
+		t.Errorf("development enables mTLS:\n%s", content)
+	}
+
+	// Render and update rebuild each file's environment from the manifest
+	manifest := generator.Manifest()
+	rendered, err := generator.Render(manifest.Context())
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, file := range rendered {
+		generated, _ := out.ReadFile(file.Path)
+		if string(file.Content) != string(generated) {
+			t.Errorf("Render() of %s differs from Generate():\n%s", file.Path, UnifiedDiff("generate", "render", string(generated), string(file.Content)))
+		}
+	}
+	entry, _ := manifest.File("configs/production.yaml")
+	if entry.Environment != "production" {
+		t.Errorf("Manifest environment of configs/production.yaml = %q, want production", entry.Environment)
+	}
+	if ctx := manifest.FileContext(entry); ctx.Environment == nil || ctx.Environment.Config.Replicas != 5 {
+		t.Errorf("FileContext() = %+v, want the production environment", ctx.Environment)
+	}
+
+	cfg := resolved.Config
+	cfg.Features.Security = false
+	cfg.Environments["Prod"] = config.EnvironmentConfig{Resources: config.ResourcesConfig{Requests: config.ResourceList{CPU: "half"}}}
+	var problems config.ValidationErrors
+	if err := cfg.Validate(); !errors.As(err, &problems) {
+		t.Fatalf("Validate() error = %v, want ValidationErrors", err)
+	}
+	var got []string
+	for _, problem := range problems {
+		got = append(got, problem.Path)
+	}
+	want := []string{"features.compliance", "environments.Prod", "environments.Prod.resources.requests.cpu", "environments.production.mtls.enabled"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Problems = %v, want %v", got, want)
+	}
+}
+
+func TestGenerator_Reproducible(t *testing.T) {
+	t.Setenv(SourceDateEpochEnv, "1700000000")
+
+	render := func() map[string][]byte {
+		generator, err := New(&config.ProjectConfig{
+			Name:     "repro-test",
+			GoModule: "github.com/example/repro-test",
+			Tier:     config.TierEnterprise,
+			Features: config.FeatureConfig{Kubernetes: true, Docker: true, TypeScript: true, OpenTelemetry: true},
+			Kubernetes: config.KubernetesConfig{
+				Enabled: true,
+				Labels:  map[string]string{"team": "core", "app": "repro", "env": "prod", "cost": "shared"},
+			},
+			Variables: map[string]string{"b": "2", "a": "1"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create generator: %v", err)
+		}
+		// Without declarations the variables map is passed through as is
+		generator.SetTemplateConfig(nil)
+		out := NewMemoryOutput()
+		generator.SetOutput(out)
+		if err := generator.Generate(); err != nil {
+			t.Fatalf("Failed to generate project: %v", err)
+		}
+		files := make(map[string][]byte)
+		for _, name := range out.Files() {
+			files[name], _ = out.ReadFile(name)
+		}
+		return files
+	}
+
+	first, second := render(), render()
+	for _, name := range unionKeys(first, second) {
+		if string(first[name]) != string(second[name]) {
+			t.Errorf("%s differs between two runs:\n%s", name, UnifiedDiff("first", "second", string(first[name]), string(second[name])))
+		}
+	}
+	if !strings.Contains(string(first[ManifestFileName]), "2023-11-14T22:13:20Z") {
+		t.Errorf("Manifest does not record SOURCE_DATE_EPOCH:\n%s", first[ManifestFileName])
+	}
+
+	generator, err := New(&config.ProjectConfig{Name: "repro-test", GoModule: "example.com/repro", Tier: config.TierAdvanced})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasks := generator.collectGenerationTasks(&GenerationContext{})
+	if !slices.IsSortedFunc(tasks, func(a, b GenerationTask) int { return strings.Compare(a.Filename, b.Filename) }) {
+		t.Error("Generation tasks are not sorted by file name")
+	}
+
+	t.Setenv(SourceDateEpochEnv, "yesterday")
+	if _, err := generator.GenerationTime(); err == nil {
+		t.Error("GenerationTime() accepted an invalid SOURCE_DATE_EPOCH")
+	}
+}
+
+func TestGenerator_InvalidConfig(t *testing.T) {
+	cfg := &config.ProjectConfig{
+		Name:     "Orders_API",
+		GoModule: "github.com/acme/orders api",
+		Tier:     config.TierEnterprise,
+	}
+	cfg.ApplyTierDefaults()
+	cfg.Features.Security = false
+	cfg.Kubernetes.Port = 70000
+	cfg.Kubernetes.Ingress.TLS = true
+	cfg.Kubernetes.HealthProbes.ReadinessProbe.TimeoutSeconds = 5
+	cfg.Observability.Metrics.Enabled = false
+
+	_, err := New(cfg)
+	var problems config.ValidationErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("New() error = %v, want ValidationErrors", err)
+	}
+
+	var got []string
+	for _, problem := range problems {
+		got = append(got, problem.Path)
+		if problem.Suggestion == "" {
+			t.Errorf("%s: no suggestion", problem.Path)
+		}
+	}
+	want := []string{
+		"name",
+		"go_module",
+		"kubernetes.port",
+		"kubernetes.health_probes.readiness_probe.timeout_seconds",
+		"kubernetes.ingress.host",
+		"kubernetes.service_monitor",
+		"features.compliance",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Problems = %v, want %v", got, want)
+	}
+	if problems[0].Suggestion != `use "orders-api"` {
+		t.Errorf("Suggestion = %q, want a DNS-1123 name", problems[0].Suggestion)
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	want := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+	for _, value := range []string{"1700000000", "2023-11-14T22:13:20Z", "2023-11-15T00:13:20+02:00"} {
+		got, err := ParseTimestamp(value)
+		if err != nil || !got.Equal(want) || got.Location() != time.UTC {
+			t.Errorf("ParseTimestamp(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	if _, err := ParseTimestamp("last tuesday"); err == nil {
+		t.Error("ParseTimestamp() accepted an invalid time")
+	}
+}
+
+func TestGenerator_Check(t *testing.T) {
+	t.Setenv(SourceDateEpochEnv, "")
+	cfg := &config.ProjectConfig{
+		Name:      "check-test",
+		GoModule:  "github.com/example/check-test",
+		Tier:      config.TierBasic,
+		OutputDir: filepath.Join(t.TempDir(), "check-test"),
+	}
+	generator, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	if err := generator.Generate(); err != nil {
+		t.Fatalf("Failed to generate project: %v", err)
+	}
+
+	// An untouched project checks clean although it was generated at another time
+	time.Sleep(time.Second)
+	drifts, err := generator.Check(context.Background())
+	if err != nil || len(drifts) != 0 {
+		t.Fatalf("Check() of an untouched project = %+v, %v", drifts, err)
+	}
+
+	os.WriteFile(filepath.Join(cfg.OutputDir, "Makefile"), []byte("hand-edited\n"), 0644)
+	os.Remove(filepath.Join(cfg.OutputDir, "go.mod"))
+	os.WriteFile(filepath.Join(cfg.OutputDir, "NOTES.md"), []byte("mine\n"), 0644)
+
+	drifts, err = generator.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(drifts) != 2 || drifts[0].Path != "Makefile" || drifts[1].Path != "go.mod" || !drifts[1].Missing {
+		t.Fatalf("Check() = %+v, want the edited Makefile and the missing go.mod", drifts)
+	}
+	if !strings.Contains(drifts[0].Diff, "-hand-edited") {
+		t.Errorf("Makefile diff does not show the edit:\n%s", drifts[0].Diff)
+	}
+}
+
+func TestGenerator_CheckCopy(t *testing.T) {
+	t.Setenv(SourceDateEpochEnv, "")
+	root := t.TempDir()
+	cfg := &config.ProjectConfig{
+		Name:      "check-copy",
+		GoModule:  "github.com/example/check-copy",
+		Tier:      config.TierBasic,
+		OutputDir: filepath.Join(root, "generated"),
+	}
+	generator, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	if err := generator.Generate(); err != nil {
+		t.Fatalf("Failed to generate project: %v", err)
+	}
+
+	// A checkout of the project elsewhere checks clean
+	copied := filepath.Join(root, "checkout")
+	err = filepath.WalkDir(cfg.OutputDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(cfg.OutputDir, path)
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(copied, rel), 0755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(copied, rel), data, 0644)
+	})
+	if err != nil {
+		t.Fatalf("Failed to copy project: %v", err)
+	}
+
+	checkCfg := *cfg
+	checkCfg.OutputDir = copied
+	checker, err := New(&checkCfg)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	drifts, err := checker.Check(context.Background())
+	if err != nil || len(drifts) != 0 {
+		t.Fatalf("Check() of a copied project = %+v, %v", drifts, err)
+	}
+}
+
 // Helper function to check if a string contains a substring
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) &&
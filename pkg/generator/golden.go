@@ -0,0 +1,262 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/config"
+)
+
+const (
+	// GoldenConfigFile is the fixture configuration of a golden test case
+	GoldenConfigFile = "config.yaml"
+
+	// GoldenOutputDir is the directory of a golden test case holding the expected project
+	GoldenOutputDir = "output"
+
+	// DefaultGoldenDir is where a project keeps the golden test cases for its template overlays
+	DefaultGoldenDir = ".template-health/golden"
+)
+
+// GoldenTimestamp is the generation time golden projects are rendered with
+var GoldenTimestamp = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// GoldenCase is a golden test case: a fixture configuration and the
+// directory holding the project it must render to. Cases live in
+// subdirectories of a golden directory:
+//
+//	<golden dir>/<case>/config.yaml
+//	<golden dir>/<case>/output/...
+type GoldenCase struct {
+	Name string
+	Dir  string
+}
+
+// GoldenMismatch is a file that differs from its golden copy
+type GoldenMismatch struct {
+	Path string
+	Diff string // unified diff from the golden file to the rendered file
+}
+
+// LoadGoldenCases returns the golden test cases below dir, sorted by name
+func LoadGoldenCases(dir string) ([]GoldenCase, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read golden directory: %w", err)
+	}
+
+	var cases []GoldenCase
+	for _, entry := range entries {
+		caseDir := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(caseDir, GoldenConfigFile)); err != nil {
+			continue
+		}
+		cases = append(cases, GoldenCase{Name: entry.Name(), Dir: caseDir})
+	}
+	return cases, nil
+}
+
+// Config loads the fixture configuration of the case the way generate
+// does: validated and with tier defaults applied
+func (c GoldenCase) Config() (*config.ProjectConfig, error) {
+	data, err := os.ReadFile(filepath.Join(c.Dir, GoldenConfigFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	layer, err := config.ParseLayer(config.LayerProject, GoldenConfigFile, data)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := config.Resolve(layer)
+	if err != nil {
+		return nil, err
+	}
+	if err := resolved.Config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", GoldenConfigFile, err)
+	}
+	return resolved.Config, nil
+}
+
+// Render renders the case's project into memory at GoldenTimestamp. The
+// manifest and template snapshots are left out: they only restate what
+// the rendered files already show.
+func (c GoldenCase) Render(registry *TemplateRegistry) (map[string][]byte, error) {
+	cfg, err := c.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	gen, err := NewWithRegistry(cfg, registry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create generator: %w", err)
+	}
+	out := NewMemoryOutput()
+	gen.SetOutput(out)
+	gen.SetTimestamp(GoldenTimestamp)
+	if err := gen.GenerateContext(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", c.Name, err)
+	}
+
+	files := make(map[string][]byte)
+	for _, name := range out.Files() {
+		if name == ManifestFileName || strings.HasPrefix(name, SnapshotDir+"/") {
+			continue
+		}
+		files[name], _ = out.ReadFile(name)
+	}
+	return files, nil
+}
+
+// Compare compares rendered files with the case's golden output byte for
+// byte, returning the mismatches sorted by path. Files missing from either
+// side are reported with a diff against an empty file.
+func (c GoldenCase) Compare(files map[string][]byte) ([]GoldenMismatch, error) {
+	golden, err := readGoldenOutput(filepath.Join(c.Dir, GoldenOutputDir))
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []GoldenMismatch
+	for _, name := range unionKeys(golden, files) {
+		want, inGolden := golden[name]
+		got, rendered := files[name]
+		if inGolden && rendered && string(want) == string(got) {
+			continue
+		}
+
+		oldName, newName := "golden/"+name, "rendered/"+name
+		switch {
+		case !inGolden:
+			oldName = "/dev/null"
+		case !rendered:
+			newName = "/dev/null"
+		}
+		mismatches = append(mismatches, GoldenMismatch{
+			Path: name,
+			Diff: UnifiedDiff(oldName, newName, string(want), string(got)),
+		})
+	}
+	return mismatches, nil
+}
+
+// Update replaces the case's golden output with the rendered files
+func (c GoldenCase) Update(files map[string][]byte) error {
+	dir := filepath.Join(c.Dir, GoldenOutputDir)
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove golden output: %w", err)
+	}
+
+	out := NewDirOutput(dir)
+	for name, data := range files {
+		if err := out.WriteFile(name, data, 0644); err != nil {
+			return fmt.Errorf("failed to write golden file %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Check renders the case and compares it with its golden output; with
+// update set, a mismatching golden output is rewritten instead and the
+// mismatches that were fixed are returned
+func (c GoldenCase) Check(registry *TemplateRegistry, update bool) ([]GoldenMismatch, error) {
+	files, err := c.Render(registry)
+	if err != nil {
+		return nil, err
+	}
+	mismatches, err := c.Compare(files)
+	if err != nil || !update || len(mismatches) == 0 {
+		return mismatches, err
+	}
+	return mismatches, c.Update(files)
+}
+
+// RunGoldenTests runs every golden test case below dir as a subtest,
+// reporting each mismatching file with a unified diff. With update set,
+// mismatching golden outputs are rewritten instead. Tests usually pass a
+// flag:
+//
+//	var update = flag.Bool("update", false, "update golden files")
+//
+//	func TestGolden(t *testing.T) {
+//		registry, _ := generator.NewTemplateRegistry()
+//		generator.RunGoldenTests(t, "testdata/golden", registry, *update)
+//	}
+func RunGoldenTests(t *testing.T, dir string, registry *TemplateRegistry, update bool) {
+	t.Helper()
+
+	cases, err := LoadGoldenCases(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) == 0 {
+		t.Fatalf("no golden test cases in %s", dir)
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			mismatches, err := c.Check(registry, update)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, mismatch := range mismatches {
+				if update {
+					t.Logf("updated %s", mismatch.Path)
+					continue
+				}
+				t.Errorf("%s differs from its golden copy (run with -update to accept):\n%s", mismatch.Path, mismatch.Diff)
+			}
+		})
+	}
+}
+
+// readGoldenOutput reads every file below dir; a missing dir has no files
+func readGoldenOutput(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		files[path.Clean(filepath.ToSlash(rel))] = data
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read golden output: %w", err)
+	}
+	return files, nil
+}
+
+// unionKeys returns the sorted keys present in either map
+func unionKeys(a, b map[string][]byte) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,53 @@
+package generator
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+func TestGolden(t *testing.T) {
+	registry, err := NewTemplateRegistry()
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	RunGoldenTests(t, filepath.Join("testdata", "golden"), registry, *update)
+}
+
+func TestGoldenCase_Compare(t *testing.T) {
+	c := GoldenCase{Name: "case", Dir: t.TempDir()}
+	if err := c.Update(map[string][]byte{"a.txt": []byte("one\ntwo\n"), "gone.txt": []byte("x\n")}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	mismatches, err := c.Compare(map[string][]byte{"a.txt": []byte("one\nthree\n"), "new.txt": []byte("y\n")})
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+
+	var paths []string
+	for _, mismatch := range mismatches {
+		paths = append(paths, mismatch.Path)
+	}
+	if got := strings.Join(paths, ","); got != "a.txt,gone.txt,new.txt" {
+		t.Fatalf("Mismatches = %s, want a.txt,gone.txt,new.txt", got)
+	}
+	if diff := mismatches[0].Diff; !strings.Contains(diff, "-two\n+three\n") || !strings.Contains(diff, "--- golden/a.txt") {
+		t.Errorf("Diff = %q", diff)
+	}
+	if diff := mismatches[2].Diff; !strings.Contains(diff, "--- /dev/null") {
+		t.Errorf("Diff for a new file = %q", diff)
+	}
+
+	// Update removes golden files that are no longer rendered
+	if err := c.Update(map[string][]byte{"a.txt": []byte("one\n")}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(c.Dir, GoldenOutputDir, "gone.txt")); !os.IsNotExist(err) {
+		t.Errorf("Stale golden file survived Update(): %v", err)
+	}
+}
@@ -0,0 +1,449 @@
+package generator
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// LintKind classifies a template lint issue
+type LintKind string
+
+const (
+	// LintParseError is a template that does not parse
+	LintParseError LintKind = "parse-error"
+
+	// LintUndefinedField is a field or method that does not exist on the value it is read from
+	LintUndefinedField LintKind = "undefined-field"
+
+	// LintUnknownFunction is a function that is neither built in nor in TemplateFuncs
+	LintUnknownFunction LintKind = "unknown-function"
+
+	// LintUnusedConditional is an if or with whose outcome is fixed or whose body is empty
+	LintUnusedConditional LintKind = "unused-conditional"
+)
+
+// LintIssue is a problem found in a template
+type LintIssue struct {
+	File    string
+	Line    int
+	Column  int
+	Kind    LintKind
+	Message string
+}
+
+// String formats the issue as file:line:col: message [kind]
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s [%s]", i.File, i.Line, i.Column, i.Message, i.Kind)
+}
+
+// builtinFuncs are the functions text/template provides to every template
+var builtinFuncs = map[string]reflect.Type{
+	"and": nil, "or": nil, "not": reflect.TypeOf(false), "call": nil, "index": nil, "slice": nil,
+	"len": reflect.TypeOf(0), "print": reflect.TypeOf(""), "printf": reflect.TypeOf(""), "println": reflect.TypeOf(""),
+	"html": reflect.TypeOf(""), "js": reflect.TypeOf(""), "urlquery": reflect.TypeOf(""),
+	"eq": reflect.TypeOf(false), "ne": reflect.TypeOf(false), "lt": reflect.TypeOf(false),
+	"le": reflect.TypeOf(false), "gt": reflect.TypeOf(false), "ge": reflect.TypeOf(false),
+}
+
+// staticTemplateExts are the files of a static template tier that are
+// rendered as templates, besides go.mod and README.md
+var staticTemplateExts = map[string]bool{
+	templateExt: true, ".go": true, ".yaml": true, ".yml": true, ".json": true, ".ts": true, ".sh": true,
+}
+
+// parseErrorLine extracts the line number from a text/template parse error
+var parseErrorLine = regexp.MustCompile(`^template: [^:]*:(\d+):`)
+
+// LintSource checks a template against the GenerationContext data model.
+// Every field chain is resolved against the Go types by reflection, so
+// {{.Config.Namespace}} is reported when ProjectConfig has no Namespace.
+// Values whose type is not known statically, such as function results of
+// type interface{}, are not checked further. file names the template in
+// the issues, which are sorted by position.
+func LintSource(file, source string, funcs template.FuncMap) []LintIssue {
+	l := &templateLinter{file: file, source: source, funcs: funcs, visited: make(map[string]bool)}
+
+	tree := parse.New(file)
+	tree.Mode = parse.SkipFuncCheck
+	l.trees = make(map[string]*parse.Tree)
+	if _, err := tree.Parse(source, "", "", l.trees); err != nil {
+		line := 0
+		if match := parseErrorLine.FindStringSubmatch(err.Error()); match != nil {
+			line, _ = strconv.Atoi(match[1])
+		}
+		return []LintIssue{{File: file, Line: line, Column: 1, Kind: LintParseError, Message: err.Error()}}
+	}
+
+	// The file itself runs against the context; templates it defines are
+	// checked with the value they are invoked with, or only for functions
+	// when no invocation has a known type
+	if main := l.trees[file]; main != nil {
+		l.walk(main.Root, contextType, map[string]reflect.Type{"$": contextType})
+	}
+	names := make([]string, 0, len(l.trees))
+	for name := range l.trees {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name != file && !l.invoked[name] {
+			l.walk(l.trees[name].Root, nil, map[string]reflect.Type{"$": nil})
+		}
+	}
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		if l.issues[i].Line != l.issues[j].Line {
+			return l.issues[i].Line < l.issues[j].Line
+		}
+		return l.issues[i].Column < l.issues[j].Column
+	})
+	return l.issues
+}
+
+// LintFS checks every template file below fsys: named templates (*.tmpl)
+// and the files static template tiers render. Files are reported as
+// label/<path>.
+func LintFS(fsys fs.FS, label string, funcs template.FuncMap) ([]LintIssue, error) {
+	var issues []LintIssue
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !isLintableFile(name) {
+			return err
+		}
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		issues = append(issues, LintSource(path.Join(label, name), string(content), funcs)...)
+		return nil
+	})
+	if err != nil {
+		return issues, fmt.Errorf("failed to lint templates in %s: %w", label, err)
+	}
+	return issues, nil
+}
+
+// LintLayers checks the named templates of every layer, including those
+// hidden by a higher layer
+func LintLayers(layers []TemplateLayer, funcs template.FuncMap) ([]LintIssue, error) {
+	var issues []LintIssue
+	for _, layer := range layers {
+		matches, err := fs.Glob(layer.FS, "*"+templateExt)
+		if err != nil {
+			return issues, fmt.Errorf("failed to list templates in %s: %w", layer.Name, err)
+		}
+		for _, match := range matches {
+			content, err := fs.ReadFile(layer.FS, match)
+			if err != nil {
+				return issues, fmt.Errorf("failed to read template %s: %w", match, err)
+			}
+			issues = append(issues, LintSource(path.Join(layer.Name, match), string(content), funcs)...)
+		}
+	}
+	return issues, nil
+}
+
+// isLintableFile reports whether a file is rendered as a template
+func isLintableFile(name string) bool {
+	base := path.Base(name)
+	return staticTemplateExts[path.Ext(name)] || base == "go.mod" || base == "README.md"
+}
+
+// templateLinter checks the trees of one template file
+type templateLinter struct {
+	file    string
+	source  string
+	funcs   template.FuncMap
+	trees   map[string]*parse.Tree
+	visited map[string]bool // defined template and dot type pairs already walked
+	invoked map[string]bool // defined templates walked with a known dot type
+	issues  []LintIssue
+}
+
+// report records an issue at a node
+func (l *templateLinter) report(node parse.Node, kind LintKind, format string, args ...interface{}) {
+	pos := int(node.Position())
+	if pos > len(l.source) {
+		pos = len(l.source)
+	}
+	line := 1 + strings.Count(l.source[:pos], "\n")
+	column := pos - strings.LastIndex(l.source[:pos], "\n")
+	l.issues = append(l.issues, LintIssue{
+		File:    l.file,
+		Line:    line,
+		Column:  column,
+		Kind:    kind,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// walk checks a node with dot of type dot; a nil type is unknown
+func (l *templateLinter) walk(node parse.Node, dot reflect.Type, vars map[string]reflect.Type) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			l.walk(child, dot, vars)
+		}
+	case *parse.ActionNode:
+		l.pipe(n.Pipe, dot, vars)
+	case *parse.IfNode:
+		inner := copyTypes(vars)
+		l.conditional(n, "if", n.Pipe, n.List, n.ElseList, l.pipe(n.Pipe, dot, inner), true)
+		l.walk(n.List, dot, inner)
+		l.walk(n.ElseList, dot, copyTypes(vars))
+	case *parse.WithNode:
+		inner := copyTypes(vars)
+		t := l.pipe(n.Pipe, dot, inner)
+		// A with on a struct is a common way to scope dot; it is only
+		// pointless when it has an else branch that can never run
+		l.conditional(n, "with", n.Pipe, n.List, n.ElseList, t, n.ElseList != nil)
+		l.walk(n.List, t, inner)
+		l.walk(n.ElseList, dot, copyTypes(vars))
+	case *parse.RangeNode:
+		inner := copyTypes(vars)
+		key, elem := rangeTypes(l.pipe(n.Pipe, dot, inner))
+		switch len(n.Pipe.Decl) {
+		case 1:
+			inner[n.Pipe.Decl[0].Ident[0]] = elem
+		case 2:
+			inner[n.Pipe.Decl[0].Ident[0]] = key
+			inner[n.Pipe.Decl[1].Ident[0]] = elem
+		}
+		l.walk(n.List, elem, inner)
+		l.walk(n.ElseList, dot, copyTypes(vars))
+	case *parse.TemplateNode:
+		var t reflect.Type
+		if n.Pipe != nil {
+			t = l.pipe(n.Pipe, dot, vars)
+		}
+		tree, ok := l.trees[n.Name]
+		if !ok || t == nil {
+			return
+		}
+		key := n.Name + "\x00" + t.String()
+		if l.visited[key] {
+			return
+		}
+		l.visited[key] = true
+		if l.invoked == nil {
+			l.invoked = make(map[string]bool)
+		}
+		l.invoked[n.Name] = true
+		l.walk(tree.Root, t, map[string]reflect.Type{"$": t})
+	}
+}
+
+// conditional reports an if or with that cannot change the output: one
+// with an empty body, a constant condition or, when checkStruct is set, a
+// condition on a struct value, which is always true
+func (l *templateLinter) conditional(node parse.Node, keyword string, pipe *parse.PipeNode, list, elseList *parse.ListNode, t reflect.Type, checkStruct bool) {
+	if isEmptyList(list) && isEmptyList(elseList) {
+		l.report(node, LintUnusedConditional, "%s %s has an empty body", keyword, pipe)
+		return
+	}
+
+	if len(pipe.Cmds) == 1 && len(pipe.Cmds[0].Args) == 1 {
+		if b, ok := pipe.Cmds[0].Args[0].(*parse.BoolNode); ok {
+			l.report(node, LintUnusedConditional, "%s condition is always %v", keyword, b.True)
+			return
+		}
+	}
+
+	if checkStruct && t != nil && t.Kind() == reflect.Struct {
+		hint := ""
+		if field, ok := t.FieldByName("Enabled"); ok && field.Type.Kind() == reflect.Bool {
+			hint = fmt.Sprintf("; did you mean %s.Enabled?", pipe)
+		}
+		l.report(node, LintUnusedConditional, "%s %s is always true because %s is a struct%s", keyword, pipe, t, hint)
+	}
+}
+
+// pipe checks a pipeline, binds its declared variables and returns its result type
+func (l *templateLinter) pipe(pipe *parse.PipeNode, dot reflect.Type, vars map[string]reflect.Type) reflect.Type {
+	if pipe == nil {
+		return nil
+	}
+
+	var result reflect.Type
+	for _, cmd := range pipe.Cmds {
+		result = l.command(cmd, dot, vars)
+	}
+	for _, v := range pipe.Decl {
+		vars[v.Ident[0]] = result
+	}
+	return result
+}
+
+// command checks a pipeline command and returns its result type
+func (l *templateLinter) command(cmd *parse.CommandNode, dot reflect.Type, vars map[string]reflect.Type) reflect.Type {
+	if len(cmd.Args) == 0 {
+		return nil
+	}
+	for _, arg := range cmd.Args[1:] {
+		l.arg(arg, dot, vars)
+	}
+
+	if ident, ok := cmd.Args[0].(*parse.IdentifierNode); ok {
+		return l.function(ident)
+	}
+	return l.arg(cmd.Args[0], dot, vars)
+}
+
+// function checks that a function exists and returns its result type
+func (l *templateLinter) function(ident *parse.IdentifierNode) reflect.Type {
+	if fn, ok := l.funcs[ident.Ident]; ok {
+		t := reflect.TypeOf(fn)
+		if t == nil || t.Kind() != reflect.Func || t.NumOut() == 0 {
+			return nil
+		}
+		return concreteType(t.Out(0))
+	}
+	if t, ok := builtinFuncs[ident.Ident]; ok {
+		return t
+	}
+	l.report(ident, LintUnknownFunction, "function %q is not defined", ident.Ident)
+	return nil
+}
+
+// arg checks a command argument and returns its type
+func (l *templateLinter) arg(node parse.Node, dot reflect.Type, vars map[string]reflect.Type) reflect.Type {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return l.chain(n, dot, "", n.Ident)
+	case *parse.VariableNode:
+		return l.chain(n, vars[n.Ident[0]], n.Ident[0], n.Ident[1:])
+	case *parse.ChainNode:
+		base := l.arg(n.Node, dot, vars)
+		return l.chain(n, base, n.Node.String(), n.Field)
+	case *parse.PipeNode:
+		return l.pipe(n, dot, vars)
+	case *parse.IdentifierNode:
+		return l.function(n)
+	case *parse.StringNode:
+		return reflect.TypeOf("")
+	case *parse.BoolNode:
+		return reflect.TypeOf(false)
+	case *parse.NumberNode:
+		if n.IsInt {
+			return reflect.TypeOf(0)
+		}
+		return nil
+	}
+	return nil
+}
+
+// chain resolves field and method names on t, reporting the first one
+// that does not exist, and returns the resulting type
+func (l *templateLinter) chain(node parse.Node, t reflect.Type, prefix string, idents []string) reflect.Type {
+	walked := prefix
+	for _, name := range idents {
+		if t == nil {
+			return nil
+		}
+		next, ok := memberType(t, name)
+		if !ok {
+			l.report(node, LintUndefinedField, "%s.%s is not defined: %s has no field or method %s", walked, name, t, name)
+			return nil
+		}
+		walked += "." + name
+		t = next
+	}
+	return t
+}
+
+// memberType returns the type of the field, method or map entry name on t.
+// A nil type means the member exists but its type is not known statically.
+func memberType(t reflect.Type, name string) (reflect.Type, bool) {
+	receiver := t
+	if t.Kind() != reflect.Pointer && t.Kind() != reflect.Interface {
+		receiver = reflect.PointerTo(t)
+	}
+	if method, ok := receiver.MethodByName(name); ok {
+		if method.Type.NumOut() == 0 {
+			return nil, true
+		}
+		return concreteType(method.Type.Out(0)), true
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		field, ok := t.FieldByName(name)
+		if !ok || !field.IsExported() {
+			return nil, false
+		}
+		return concreteType(field.Type), true
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, false
+		}
+		return concreteType(t.Elem()), true
+	case reflect.Interface:
+		return nil, true
+	}
+	return nil, false
+}
+
+// concreteType returns t, or nil for interface types whose dynamic type is unknown
+func concreteType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Interface {
+		return nil
+	}
+	return t
+}
+
+// rangeTypes returns the key and element types of ranging over t
+func rangeTypes(t reflect.Type) (key, elem reflect.Type) {
+	if t == nil {
+		return nil, nil
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return reflect.TypeOf(0), concreteType(t.Elem())
+	case reflect.Map:
+		return concreteType(t.Key()), concreteType(t.Elem())
+	case reflect.Chan:
+		return concreteType(t.Elem()), concreteType(t.Elem())
+	}
+	return nil, nil
+}
+
+// isEmptyList reports whether a template list renders nothing but whitespace
+func isEmptyList(list *parse.ListNode) bool {
+	if list == nil {
+		return true
+	}
+	for _, node := range list.Nodes {
+		text, ok := node.(*parse.TextNode)
+		if !ok || strings.TrimSpace(string(text.Text)) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// copyTypes returns a copy of a variable scope for a nested block
+func copyTypes(vars map[string]reflect.Type) map[string]reflect.Type {
+	inner := make(map[string]reflect.Type, len(vars))
+	for name, t := range vars {
+		inner[name] = t
+	}
+	return inner
+}
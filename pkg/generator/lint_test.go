@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLintSource(t *testing.T) {
+	source := `module {{.ModuleName}}
+{{.Config.Name | upper}} {{.Config.Tier.Description}} {{len .Config.Kubernetes.Labels}}
+{{- range $name, $value := .Config.Kubernetes.Labels}}{{$name}}={{$value.Missing}}{{end}}
+{{- with .Config.Kubernetes}}{{.Namespace}}{{.Owner}}{{end}}
+{{if .Config.Observability.Metrics}}metrics{{end}}
+{{if .Config.Features.Docker}}  {{end}}
+{{frobnicate .Config.Name}}
+{{range .Config.Dependencies.ExternalServices}}{{.}}{{end}}
+{{$cfg := .Config}}{{$cfg.Kubernetes.Ingress.Hots}}`
+
+	issues := LintSource("test.tmpl", source, TemplateFuncs())
+
+	want := []string{
+		"test.tmpl:1:10: .ModuleName is not defined",
+		"test.tmpl:3:73: $value.Missing is not defined",
+		"test.tmpl:4:46: .Owner is not defined",
+		"test.tmpl:5:6: if .Config.Observability.Metrics is always true because config.MetricsConfig is a struct; did you mean .Config.Observability.Metrics.Enabled?",
+		"test.tmpl:6:6: if .Config.Features.Docker has an empty body",
+		"test.tmpl:7:3: function \"frobnicate\" is not defined",
+		"test.tmpl:9:26: $cfg.Kubernetes.Ingress.Hots is not defined",
+	}
+	if len(issues) != len(want) {
+		for _, issue := range issues {
+			t.Log(issue)
+		}
+		t.Fatalf("Found %d issues, want %d", len(issues), len(want))
+	}
+	for i, issue := range issues {
+		if !strings.HasPrefix(issue.String(), want[i]) {
+			t.Errorf("Issue %d = %s, want prefix %s", i, issue, want[i])
+		}
+	}
+
+	if issues := LintSource("bad.tmpl", "line\n{{.Config.Name", nil); len(issues) != 1 || issues[0].Kind != LintParseError || issues[0].Line != 2 {
+		t.Errorf("Parse error issues = %v", issues)
+	}
+}
+
+func TestLintBuiltinTemplates(t *testing.T) {
+	builtin, err := BuiltinTemplateLayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues, err := LintLayers([]TemplateLayer{builtin}, TemplateFuncs())
+	if err != nil {
+		t.Fatalf("LintLayers() error = %v", err)
+	}
+	for _, issue := range issues {
+		if issue.Kind != LintUnusedConditional {
+			t.Errorf("Built-in template issue: %s", issue)
+		}
+	}
+}
+
+func TestLintFS_ShippedTemplates(t *testing.T) {
+	for _, dir := range []string{"templates", "template-health/templates"} {
+		issues, err := LintFS(os.DirFS(filepath.Join("..", "..", filepath.FromSlash(dir))), dir, TemplateFuncs())
+		if err != nil {
+			t.Fatalf("LintFS(%s) error = %v", dir, err)
+		}
+		for _, issue := range issues {
+			t.Errorf("Shipped template issue: %s", issue)
+		}
+	}
+}
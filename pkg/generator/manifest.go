@@ -0,0 +1,233 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/config"
+)
+
+// ManifestFileName is the name of the manifest written into every generated project
+const ManifestFileName = ".template-metadata.yaml"
+
+// SnapshotDir holds the template sources a project was generated from, keyed by template hash
+const SnapshotDir = ".template-health/snapshots"
+
+// manifestHeader is written above the manifest content
+const manifestHeader = "# Generated by template-health-endpoint. Do not edit by hand.\n"
+
+// Manifest records how a project was generated
+type Manifest struct {
+	Name             string                 `yaml:"name"`
+	Tier             string                 `yaml:"tier"`
+	Module           string                 `yaml:"module"`
+	GeneratorVersion string                 `yaml:"generator_version"`
+	GeneratedAt      string                 `yaml:"generated_at"`
+	Config           *config.ProjectConfig  `yaml:"config"`
+	Vars             map[string]interface{} `yaml:"vars,omitempty"`
+	Files            []ManifestFile         `yaml:"files"`
+}
+
+// ManifestFile records a single generated file
+type ManifestFile struct {
+	Path         string `yaml:"path"`
+	Template     string `yaml:"template"`
+	TemplateHash string `yaml:"template_hash"`
+	SHA256       string `yaml:"sha256"`
+
+	// Environment is the environment of the configuration the file was
+	// rendered for, such as staging for configs/staging.yaml
+	Environment string `yaml:"environment,omitempty"`
+
+	// Fields are the GenerationContext fields the template reads and
+	// InputsHash the hash of their values, for incremental regeneration
+	Fields     []string `yaml:"fields,omitempty"`
+	InputsHash string   `yaml:"inputs_hash,omitempty"`
+}
+
+// FileDrift describes a generated file whose content no longer matches the manifest
+type FileDrift struct {
+	Path     string
+	Expected string
+	Actual   string
+	Missing  bool
+}
+
+// NewManifest creates an empty manifest for the given configuration. The
+// output directory is not recorded, so a project checks clean wherever it
+// is checked out.
+func NewManifest(cfg *config.ProjectConfig, generatedAt string) *Manifest {
+	recorded := *cfg
+	recorded.OutputDir = ""
+	return &Manifest{
+		Name:             cfg.Name,
+		Tier:             cfg.Tier.String(),
+		Module:           cfg.GoModule,
+		GeneratorVersion: GeneratorVersion,
+		GeneratedAt:      generatedAt,
+		Config:           &recorded,
+	}
+}
+
+// Context returns the generation context the manifest's project was rendered with
+func (m *Manifest) Context() *GenerationContext {
+	return &GenerationContext{
+		Config:    m.Config,
+		Timestamp: m.GeneratedAt,
+		Version:   m.GeneratorVersion,
+		Vars:      m.Vars,
+	}
+}
+
+// FileContext returns the generation context a file of the manifest's
+// project was rendered with
+func (m *Manifest) FileContext(file ManifestFile) *GenerationContext {
+	return m.Context().ForEnvironment(file.Environment)
+}
+
+// LoadManifest reads the manifest of the project in dir
+func LoadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ManifestFileName, err)
+	}
+
+	return &manifest, nil
+}
+
+// Encode returns the manifest file content
+func (m *Manifest) Encode() ([]byte, error) {
+	m.sortFiles()
+
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	return append([]byte(manifestHeader), data...), nil
+}
+
+// Save writes the manifest into dir
+func (m *Manifest) Save(dir string) error {
+	data, err := m.Encode()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, ManifestFileName)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// File returns the manifest entry for the given project-relative path
+func (m *Manifest) File(path string) (ManifestFile, bool) {
+	for _, file := range m.Files {
+		if file.Path == path {
+			return file, true
+		}
+	}
+	return ManifestFile{}, false
+}
+
+// Record adds or replaces the entry for a generated file
+func (m *Manifest) Record(file ManifestFile) {
+	for i := range m.Files {
+		if m.Files[i].Path == file.Path {
+			m.Files[i] = file
+			return
+		}
+	}
+	m.Files = append(m.Files, file)
+}
+
+// Remove deletes the entry for the given project-relative path
+func (m *Manifest) Remove(path string) {
+	for i := range m.Files {
+		if m.Files[i].Path == path {
+			m.Files = append(m.Files[:i], m.Files[i+1:]...)
+			return
+		}
+	}
+}
+
+// Drift compares the files on disk under dir with the recorded checksums
+// and returns every file that was modified or deleted since generation
+func (m *Manifest) Drift(dir string) ([]FileDrift, error) {
+	var drift []FileDrift
+
+	for _, file := range m.Files {
+		data, err := os.ReadFile(filepath.Join(dir, file.Path))
+		if errors.Is(err, fs.ErrNotExist) {
+			drift = append(drift, FileDrift{Path: file.Path, Expected: file.SHA256, Missing: true})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Path, err)
+		}
+
+		if actual := Checksum(data); actual != file.SHA256 {
+			drift = append(drift, FileDrift{Path: file.Path, Expected: file.SHA256, Actual: actual})
+		}
+	}
+
+	return drift, nil
+}
+
+// sortFiles orders the file entries by path so the manifest is stable
+func (m *Manifest) sortFiles() {
+	sort.Slice(m.Files, func(i, j int) bool {
+		return m.Files[i].Path < m.Files[j].Path
+	})
+}
+
+// SnapshotPath returns the project-relative path of a template snapshot
+func SnapshotPath(templateHash string) string {
+	return path.Join(SnapshotDir, templateHash+templateExt)
+}
+
+// SaveTemplateSnapshot stores a template source in the project's snapshot directory
+func SaveTemplateSnapshot(projectDir, templateHash, source string) error {
+	dir := filepath.Join(projectDir, filepath.FromSlash(SnapshotDir))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory %s: %w", dir, err)
+	}
+
+	path := filepath.Join(projectDir, filepath.FromSlash(SnapshotPath(templateHash)))
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		return fmt.Errorf("failed to write template snapshot %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// LoadTemplateSnapshot reads a template source from the project's snapshot directory
+func LoadTemplateSnapshot(projectDir, templateHash string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(projectDir, filepath.FromSlash(SnapshotPath(templateHash))))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Checksum returns the hex-encoded SHA-256 of data
+func Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
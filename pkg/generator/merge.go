@@ -0,0 +1,162 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConflictStyle controls how unresolved merge conflicts are written
+type ConflictStyle string
+
+const (
+	// ConflictMarkers writes git-style <<<<<<< / ======= / >>>>>>> markers into the file
+	ConflictMarkers ConflictStyle = "markers"
+
+	// ConflictReject keeps the local version in the file and writes the
+	// rejected template hunks to a .rej file next to it
+	ConflictReject ConflictStyle = "rej"
+)
+
+// IsValid checks if the conflict style is a valid option
+func (s ConflictStyle) IsValid() bool {
+	return s == ConflictMarkers || s == ConflictReject
+}
+
+// MergeResult is the outcome of a three-way merge
+type MergeResult struct {
+	// Content is the merged file content
+	Content string
+
+	// Conflicts is the number of regions changed on both sides in different ways
+	Conflicts int
+
+	// Rejects holds the rejected template hunks in unified diff form when
+	// the merge used ConflictReject and had conflicts
+	Rejects string
+}
+
+// HasConflicts reports whether the merge left unresolved conflicts
+func (r *MergeResult) HasConflicts() bool {
+	return r.Conflicts > 0
+}
+
+// MergeLabels names the three inputs of a merge in conflict markers and reject files
+type MergeLabels struct {
+	Base   string
+	Ours   string
+	Theirs string
+}
+
+// Merge3 merges the changes from base to theirs into ours.
+// Regions changed on only one side take that side's version; regions
+// changed identically on both sides are taken once; anything else is a
+// conflict written according to style.
+func Merge3(base, ours, theirs string, labels MergeLabels, style ConflictStyle) *MergeResult {
+	baseLines := splitLines(base)
+	ourLines := splitLines(ours)
+	theirLines := splitLines(theirs)
+
+	ourMatch := matchLines(baseLines, ourLines)
+	theirMatch := matchLines(baseLines, theirLines)
+
+	result := &MergeResult{}
+	var out strings.Builder
+	var rejects strings.Builder
+
+	i, j, k := 0, 0, 0
+	for i < len(baseLines) || j < len(ourLines) || k < len(theirLines) {
+		// Stable line: unchanged on both sides
+		if i < len(baseLines) && ourMatch[i] == j && theirMatch[i] == k {
+			out.WriteString(baseLines[i])
+			i, j, k = i+1, j+1, k+1
+			continue
+		}
+
+		// Find the next line that is unchanged on both sides
+		nextI, nextJ, nextK := len(baseLines), len(ourLines), len(theirLines)
+		for s := i; s < len(baseLines); s++ {
+			if ourMatch[s] >= j && theirMatch[s] >= k {
+				nextI, nextJ, nextK = s, ourMatch[s], theirMatch[s]
+				break
+			}
+		}
+
+		baseChunk := strings.Join(baseLines[i:nextI], "")
+		ourChunk := strings.Join(ourLines[j:nextJ], "")
+		theirChunk := strings.Join(theirLines[k:nextK], "")
+
+		switch {
+		case ourChunk == baseChunk:
+			out.WriteString(theirChunk)
+		case theirChunk == baseChunk, ourChunk == theirChunk:
+			out.WriteString(ourChunk)
+		default:
+			result.Conflicts++
+			if style == ConflictReject {
+				out.WriteString(ourChunk)
+				writeRejectHunk(&rejects, i, baseChunk, theirChunk)
+			} else {
+				writeConflict(&out, ourChunk, theirChunk, labels)
+			}
+		}
+
+		i, j, k = nextI, nextJ, nextK
+	}
+
+	result.Content = out.String()
+	if rejects.Len() > 0 {
+		result.Rejects = fmt.Sprintf("--- %s\n+++ %s\n", labels.Base, labels.Theirs) + rejects.String()
+	}
+
+	return result
+}
+
+// matchLines maps every line of base to the index of the same line in
+// other, or -1 if the line was changed or removed
+func matchLines(base, other []string) []int {
+	match := make([]int, len(base))
+	for i := range match {
+		match[i] = -1
+	}
+
+	for _, op := range diffLines(base, other) {
+		if op.Kind == diffEqual {
+			match[op.A] = op.B
+		}
+	}
+
+	return match
+}
+
+// writeConflict writes a git-style conflict block
+func writeConflict(out *strings.Builder, ours, theirs string, labels MergeLabels) {
+	fmt.Fprintf(out, "<<<<<<< %s\n", labels.Ours)
+	writeTerminated(out, ours)
+	out.WriteString("=======\n")
+	writeTerminated(out, theirs)
+	fmt.Fprintf(out, ">>>>>>> %s\n", labels.Theirs)
+}
+
+// writeRejectHunk writes the base-to-theirs change of a conflicting region as a unified diff hunk
+func writeRejectHunk(out *strings.Builder, baseStart int, base, theirs string) {
+	baseLines := splitLines(base)
+	theirLines := splitLines(theirs)
+
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(baseStart, len(baseLines)), hunkRange(baseStart, len(theirLines)))
+	var sb strings.Builder
+	for _, line := range baseLines {
+		writeDiffLine(&sb, '-', line)
+	}
+	for _, line := range theirLines {
+		writeDiffLine(&sb, '+', line)
+	}
+	out.WriteString(sb.String())
+}
+
+// writeTerminated writes text and makes sure it ends with a newline
+func writeTerminated(out *strings.Builder, text string) {
+	out.WriteString(text)
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		out.WriteString("\n")
+	}
+}
@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+var testLabels = MergeLabels{Base: "base", Ours: "local", Theirs: "template"}
+
+func TestMerge3(t *testing.T) {
+	base := "package handlers\n\nfunc A() {}\n\nfunc B() {}\n"
+
+	tests := []struct {
+		name          string
+		ours          string
+		theirs        string
+		want          string
+		wantConflicts int
+	}{
+		{
+			name:   "unchanged on both sides",
+			ours:   base,
+			theirs: base,
+			want:   base,
+		},
+		{
+			name:   "template change only",
+			ours:   base,
+			theirs: "package handlers\n\nfunc A() {}\n\nfunc B() { b() }\n",
+			want:   "package handlers\n\nfunc A() {}\n\nfunc B() { b() }\n",
+		},
+		{
+			name:   "local change only",
+			ours:   "package handlers\n\nfunc A() { custom() }\n\nfunc B() {}\n",
+			theirs: base,
+			want:   "package handlers\n\nfunc A() { custom() }\n\nfunc B() {}\n",
+		},
+		{
+			name:   "non-overlapping changes",
+			ours:   "package handlers\n\nfunc A() { custom() }\n\nfunc B() {}\n",
+			theirs: "package handlers\n\nfunc A() {}\n\nfunc B() { b() }\n\nfunc C() {}\n",
+			want:   "package handlers\n\nfunc A() { custom() }\n\nfunc B() { b() }\n\nfunc C() {}\n",
+		},
+		{
+			name:   "identical change on both sides",
+			ours:   "package handlers\n\nfunc A() { same() }\n\nfunc B() {}\n",
+			theirs: "package handlers\n\nfunc A() { same() }\n\nfunc B() {}\n",
+			want:   "package handlers\n\nfunc A() { same() }\n\nfunc B() {}\n",
+		},
+		{
+			name:          "overlapping changes",
+			ours:          "package handlers\n\nfunc A() { mine() }\n\nfunc B() {}\n",
+			theirs:        "package handlers\n\nfunc A() { theirs() }\n\nfunc B() {}\n",
+			want:          "package handlers\n\n<<<<<<< local\nfunc A() { mine() }\n=======\nfunc A() { theirs() }\n>>>>>>> template\n\nfunc B() {}\n",
+			wantConflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Merge3(base, tt.ours, tt.theirs, testLabels, ConflictMarkers)
+			if result.Content != tt.want {
+				t.Errorf("Merge3() content =\n%s\nwant\n%s", result.Content, tt.want)
+			}
+			if result.Conflicts != tt.wantConflicts {
+				t.Errorf("Merge3() conflicts = %d, want %d", result.Conflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
+
+func TestMerge3_RejectStyle(t *testing.T) {
+	base := "a\nb\nc\n"
+	ours := "a\nmine\nc\n"
+	theirs := "a\ntheirs\nc\n"
+
+	result := Merge3(base, ours, theirs, testLabels, ConflictReject)
+	if result.Content != ours {
+		t.Errorf("Reject style should keep the local version, got:\n%s", result.Content)
+	}
+	if !strings.Contains(result.Rejects, "@@ -2 +2 @@\n-b\n+theirs\n") {
+		t.Errorf("Unexpected reject hunk:\n%s", result.Rejects)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	oldText := "one\ntwo\nthree\n"
+	newText := "one\n2\nthree\nfour\n"
+
+	want := "--- a\n+++ b\n@@ -1,3 +1,4 @@\n one\n-two\n+2\n three\n+four\n"
+	if got := UnifiedDiff("a", "b", oldText, newText); got != want {
+		t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := UnifiedDiff("a", "b", oldText, oldText); got != "" {
+		t.Errorf("UnifiedDiff() of identical texts = %q, want empty", got)
+	}
+}
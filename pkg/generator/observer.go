@@ -0,0 +1,218 @@
+package generator
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os/user"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/domain"
+)
+
+// Observer receives the domain events a Generator publishes while it runs:
+// GenerationStarted, GenerationProgress, GenerationArtifactCreated and one
+// of GenerationCompleted, GenerationFailed or GenerationCancelled. Events
+// are delivered one at a time, in order, from the generating goroutines.
+type Observer interface {
+	OnEvent(event domain.DomainEvent)
+}
+
+// ObserverFunc adapts a function to the Observer interface
+type ObserverFunc func(event domain.DomainEvent)
+
+// OnEvent calls f(event)
+func (f ObserverFunc) OnEvent(event domain.DomainEvent) {
+	f(event)
+}
+
+// Error codes of GenerationFailed events
+const (
+	ErrorCodeOutputNotEmpty   = "output_not_empty"
+	ErrorCodeInvalidArtifact  = "invalid_artifact"
+	ErrorCodeTimeout          = "timeout"
+	ErrorCodeGenerationFailed = "generation_failed"
+)
+
+// AddObserver registers an observer for the events of every later run
+func (g *Generator) AddObserver(observer Observer) {
+	g.eventMu.Lock()
+	defer g.eventMu.Unlock()
+	g.observers = append(g.observers, observer)
+}
+
+// startRun creates the generation aggregate for a run and publishes its start
+func (g *Generator) startRun() {
+	g.eventMu.Lock()
+	defer g.eventMu.Unlock()
+
+	generation, err := domain.NewGeneration(newGenerationID(), g.config.Name, requestedBy())
+	if err != nil {
+		// Only possible without a project name, which Generate rejects later
+		g.generation = nil
+		return
+	}
+	generation.Start()
+	g.generation = generation
+	g.publishLocked()
+}
+
+// finishRun marks the generation of the current run as completed, failed or cancelled
+func (g *Generator) finishRun(err error) {
+	g.eventMu.Lock()
+	defer g.eventMu.Unlock()
+
+	if g.generation == nil {
+		return
+	}
+
+	var artifactErr *ArtifactError
+	switch {
+	case err == nil:
+		g.generation.Complete()
+	case errors.Is(err, context.Canceled):
+		g.generation.Cancel()
+	case errors.Is(err, ErrOutputNotEmpty):
+		g.generation.Fail(domain.NewGenerationError(ErrorCodeOutputNotEmpty, err.Error(), nil))
+	case errors.As(err, &artifactErr):
+		g.generation.Fail(domain.NewGenerationError(ErrorCodeInvalidArtifact, err.Error(), nil))
+	case errors.Is(err, context.DeadlineExceeded):
+		g.generation.Fail(domain.NewGenerationError(ErrorCodeTimeout, err.Error(), nil))
+	default:
+		g.generation.Fail(domain.NewGenerationError(ErrorCodeGenerationFailed, err.Error(), nil))
+	}
+	g.publishLocked()
+}
+
+// reportProgress publishes a progress event for the current run
+func (g *Generator) reportProgress(message string, completed, total int) {
+	g.eventMu.Lock()
+	defer g.eventMu.Unlock()
+
+	if g.generation == nil {
+		return
+	}
+	g.generation.ReportProgress(message, completed, total)
+	g.publishLocked()
+}
+
+// reportArtifact publishes an artifact event for a written project file
+func (g *Generator) reportArtifact(filename string, content []byte) {
+	g.eventMu.Lock()
+	defer g.eventMu.Unlock()
+
+	if g.generation == nil {
+		return
+	}
+	mimeType := mime.TypeByExtension(path.Ext(filename))
+	if mimeType == "" {
+		mimeType = "text/plain"
+	}
+	g.generation.AddArtifact(domain.NewGenerationArtifact(filename, int64(len(content)), Checksum(content), mimeType))
+	g.publishLocked()
+}
+
+// artifactCount returns the number of files written by the current run
+func (g *Generator) artifactCount() int {
+	g.eventMu.Lock()
+	defer g.eventMu.Unlock()
+
+	if g.generation == nil {
+		return 0
+	}
+	return len(g.generation.Artifacts())
+}
+
+// publishLocked hands the pending events of the generation to every observer.
+// The caller must hold g.eventMu.
+func (g *Generator) publishLocked() {
+	events := g.generation.DomainEvents()
+	g.generation.ClearDomainEvents()
+
+	for _, event := range events {
+		for _, observer := range g.observers {
+			observer.OnEvent(event)
+		}
+	}
+}
+
+// newGenerationID returns a random identifier for a generation run
+func newGenerationID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("gen-%d", time.Now().UnixNano())
+	}
+	return "gen-" + hex.EncodeToString(b)
+}
+
+// requestedBy names the user running the generator
+func requestedBy() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return "template-health-endpoint"
+}
+
+// JSONLinesObserver writes every event as one JSON object per line. Each
+// object has "type", "time" and "generationId" keys plus the event's own
+// fields, for example:
+//
+//	{"artifactPath":"go.mod","artifactSize":412,"generationId":"gen-1f2e3d4c5b6a7980","time":"2024-01-02T03:04:05Z","type":"GenerationArtifactCreated"}
+type JSONLinesObserver struct {
+	mu  sync.Mutex
+	w   io.Writer
+	err error
+}
+
+// NewJSONLinesObserver creates an observer that streams events to w
+func NewJSONLinesObserver(w io.Writer) *JSONLinesObserver {
+	return &JSONLinesObserver{w: w}
+}
+
+// OnEvent writes the event as a JSON line
+func (o *JSONLinesObserver) OnEvent(event domain.DomainEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.err != nil {
+		return
+	}
+
+	line, err := EncodeEvent(event)
+	if err == nil {
+		_, err = o.w.Write(append(line, '\n'))
+	}
+	o.err = err
+}
+
+// Err returns the first error that occurred while writing events
+func (o *JSONLinesObserver) Err() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.err
+}
+
+// EncodeEvent returns the JSON form of an event used by JSONLinesObserver
+func EncodeEvent(event domain.DomainEvent) ([]byte, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", event.EventType(), err)
+	}
+
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", event.EventType(), err)
+	}
+	fields["type"] = event.EventType()
+	fields["time"] = event.OccurredAt().UTC().Format(time.RFC3339Nano)
+	fields["generationId"] = event.AggregateID()
+
+	return json.Marshal(fields)
+}
@@ -0,0 +1,104 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/config"
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/domain"
+)
+
+func TestGenerator_Observer(t *testing.T) {
+	for _, parallel := range []bool{true, false} {
+		config := &config.ProjectConfig{
+			Name:        "observer-test",
+			Description: "Test generation events",
+			GoModule:    "github.com/example/observer-test",
+			Tier:        config.TierIntermediate,
+			Version:     "1.0.0",
+			OutputDir:   "test-observer",
+			Features: config.FeatureConfig{
+				Kubernetes: true,
+				TypeScript: true,
+			},
+		}
+
+		generator, err := New(config)
+		if err != nil {
+			t.Fatalf("Failed to create generator: %v", err)
+		}
+		generator.enableParallel = parallel
+
+		var events []domain.DomainEvent
+		generator.AddObserver(ObserverFunc(func(event domain.DomainEvent) {
+			events = append(events, event)
+		}))
+		generator.SetOutput(NewMemoryOutput())
+
+		if err := generator.Generate(); err != nil {
+			t.Fatalf("Failed to generate project: %v", err)
+		}
+
+		if _, ok := events[0].(*domain.GenerationStartedEvent); !ok {
+			t.Errorf("First event = %s, want GenerationStarted", events[0].EventType())
+		}
+		completed, ok := events[len(events)-1].(*domain.GenerationCompletedEvent)
+		if !ok {
+			t.Fatalf("Last event = %s, want GenerationCompleted", events[len(events)-1].EventType())
+		}
+
+		artifacts := 0
+		for _, event := range events {
+			switch e := event.(type) {
+			case *domain.GenerationArtifactCreatedEvent:
+				artifacts++
+				if e.ArtifactSize == 0 {
+					t.Errorf("Artifact %s has no size", e.ArtifactPath)
+				}
+			case *domain.GenerationProgressEvent:
+				if e.Total > 0 && e.Total != len(generator.Manifest().Files) {
+					t.Errorf("parallel=%v: progress total = %d, want %d", parallel, e.Total, len(generator.Manifest().Files))
+				}
+			}
+		}
+		if artifacts != completed.ArtifactCount || artifacts != len(generator.Manifest().Files) {
+			t.Errorf("parallel=%v: %d artifact events, completed count %d, manifest has %d files",
+				parallel, artifacts, completed.ArtifactCount, len(generator.Manifest().Files))
+		}
+	}
+}
+
+func TestJSONLinesObserver(t *testing.T) {
+	var buf bytes.Buffer
+	observer := NewJSONLinesObserver(&buf)
+	observer.OnEvent(domain.NewGenerationArtifactCreatedEvent("gen-1", "go.mod", 42))
+	observer.OnEvent(domain.NewGenerationFailedEvent("gen-1", ErrorCodeTimeout, "took too long"))
+	if err := observer.Err(); err != nil {
+		t.Fatalf("OnEvent() error = %v", err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %s", len(lines), buf.String())
+	}
+
+	var artifact map[string]interface{}
+	if err := json.Unmarshal(lines[0], &artifact); err != nil {
+		t.Fatalf("Line is not JSON: %v", err)
+	}
+	want := map[string]interface{}{
+		"type":         "GenerationArtifactCreated",
+		"generationId": "gen-1",
+		"artifactPath": "go.mod",
+		"artifactSize": float64(42),
+	}
+	for key, value := range want {
+		if artifact[key] != value {
+			t.Errorf("%s = %v, want %v", key, artifact[key], value)
+		}
+	}
+	if _, ok := artifact["time"]; !ok {
+		t.Error("Event has no time")
+	}
+}
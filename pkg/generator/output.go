@@ -0,0 +1,296 @@
+package generator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing/fstest"
+	"time"
+)
+
+// OutputFS is where the generator writes a project. Names are
+// slash-separated paths relative to the project root.
+type OutputFS interface {
+	// MkdirAll creates a directory and any missing parents
+	MkdirAll(name string, perm fs.FileMode) error
+
+	// WriteFile creates or replaces a file, creating parent directories as needed
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+}
+
+// ArchiveFormat identifies the archive type written by an archive output
+type ArchiveFormat string
+
+const (
+	// ArchiveTarGz writes a gzip-compressed tar archive
+	ArchiveTarGz ArchiveFormat = "tgz"
+
+	// ArchiveZip writes a zip archive
+	ArchiveZip ArchiveFormat = "zip"
+)
+
+// ArchiveFormatFromName picks the archive format from a file name's extension
+func ArchiveFormatFromName(name string) (ArchiveFormat, error) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".tgz"), strings.HasSuffix(lower, ".tar.gz"):
+		return ArchiveTarGz, nil
+	case strings.HasSuffix(lower, ".zip"):
+		return ArchiveZip, nil
+	}
+	return "", fmt.Errorf("cannot determine archive format of %s (use .tgz, .tar.gz or .zip)", name)
+}
+
+// DirOutput writes a project into a directory on disk
+type DirOutput struct {
+	root string
+}
+
+// NewDirOutput creates an output that writes below root
+func NewDirOutput(root string) *DirOutput {
+	return &DirOutput{root: root}
+}
+
+// MkdirAll creates a directory below the root
+func (d *DirOutput) MkdirAll(name string, perm fs.FileMode) error {
+	dir := filepath.Join(d.root, filepath.FromSlash(name))
+	if err := os.MkdirAll(dir, perm); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	return nil
+}
+
+// WriteFile writes a file below the root
+func (d *DirOutput) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	fullPath := filepath.Join(d.root, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(fullPath), err)
+	}
+	if err := os.WriteFile(fullPath, data, perm); err != nil {
+		return fmt.Errorf("failed to create file %s: %w", fullPath, err)
+	}
+	return nil
+}
+
+// memoryFile is a file or directory held by MemoryOutput
+type memoryFile struct {
+	data  []byte
+	mode  fs.FileMode
+	isDir bool
+}
+
+// MemoryOutput keeps a generated project in memory. It is safe for
+// concurrent use and exposes the result as an fs.FS.
+type MemoryOutput struct {
+	mu    sync.Mutex
+	files map[string]memoryFile
+}
+
+// NewMemoryOutput creates an empty in-memory output
+func NewMemoryOutput() *MemoryOutput {
+	return &MemoryOutput{files: make(map[string]memoryFile)}
+}
+
+// MkdirAll records a directory and its parents
+func (m *MemoryOutput) MkdirAll(name string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for dir := path.Clean(name); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if _, exists := m.files[dir]; !exists {
+			m.files[dir] = memoryFile{mode: fs.ModeDir | perm, isDir: true}
+		}
+	}
+	return nil
+}
+
+// WriteFile stores a copy of data under name
+func (m *MemoryOutput) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	name = path.Clean(name)
+	if err := m.MkdirAll(path.Dir(name), 0755); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[name] = memoryFile{data: append([]byte(nil), data...), mode: perm}
+	return nil
+}
+
+// ReadFile returns the content of a written file
+func (m *MemoryOutput) ReadFile(name string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, exists := m.files[path.Clean(name)]
+	if !exists || file.isDir {
+		return nil, false
+	}
+	return file.data, true
+}
+
+// Files returns the sorted names of all written files
+func (m *MemoryOutput) Files() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.files))
+	for name, file := range m.files {
+		if !file.isDir {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FS returns a read-only snapshot of the output
+func (m *MemoryOutput) FS() fs.FS {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(fstest.MapFS, len(m.files))
+	for name, file := range m.files {
+		snapshot[name] = &fstest.MapFile{Data: file.data, Mode: file.mode}
+	}
+	return snapshot
+}
+
+// entries returns every file and directory sorted by name
+func (m *MemoryOutput) entries() ([]string, map[string]memoryFile) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.files))
+	files := make(map[string]memoryFile, len(m.files))
+	for name, file := range m.files {
+		names = append(names, name)
+		files[name] = file
+	}
+	sort.Strings(names)
+	return names, files
+}
+
+// ArchiveOutput collects a project in memory and streams it as a tar.gz or
+// zip archive on Close. Entries are written in sorted order below an
+// optional root directory, so the archive does not depend on the order in
+// which files were generated.
+type ArchiveOutput struct {
+	*MemoryOutput
+	w       io.Writer
+	format  ArchiveFormat
+	root    string
+	modTime time.Time
+}
+
+// NewArchiveOutput creates an archive output that writes to w on Close.
+// Every entry is placed below root unless root is empty.
+func NewArchiveOutput(w io.Writer, format ArchiveFormat, root string, modTime time.Time) *ArchiveOutput {
+	return &ArchiveOutput{
+		MemoryOutput: NewMemoryOutput(),
+		w:            w,
+		format:       format,
+		root:         root,
+		modTime:      modTime,
+	}
+}
+
+// Close writes the archive. It does not close the underlying writer.
+func (a *ArchiveOutput) Close() error {
+	switch a.format {
+	case ArchiveTarGz:
+		return a.writeTarGz()
+	case ArchiveZip:
+		return a.writeZip()
+	}
+	return fmt.Errorf("unsupported archive format '%s'", a.format)
+}
+
+// entryName returns the archive path of a project file
+func (a *ArchiveOutput) entryName(name string) string {
+	if a.root == "" {
+		return name
+	}
+	return path.Join(a.root, name)
+}
+
+// writeTarGz writes the collected files as a gzip-compressed tar archive
+func (a *ArchiveOutput) writeTarGz() error {
+	gz := gzip.NewWriter(a.w)
+	tw := tar.NewWriter(gz)
+
+	names, files := a.entries()
+	for _, name := range names {
+		file := files[name]
+		header := &tar.Header{
+			Name:    a.entryName(name),
+			Mode:    int64(file.mode.Perm()),
+			ModTime: a.modTime,
+			Format:  tar.FormatPAX,
+		}
+		if file.isDir {
+			header.Typeflag = tar.TypeDir
+			header.Name += "/"
+		} else {
+			header.Typeflag = tar.TypeReg
+			header.Size = int64(len(file.data))
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write archive entry %s: %w", header.Name, err)
+		}
+		if _, err := tw.Write(file.data); err != nil {
+			return fmt.Errorf("failed to write archive entry %s: %w", header.Name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish tar archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finish gzip stream: %w", err)
+	}
+	return nil
+}
+
+// writeZip writes the collected files as a zip archive
+func (a *ArchiveOutput) writeZip() error {
+	zw := zip.NewWriter(a.w)
+
+	names, files := a.entries()
+	for _, name := range names {
+		file := files[name]
+		header := &zip.FileHeader{
+			Name:     a.entryName(name),
+			Method:   zip.Deflate,
+			Modified: a.modTime,
+		}
+		header.SetMode(file.mode)
+		if file.isDir {
+			header.Name += "/"
+			header.Method = zip.Store
+		}
+
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("failed to write archive entry %s: %w", header.Name, err)
+		}
+		if _, err := w.Write(file.data); err != nil {
+			return fmt.Errorf("failed to write archive entry %s: %w", header.Name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish zip archive: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package generator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/config"
+)
+
+func TestGenerator_MemoryOutput(t *testing.T) {
+	config := &config.ProjectConfig{
+		Name:        "memory-test",
+		Description: "Test in-memory output",
+		GoModule:    "github.com/example/memory-test",
+		Tier:        config.TierBasic,
+		Version:     "1.0.0",
+		OutputDir:   "test-memory-output",
+	}
+
+	generator, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	out := NewMemoryOutput()
+	generator.SetOutput(out)
+	if err := generator.Generate(); err != nil {
+		t.Fatalf("Failed to generate project: %v", err)
+	}
+
+	if _, err := os.Stat(config.OutputDir); !os.IsNotExist(err) {
+		t.Error("Generating into memory touched the output directory")
+	}
+
+	for _, name := range []string{"go.mod", "cmd/server/main.go", ManifestFileName} {
+		if _, ok := out.ReadFile(name); !ok {
+			t.Errorf("Expected %s in memory output", name)
+		}
+	}
+
+	// Every manifest entry is in the output with a matching checksum
+	for _, file := range generator.Manifest().Files {
+		data, ok := out.ReadFile(file.Path)
+		if !ok {
+			t.Errorf("Manifest lists %s, which was not written", file.Path)
+			continue
+		}
+		if Checksum(data) != file.SHA256 {
+			t.Errorf("Checksum mismatch for %s", file.Path)
+		}
+		if _, ok := out.ReadFile(SnapshotPath(file.TemplateHash)); !ok {
+			t.Errorf("Missing template snapshot for %s", file.Path)
+		}
+	}
+}
+
+func TestArchiveOutput(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	files := map[string]string{
+		"go.mod":             "module example\n",
+		"cmd/server/main.go": "package main\n",
+	}
+
+	for _, format := range []ArchiveFormat{ArchiveTarGz, ArchiveZip} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			out := NewArchiveOutput(&buf, format, "svc", modTime)
+			for name, content := range files {
+				if err := out.WriteFile(name, []byte(content), 0644); err != nil {
+					t.Fatalf("WriteFile(%s) error = %v", name, err)
+				}
+			}
+			if err := out.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			got := readArchive(t, format, buf.Bytes())
+			for name, content := range files {
+				if got["svc/"+name] != content {
+					t.Errorf("Archive entry svc/%s = %q, want %q", name, got["svc/"+name], content)
+				}
+			}
+			if _, ok := got["svc/cmd/server/"]; !ok {
+				t.Error("Archive is missing the directory entry svc/cmd/server/")
+			}
+		})
+	}
+}
+
+func TestArchiveFormatFromName(t *testing.T) {
+	tests := map[string]ArchiveFormat{
+		"out.tgz":    ArchiveTarGz,
+		"out.TAR.GZ": ArchiveTarGz,
+		"out.zip":    ArchiveZip,
+	}
+	for name, want := range tests {
+		if got, err := ArchiveFormatFromName(name); err != nil || got != want {
+			t.Errorf("ArchiveFormatFromName(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+
+	if _, err := ArchiveFormatFromName("out.tar"); err == nil {
+		t.Error("Expected an error for an unknown extension")
+	}
+}
+
+// readArchive returns every entry of an archive keyed by name; directories map to ""
+func readArchive(t *testing.T, format ArchiveFormat, data []byte) map[string]string {
+	t.Helper()
+	entries := make(map[string]string)
+
+	if format == ArchiveZip {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("Failed to open zip archive: %v", err)
+		}
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatalf("Failed to open %s: %v", f.Name, err)
+			}
+			content, _ := io.ReadAll(rc)
+			rc.Close()
+			entries[f.Name] = string(content)
+		}
+		return entries
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to open gzip stream: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read tar archive: %v", err)
+		}
+		content, _ := io.ReadAll(tr)
+		entries[header.Name] = string(content)
+	}
+	return entries
+}
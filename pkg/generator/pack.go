@@ -0,0 +1,464 @@
+package generator
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/config"
+)
+
+const (
+	// PackManifestFile is the manifest of a template pack, listing its files and their checksums
+	PackManifestFile = "MANIFEST.yaml"
+
+	// PackSignatureFile holds the base64 ed25519 signature of the manifest
+	PackSignatureFile = "MANIFEST.sig"
+
+	// PackExt is the file extension of template packs
+	PackExt = ".tgz"
+
+	// packFilesDir is the directory of a pack archive holding the template files
+	packFilesDir = "files"
+
+	// maxPackSize bounds the uncompressed size of a pack that is read
+	maxPackSize = 64 << 20
+)
+
+// userPackDir is the directory installed packs live in, relative to the home directory
+const userPackDir = ".template-health-endpoint/packs"
+
+// ErrUntrustedPack is returned for a pack whose signature does not verify with any trusted key
+var ErrUntrustedPack = errors.New("pack is not signed by a trusted key")
+
+var (
+	packName    = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	packVersion = regexp.MustCompile(`^v?[0-9]+\.[0-9]+\.[0-9]+([-+][0-9A-Za-z.+-]+)?$`)
+)
+
+// PackManifest describes a template pack. It is signed as written, so
+// the checksums tie every file to the signature.
+type PackManifest struct {
+	Name        string     `yaml:"name"`
+	Version     string     `yaml:"version"`
+	Description string     `yaml:"description,omitempty"`
+	KeyID       string     `yaml:"key_id"`
+	Variables   []string   `yaml:"variables,omitempty"`
+	Files       []PackFile `yaml:"files"`
+}
+
+// PackFile is a file of a template pack
+type PackFile struct {
+	Path   string `yaml:"path"`
+	SHA256 string `yaml:"sha256"`
+	Size   int64  `yaml:"size"`
+}
+
+// FileName returns the conventional file name of the pack, <name>-<version>.tgz
+func (m *PackManifest) FileName() string {
+	return m.Name + "-" + m.Version + PackExt
+}
+
+// TemplatePack is a verified template pack read from an archive
+type TemplatePack struct {
+	Manifest  PackManifest
+	manifest  []byte
+	signature []byte
+	files     map[string][]byte
+}
+
+// WritePack bundles a template directory into a pack archive signed with
+// key. The directory holds a template.yaml naming and versioning the pack
+// and declaring its variables, and the template files; named templates
+// such as go-server.tmpl at its top level override the built-in ones once
+// the pack is installed. Hidden directories such as .git are left out.
+func WritePack(w io.Writer, dir string, key ed25519.PrivateKey) (*PackManifest, error) {
+	templateConfig, err := config.LoadTemplateConfig(filepath.Join(dir, config.TemplateConfigFile))
+	if err != nil {
+		return nil, err
+	}
+	if !packName.MatchString(templateConfig.Name) {
+		return nil, fmt.Errorf("invalid pack name %q in %s (must be letters, digits, dots, dashes or underscores)", templateConfig.Name, config.TemplateConfigFile)
+	}
+	if !packVersion.MatchString(templateConfig.Version) {
+		return nil, fmt.Errorf("invalid pack version %q in %s (must be a semantic version such as 1.2.0)", templateConfig.Version, config.TemplateConfigFile)
+	}
+
+	files := make(map[string][]byte)
+	err = filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return fmt.Errorf("%s is not a regular file", name)
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == PackManifestFile || rel == PackSignatureFile {
+			return fmt.Errorf("%s is reserved for the pack manifest", rel)
+		}
+		files[rel], err = os.ReadFile(name)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template directory: %w", err)
+	}
+
+	manifest := PackManifest{
+		Name:        templateConfig.Name,
+		Version:     templateConfig.Version,
+		Description: templateConfig.Description,
+		KeyID:       PackKeyID(key.Public().(ed25519.PublicKey)),
+	}
+	for _, spec := range templateConfig.Variables {
+		manifest.Variables = append(manifest.Variables, spec.Name)
+	}
+	for _, name := range sortedPackPaths(files) {
+		manifest.Files = append(manifest.Files, PackFile{Path: name, SHA256: Checksum(files[name]), Size: int64(len(files[name]))})
+	}
+
+	manifestData, err := yaml.Marshal(&manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal pack manifest: %w", err)
+	}
+	signature := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(key, manifestData)) + "\n")
+
+	// Entries carry no timestamps or owners, so packing is reproducible
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	writeEntry := func(name string, data []byte) error {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Unix(0, 0), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	if err := writeEntry(PackManifestFile, manifestData); err != nil {
+		return nil, fmt.Errorf("failed to write pack: %w", err)
+	}
+	if err := writeEntry(PackSignatureFile, signature); err != nil {
+		return nil, fmt.Errorf("failed to write pack: %w", err)
+	}
+	for _, file := range manifest.Files {
+		if err := writeEntry(path.Join(packFilesDir, file.Path), files[file.Path]); err != nil {
+			return nil, fmt.Errorf("failed to write pack: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write pack: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write pack: %w", err)
+	}
+	return &manifest, nil
+}
+
+// ReadPack reads a pack archive and verifies it: the manifest must be
+// signed by one of the trusted keys and the files must match it exactly
+func ReadPack(r io.Reader, trusted []ed25519.PublicKey) (*TemplatePack, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pack: %w", err)
+	}
+	defer gz.Close()
+
+	pack := &TemplatePack{files: make(map[string][]byte)}
+	tr := tar.NewReader(gz)
+	var total int64
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pack: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("pack entry %s is not a regular file", header.Name)
+		}
+		total += header.Size
+		if total > maxPackSize {
+			return nil, fmt.Errorf("pack is larger than %d MiB", maxPackSize>>20)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, header.Size))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pack entry %s: %w", header.Name, err)
+		}
+
+		switch name := header.Name; {
+		case name == PackManifestFile:
+			pack.manifest = data
+		case name == PackSignatureFile:
+			pack.signature = data
+		case strings.HasPrefix(name, packFilesDir+"/"):
+			rel := strings.TrimPrefix(name, packFilesDir+"/")
+			if !isPackPath(rel) {
+				return nil, fmt.Errorf("pack entry %s has an unsafe path", name)
+			}
+			pack.files[rel] = data
+		default:
+			return nil, fmt.Errorf("unexpected pack entry %s", name)
+		}
+	}
+
+	if pack.manifest == nil || pack.signature == nil {
+		return nil, fmt.Errorf("pack has no signed manifest")
+	}
+	if err := verifyPackSignature(pack.manifest, pack.signature, trusted); err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(pack.manifest, &pack.Manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse pack manifest: %w", err)
+	}
+	if !packName.MatchString(pack.Manifest.Name) || !packVersion.MatchString(pack.Manifest.Version) {
+		return nil, fmt.Errorf("pack manifest has an invalid name %q or version %q", pack.Manifest.Name, pack.Manifest.Version)
+	}
+
+	listed := make(map[string]bool)
+	for _, file := range pack.Manifest.Files {
+		data, ok := pack.files[file.Path]
+		if !ok {
+			return nil, fmt.Errorf("pack is missing %s", file.Path)
+		}
+		if Checksum(data) != file.SHA256 || int64(len(data)) != file.Size {
+			return nil, fmt.Errorf("pack file %s does not match its checksum", file.Path)
+		}
+		listed[file.Path] = true
+	}
+	for name := range pack.files {
+		if !listed[name] {
+			return nil, fmt.Errorf("pack file %s is not in the manifest", name)
+		}
+	}
+	if !listed[config.TemplateConfigFile] {
+		return nil, fmt.Errorf("pack has no %s", config.TemplateConfigFile)
+	}
+	return pack, nil
+}
+
+// verifyPackSignature checks a manifest signature against every trusted key
+func verifyPackSignature(manifest, signature []byte, trusted []ed25519.PublicKey) error {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("failed to decode pack signature: %w", err)
+	}
+	for _, key := range trusted {
+		if ed25519.Verify(key, manifest, sig) {
+			return nil
+		}
+	}
+	return ErrUntrustedPack
+}
+
+// Files returns the paths of the pack's files, sorted
+func (p *TemplatePack) Files() []string {
+	return sortedPackPaths(p.files)
+}
+
+// Install writes the pack to <root>/<name>, replacing any installed version
+// of it, and returns the manifest of the version it replaced, if any. The
+// manifest and signature are kept beside the files. A failed install leaves
+// the installed version in place.
+func (p *TemplatePack) Install(root string) (*PackManifest, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create pack directory: %w", err)
+	}
+	target := filepath.Join(root, p.Manifest.Name)
+	previous, err := readPackManifest(target)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	staging, err := os.MkdirTemp(root, "."+p.Manifest.Name+"-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	out := NewDirOutput(staging)
+	for name, data := range p.files {
+		if err := out.WriteFile(filepath.FromSlash(name), data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	if err := out.WriteFile(PackManifestFile, p.manifest, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write pack manifest: %w", err)
+	}
+	if err := out.WriteFile(PackSignatureFile, p.signature, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write pack signature: %w", err)
+	}
+
+	if err := os.RemoveAll(target); err != nil {
+		return nil, fmt.Errorf("failed to remove installed version: %w", err)
+	}
+	if err := os.Rename(staging, target); err != nil {
+		return nil, fmt.Errorf("failed to install pack: %w", err)
+	}
+	return previous, nil
+}
+
+// UserPackDir returns the directory installed packs live in,
+// ~/.template-health-endpoint/packs
+func UserPackDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, filepath.FromSlash(userPackDir)), nil
+}
+
+// InstalledPacks returns the manifests of the packs installed below root,
+// sorted by name. A missing root has no packs.
+func InstalledPacks(root string) ([]PackManifest, error) {
+	var packs []PackManifest
+	for _, dir := range InstalledPackDirs(root) {
+		manifest, err := readPackManifest(dir)
+		if err != nil {
+			return nil, err
+		}
+		packs = append(packs, *manifest)
+	}
+	return packs, nil
+}
+
+// InstalledPackDirs returns the directories of the packs installed below
+// root, sorted by name
+func InstalledPackDirs(root string) []string {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		dir := filepath.Join(root, entry.Name())
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, PackManifestFile)); err == nil {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// InstalledPackVariables returns the variables declared in the
+// template.yaml of the packs installed below root, in pack name order
+func InstalledPackVariables(root string) ([]config.VariableSpec, error) {
+	var specs []config.VariableSpec
+	for _, dir := range InstalledPackDirs(root) {
+		templateConfig, err := config.LoadTemplateConfig(filepath.Join(dir, config.TemplateConfigFile))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, templateConfig.Variables...)
+	}
+	return specs, nil
+}
+
+// readPackManifest reads the manifest of an installed pack
+func readPackManifest(dir string) (*PackManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, PackManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pack manifest: %w", err)
+	}
+	var manifest PackManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse pack manifest %s: %w", dir, err)
+	}
+	return &manifest, nil
+}
+
+// PackKeyID returns a short fingerprint identifying a public key
+func PackKeyID(key ed25519.PublicKey) string {
+	return Checksum(key)[:16]
+}
+
+// FormatPackPublicKey encodes a public key the way trusted_keys lists it: base64
+func FormatPackPublicKey(key ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// ParsePackPublicKey decodes a base64 public key as written by FormatPackPublicKey
+func ParsePackPublicKey(s string) (ed25519.PublicKey, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode public key: %w", err)
+	}
+	if len(data) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key has %d bytes, want %d", len(data), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(data), nil
+}
+
+// MarshalPackPrivateKey encodes a private key as PKCS #8 PEM, the format
+// of openssl genpkey -algorithm ed25519
+func MarshalPackPrivateKey(key ed25519.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// ParsePackPrivateKey decodes a PKCS #8 PEM ed25519 private key
+func ParsePackPrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("private key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not an ed25519 key")
+	}
+	return key, nil
+}
+
+// isPackPath reports whether a pack file path stays inside the pack directory
+func isPackPath(name string) bool {
+	return name != "" && name == path.Clean(name) && !path.IsAbs(name) && name != ".." && !strings.HasPrefix(name, "../") &&
+		name != PackManifestFile && name != PackSignatureFile
+}
+
+// sortedPackPaths returns the keys of a file map, sorted
+func sortedPackPaths(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
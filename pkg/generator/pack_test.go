@@ -0,0 +1,162 @@
+package generator
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePackDir(t *testing.T, version string) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"template.yaml":       "name: acme\nversion: " + version + "\nvariables:\n  - {name: team, type: string}\n",
+		"dockerfile.tmpl":     "FROM acme/" + version + "\n",
+		"docs/CONVENTIONS.md": "conventions\n",
+		".git/HEAD":           "ref: refs/heads/main\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestTemplatePack(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	dir := writePackDir(t, "1.0.0")
+	var buf bytes.Buffer
+	manifest, err := WritePack(&buf, dir, key)
+	if err != nil {
+		t.Fatalf("WritePack() error = %v", err)
+	}
+	if manifest.FileName() != "acme-1.0.0.tgz" || len(manifest.Files) != 3 || manifest.KeyID != PackKeyID(pub) {
+		t.Errorf("Manifest = %+v, want 3 files without .git", manifest)
+	}
+
+	// Packing is reproducible
+	var again bytes.Buffer
+	if _, err := WritePack(&again, dir, key); err != nil || !bytes.Equal(buf.Bytes(), again.Bytes()) {
+		t.Errorf("Packing twice gave different archives (err = %v)", err)
+	}
+
+	if _, err := ReadPack(bytes.NewReader(buf.Bytes()), []ed25519.PublicKey{other}); !errors.Is(err, ErrUntrustedPack) {
+		t.Errorf("ReadPack() with an untrusted key error = %v, want ErrUntrustedPack", err)
+	}
+	tampered := rewritePack(t, buf.Bytes(), "files/dockerfile.tmpl", "FROM evil\n")
+	if _, err := ReadPack(bytes.NewReader(tampered), []ed25519.PublicKey{pub}); err == nil {
+		t.Error("ReadPack() accepted a pack with a tampered file")
+	}
+
+	pack, err := ReadPack(bytes.NewReader(buf.Bytes()), []ed25519.PublicKey{other, pub})
+	if err != nil {
+		t.Fatalf("ReadPack() error = %v", err)
+	}
+	root, err := UserPackDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previous, err := pack.Install(root); err != nil || previous != nil {
+		t.Fatalf("Install() = %v, %v", previous, err)
+	}
+
+	// An installed pack overrides built-in templates
+	registry, err := NewOverlayRegistry(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewOverlayRegistry() error = %v", err)
+	}
+	if source, _ := registry.Source("dockerfile"); source != "FROM acme/1.0.0\n" {
+		t.Errorf("dockerfile = %q, want the pack's", source)
+	}
+
+	// Installing another version replaces the installed one
+	buf.Reset()
+	if _, err := WritePack(&buf, writePackDir(t, "1.1.0"), key); err != nil {
+		t.Fatal(err)
+	}
+	pack, err = ReadPack(&buf, []ed25519.PublicKey{pub})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previous, err := pack.Install(root); err != nil || previous == nil || previous.Version != "1.0.0" {
+		t.Fatalf("Install() over 1.0.0 = %+v, %v", previous, err)
+	}
+	installed, err := InstalledPacks(root)
+	if err != nil || len(installed) != 1 || installed[0].Version != "1.1.0" || installed[0].Variables[0] != "team" {
+		t.Errorf("InstalledPacks() = %+v, %v, want acme 1.1.0", installed, err)
+	}
+}
+
+func TestPackKeys(t *testing.T) {
+	pub, key, _ := ed25519.GenerateKey(rand.Reader)
+
+	data, err := MarshalPackPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParsePackPrivateKey(data)
+	if err != nil || !parsed.Equal(key) {
+		t.Errorf("ParsePackPrivateKey() = %v, want the marshalled key", err)
+	}
+
+	parsedPub, err := ParsePackPublicKey(FormatPackPublicKey(pub) + "\n")
+	if err != nil || !parsedPub.Equal(pub) {
+		t.Errorf("ParsePackPublicKey() = %v, want the formatted key", err)
+	}
+	if _, err := ParsePackPublicKey("c2hvcnQ="); err == nil {
+		t.Error("ParsePackPublicKey() accepted a short key")
+	}
+}
+
+// rewritePack returns a copy of a pack archive with one entry's content replaced
+func rewritePack(t *testing.T, data []byte, name, content string) []byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+
+	var out bytes.Buffer
+	gw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gw)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(tr)
+		if header.Name == name {
+			body = []byte(content)
+			header.Size = int64(len(body))
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write(body)
+	}
+	tw.Close()
+	gw.Close()
+	return out.Bytes()
+}
@@ -2,17 +2,24 @@ package generator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"sort"
 	"sync"
+	"syscall"
 	"time"
 )
 
+// DefaultTaskTimeout bounds how long a single file may take to render and write
+const DefaultTaskTimeout = 30 * time.Second
+
 // ParallelGenerator handles parallel file generation
 type ParallelGenerator struct {
-	workerCount   int
-	maxRetries    int
-	retryDelay    time.Duration
-	resultChannel chan GenerationResult
+	workerCount int
+	maxRetries  int
+	retryDelay  time.Duration
+	taskTimeout time.Duration
 }
 
 // GenerationResult represents the result of generating a single file
@@ -20,9 +27,12 @@ type GenerationResult struct {
 	Filename     string
 	TemplateName string
 	Success      bool
+	Skipped      bool // left untouched by an incremental run
 	Error        error
 	Duration     time.Duration
+	Attempts     int
 	Size         int64
+	Checksum     string
 }
 
 // GenerationTask represents a file generation task
@@ -31,16 +41,25 @@ type GenerationTask struct {
 	TemplateName string
 	Context      *GenerationContext
 	Generator    *Generator
+
+	// DependsOn lists the filenames of tasks that must complete first.
+	// A task whose dependency fails is not run.
+	DependsOn []string
+
+	// Timeout overrides the generator's per-task deadline when positive
+	Timeout time.Duration
 }
 
 // GenerationSummary provides statistics about the generation process
 type GenerationSummary struct {
 	TotalFiles    int
 	SuccessCount  int
+	SkippedCount  int
 	FailureCount  int
-	TotalDuration time.Duration
+	TotalDuration time.Duration // sum of the task durations
+	Elapsed       time.Duration // wall-clock time of the whole run
 	TotalSize     int64
-	Results       []GenerationResult
+	Results       []GenerationResult // sorted by filename
 }
 
 // NewParallelGenerator creates a new parallel generator
@@ -50,172 +69,293 @@ func NewParallelGenerator(workerCount int) *ParallelGenerator {
 	}
 
 	return &ParallelGenerator{
-		workerCount:   workerCount,
-		maxRetries:    3,
-		retryDelay:    100 * time.Millisecond,
-		resultChannel: make(chan GenerationResult, workerCount*2),
+		workerCount: workerCount,
+		maxRetries:  3,
+		retryDelay:  100 * time.Millisecond,
+		taskTimeout: DefaultTaskTimeout,
 	}
 }
 
-// GenerateFiles generates multiple files in parallel
+// SetTaskTimeout sets the deadline for each task; zero or less disables it
+func (pg *ParallelGenerator) SetTaskTimeout(timeout time.Duration) {
+	pg.taskTimeout = timeout
+}
+
+// GenerateFiles generates multiple files in parallel. A task is started
+// once every task it depends on has succeeded. Cancelling ctx stops tasks
+// that have not started and interrupts waiting ones; the summary then still
+// accounts for every task and the context error is returned with it.
 func (pg *ParallelGenerator) GenerateFiles(ctx context.Context, tasks []GenerationTask) (*GenerationSummary, error) {
 	if len(tasks) == 0 {
 		return &GenerationSummary{}, nil
 	}
 
-	// Create task channel
-	taskChan := make(chan GenerationTask, len(tasks))
-	
+	graph, err := newTaskGraph(tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	ready := make(chan int, len(tasks))
+	results := make(chan GenerationResult, len(tasks))
+
 	// Start workers
 	var wg sync.WaitGroup
 	for i := 0; i < pg.workerCount; i++ {
 		wg.Add(1)
-		go pg.worker(ctx, &wg, taskChan)
-	}
-
-	// Send tasks to workers
-	go func() {
-		defer close(taskChan)
-		for _, task := range tasks {
-			select {
-			case taskChan <- task:
-			case <-ctx.Done():
-				return
+		go func() {
+			defer wg.Done()
+			for i := range ready {
+				results <- pg.processTask(ctx, tasks[i])
 			}
-		}
-	}()
+		}()
+	}
 
-	// Collect results
-	results := make([]GenerationResult, 0, len(tasks))
-	summary := &GenerationSummary{
-		TotalFiles: len(tasks),
+	for _, i := range graph.roots() {
+		ready <- i
 	}
 
-	// Start result collector
-	resultDone := make(chan struct{})
-	go func() {
-		defer close(resultDone)
-		for i := 0; i < len(tasks); i++ {
-			select {
-			case result := <-pg.resultChannel:
-				results = append(results, result)
-				summary.TotalDuration += result.Duration
-				summary.TotalSize += result.Size
-				
-				if result.Success {
-					summary.SuccessCount++
-				} else {
-					summary.FailureCount++
-				}
-			case <-ctx.Done():
-				return
+	// Collect results, releasing dependents as their dependencies succeed
+	summary := &GenerationSummary{TotalFiles: len(tasks)}
+	for done := 0; done < len(tasks); {
+		result := <-results
+		done++
+		summary.add(result)
+
+		if !result.Success {
+			for _, i := range graph.fail(result.Filename) {
+				summary.add(GenerationResult{
+					Filename:     tasks[i].Filename,
+					TemplateName: tasks[i].TemplateName,
+					Error:        fmt.Errorf("not generated: dependency %s failed", result.Filename),
+				})
+				done++
 			}
+			continue
 		}
-	}()
+		for _, i := range graph.succeed(result.Filename) {
+			ready <- i
+		}
+	}
 
-	// Wait for workers to complete
+	close(ready)
 	wg.Wait()
 
-	// Wait for result collection to complete
-	select {
-	case <-resultDone:
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	}
+	summary.Elapsed = time.Since(start)
+	sort.Slice(summary.Results, func(i, j int) bool {
+		return summary.Results[i].Filename < summary.Results[j].Filename
+	})
 
-	summary.Results = results
+	if err := ctx.Err(); err != nil {
+		return summary, err
+	}
 	return summary, nil
 }
 
-// worker processes generation tasks
-func (pg *ParallelGenerator) worker(ctx context.Context, wg *sync.WaitGroup, taskChan <-chan GenerationTask) {
-	defer wg.Done()
+// add records a task result in the summary
+func (s *GenerationSummary) add(result GenerationResult) {
+	s.Results = append(s.Results, result)
+	s.TotalDuration += result.Duration
+	s.TotalSize += result.Size
 
-	for {
-		select {
-		case task, ok := <-taskChan:
-			if !ok {
-				return
-			}
-			
-			result := pg.processTask(ctx, task)
-			
-			select {
-			case pg.resultChannel <- result:
-			case <-ctx.Done():
-				return
-			}
-			
-		case <-ctx.Done():
-			return
+	switch {
+	case !result.Success:
+		s.FailureCount++
+	case result.Skipped:
+		s.SuccessCount++
+		s.SkippedCount++
+	default:
+		s.SuccessCount++
+	}
+}
+
+// Failures returns the results of the tasks that failed
+func (s *GenerationSummary) Failures() []GenerationResult {
+	var failures []GenerationResult
+	for _, result := range s.Results {
+		if !result.Success {
+			failures = append(failures, result)
 		}
 	}
+	return failures
 }
 
-// processTask processes a single generation task with retries
+// processTask generates a single file, retrying transient filesystem errors
 func (pg *ParallelGenerator) processTask(ctx context.Context, task GenerationTask) GenerationResult {
 	start := time.Now()
-	
-	var lastErr error
-	for attempt := 0; attempt < pg.maxRetries; attempt++ {
-		select {
-		case <-ctx.Done():
-			return GenerationResult{
-				Filename:     task.Filename,
-				TemplateName: task.TemplateName,
-				Success:      false,
-				Error:        ctx.Err(),
-				Duration:     time.Since(start),
-			}
-		default:
+	result := GenerationResult{
+		Filename:     task.Filename,
+		TemplateName: task.TemplateName,
+	}
+
+	timeout := pg.taskTimeout
+	if task.Timeout > 0 {
+		timeout = task.Timeout
+	}
+	taskCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		taskCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	for {
+		result.Attempts++
+
+		if err := taskCtx.Err(); err != nil {
+			result.Error = err
+			break
 		}
 
-		err := task.Generator.generateFile(task.Filename, task.TemplateName, task.Context)
+		written, err := task.Generator.generateFileContext(taskCtx, task.Filename, task.TemplateName, task.Context)
 		if err == nil {
-			// Success - get file size
-			size := pg.getFileSize(task.Generator.config.OutputDir, task.Filename)
-			
-			return GenerationResult{
-				Filename:     task.Filename,
-				TemplateName: task.TemplateName,
-				Success:      true,
-				Duration:     time.Since(start),
-				Size:         size,
+			result.Success = true
+			result.Skipped = written.Skipped
+			result.Size = int64(len(written.Content))
+			result.Checksum = written.Checksum
+			break
+		}
+
+		result.Error = err
+		if !isTransient(err) || result.Attempts >= pg.maxRetries {
+			break
+		}
+
+		// Back off before the next attempt
+		select {
+		case <-time.After(pg.retryDelay * time.Duration(result.Attempts)):
+		case <-taskCtx.Done():
+		}
+	}
+
+	if errors.Is(result.Error, context.DeadlineExceeded) && ctx.Err() == nil {
+		result.Error = fmt.Errorf("exceeded the %v task deadline: %w", timeout, result.Error)
+	}
+	if result.Error != nil && result.Attempts > 1 {
+		result.Error = fmt.Errorf("failed after %d attempts: %w", result.Attempts, result.Error)
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+// isTransient reports whether a failed write is worth retrying: the
+// filesystem was busy or out of file handles, not misconfigured. Template
+// and verification errors are never transient.
+func isTransient(err error) bool {
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) {
+		return false
+	}
+	for _, errno := range []syscall.Errno{syscall.EAGAIN, syscall.EBUSY, syscall.EINTR, syscall.EMFILE, syscall.ENFILE, syscall.ETIMEDOUT} {
+		if errors.Is(pathErr.Err, errno) {
+			return true
+		}
+	}
+	return false
+}
+
+// taskGraph tracks which tasks are waiting for which dependencies
+type taskGraph struct {
+	index      map[string]int
+	waiting    []int   // unfinished dependencies per task
+	dependents [][]int // tasks waiting for each task
+	settled    []bool
+}
+
+// newTaskGraph builds the dependency graph, rejecting unknown and cyclic dependencies
+func newTaskGraph(tasks []GenerationTask) (*taskGraph, error) {
+	g := &taskGraph{
+		index:      make(map[string]int, len(tasks)),
+		waiting:    make([]int, len(tasks)),
+		dependents: make([][]int, len(tasks)),
+		settled:    make([]bool, len(tasks)),
+	}
+
+	for i, task := range tasks {
+		if _, exists := g.index[task.Filename]; exists {
+			return nil, fmt.Errorf("duplicate generation task for %s", task.Filename)
+		}
+		g.index[task.Filename] = i
+	}
+
+	for i, task := range tasks {
+		for _, dep := range task.DependsOn {
+			j, ok := g.index[dep]
+			if !ok {
+				return nil, fmt.Errorf("task %s depends on unknown task %s", task.Filename, dep)
 			}
+			g.waiting[i]++
+			g.dependents[j] = append(g.dependents[j], i)
 		}
+	}
 
-		lastErr = err
-		
-		// Wait before retry (except on last attempt)
-		if attempt < pg.maxRetries-1 {
-			select {
-			case <-time.After(pg.retryDelay * time.Duration(attempt+1)):
-			case <-ctx.Done():
-				return GenerationResult{
-					Filename:     task.Filename,
-					TemplateName: task.TemplateName,
-					Success:      false,
-					Error:        ctx.Err(),
-					Duration:     time.Since(start),
-				}
+	// Kahn's algorithm: every task must become ready eventually
+	waiting := append([]int(nil), g.waiting...)
+	queue := g.roots()
+	for n := 0; n < len(queue); n++ {
+		for _, d := range g.dependents[queue[n]] {
+			if waiting[d]--; waiting[d] == 0 {
+				queue = append(queue, d)
 			}
 		}
 	}
+	if len(queue) != len(tasks) {
+		var cyclic []string
+		for i, task := range tasks {
+			if waiting[i] > 0 {
+				cyclic = append(cyclic, task.Filename)
+			}
+		}
+		sort.Strings(cyclic)
+		return nil, fmt.Errorf("generation tasks have a dependency cycle: %v", cyclic)
+	}
 
-	return GenerationResult{
-		Filename:     task.Filename,
-		TemplateName: task.TemplateName,
-		Success:      false,
-		Error:        fmt.Errorf("failed after %d attempts: %w", pg.maxRetries, lastErr),
-		Duration:     time.Since(start),
+	return g, nil
+}
+
+// roots returns the tasks without dependencies
+func (g *taskGraph) roots() []int {
+	var roots []int
+	for i, waiting := range g.waiting {
+		if waiting == 0 {
+			roots = append(roots, i)
+		}
+	}
+	return roots
+}
+
+// succeed marks a task done and returns the dependents that became ready
+func (g *taskGraph) succeed(filename string) []int {
+	i := g.index[filename]
+	g.settled[i] = true
+
+	var ready []int
+	for _, d := range g.dependents[i] {
+		if g.waiting[d]--; g.waiting[d] == 0 && !g.settled[d] {
+			ready = append(ready, d)
+		}
 	}
+	return ready
 }
 
-// getFileSize gets the size of a generated file
-func (pg *ParallelGenerator) getFileSize(outputDir, filename string) int64 {
-	// This is a simplified implementation
-	// In practice, you'd use filepath.Join and os.Stat
-	return 0
+// fail marks a task done and returns every task that can no longer run
+// because it depends on it, directly or transitively
+func (g *taskGraph) fail(filename string) []int {
+	var blocked []int
+	stack := []int{g.index[filename]}
+	g.settled[stack[0]] = true
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, d := range g.dependents[i] {
+			if !g.settled[d] {
+				g.settled[d] = true
+				blocked = append(blocked, d)
+				stack = append(stack, d)
+			}
+		}
+	}
+	return blocked
 }
 
 // WorkerPool represents a pool of workers for file generation
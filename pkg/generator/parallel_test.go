@@ -0,0 +1,159 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/config"
+)
+
+// orderedOutput records the order in which files are written
+type orderedOutput struct {
+	*MemoryOutput
+	mu    sync.Mutex
+	order []string
+}
+
+func (o *orderedOutput) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	o.mu.Lock()
+	o.order = append(o.order, name)
+	o.mu.Unlock()
+	return o.MemoryOutput.WriteFile(name, data, perm)
+}
+
+func newTestTaskGenerator(t *testing.T, templates fstest.MapFS) (*Generator, *orderedOutput) {
+	t.Helper()
+	registry, err := NewTemplateRegistryFromLayers(TemplateLayer{Name: "test", FS: templates})
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	generator, err := NewWithRegistry(&config.ProjectConfig{
+		Name:     "parallel-test",
+		GoModule: "github.com/example/parallel-test",
+		Tier:     config.TierBasic,
+	}, registry)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	out := &orderedOutput{MemoryOutput: NewMemoryOutput()}
+	generator.out = out
+	return generator, out
+}
+
+func TestParallelGenerator_Dependencies(t *testing.T) {
+	generator, out := newTestTaskGenerator(t, fstest.MapFS{
+		"ok.tmpl":     {Data: []byte("{{.Config.Name}}\n")},
+		"broken.tmpl": {Data: []byte("{{.Missing.Field}}")},
+	})
+	ctx := &GenerationContext{Config: generator.config}
+	task := func(filename, templateName string, deps ...string) GenerationTask {
+		return GenerationTask{Filename: filename, TemplateName: templateName, Context: ctx, Generator: generator, DependsOn: deps}
+	}
+
+	summary, err := NewParallelGenerator(4).GenerateFiles(context.Background(), []GenerationTask{
+		task("c.txt", "ok", "b.txt"),
+		task("b.txt", "ok", "a.txt"),
+		task("a.txt", "ok"),
+		task("bad.txt", "broken"),
+		task("after-bad.txt", "ok", "bad.txt"),
+		task("after-after-bad.txt", "ok", "after-bad.txt", "a.txt"),
+	})
+	if err != nil {
+		t.Fatalf("GenerateFiles() error = %v", err)
+	}
+
+	if got := strings.Join(out.order, ","); got != "a.txt,b.txt,c.txt" {
+		t.Errorf("Write order = %s, want a.txt,b.txt,c.txt", got)
+	}
+	if summary.SuccessCount != 3 || summary.FailureCount != 3 {
+		t.Errorf("Summary has %d successes and %d failures, want 3 and 3", summary.SuccessCount, summary.FailureCount)
+	}
+
+	for _, result := range summary.Results {
+		switch result.Filename {
+		case "a.txt":
+			if result.Size != int64(len("parallel-test\n")) || result.Checksum != Checksum([]byte("parallel-test\n")) {
+				t.Errorf("a.txt: size %d, checksum %s", result.Size, result.Checksum)
+			}
+		case "bad.txt":
+			// Template errors are not retried
+			if result.Attempts != 1 {
+				t.Errorf("bad.txt was attempted %d times, want 1", result.Attempts)
+			}
+		case "after-bad.txt", "after-after-bad.txt":
+			if result.Error == nil || result.Attempts != 0 {
+				t.Errorf("%s ran despite a failed dependency", result.Filename)
+			}
+		}
+	}
+}
+
+func TestParallelGenerator_InvalidDependencies(t *testing.T) {
+	tasks := []GenerationTask{
+		{Filename: "a", DependsOn: []string{"b"}},
+		{Filename: "b", DependsOn: []string{"a"}},
+		{Filename: "c"},
+	}
+	if _, err := NewParallelGenerator(2).GenerateFiles(context.Background(), tasks); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Expected a dependency cycle error, got %v", err)
+	}
+
+	tasks = []GenerationTask{{Filename: "a", DependsOn: []string{"missing"}}}
+	if _, err := NewParallelGenerator(2).GenerateFiles(context.Background(), tasks); err == nil {
+		t.Error("Expected an unknown dependency error")
+	}
+}
+
+func TestParallelGenerator_Cancel(t *testing.T) {
+	generator, out := newTestTaskGenerator(t, fstest.MapFS{"ok.tmpl": {Data: []byte("ok\n")}})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	summary, err := NewParallelGenerator(2).GenerateFiles(ctx, []GenerationTask{
+		{Filename: "a.txt", TemplateName: "ok", Context: &GenerationContext{Config: generator.config}, Generator: generator},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if summary.FailureCount != 1 || len(out.order) != 0 {
+		t.Errorf("Cancelled run wrote %v", out.order)
+	}
+}
+
+func TestRenderContext_Deadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := renderContext(ctx, func() ([]byte, error) {
+		time.Sleep(time.Second)
+		return nil, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&fs.PathError{Op: "open", Path: "x", Err: syscall.EMFILE}, true},
+		{&fs.PathError{Op: "write", Path: "x", Err: syscall.EBUSY}, true},
+		{&fs.PathError{Op: "open", Path: "x", Err: syscall.EACCES}, false},
+		{&ArtifactError{Path: "x.go"}, false},
+		{errors.New("template: x:1: executing"), false},
+	}
+	for _, tt := range tests {
+		if got := isTransient(tt.err); got != tt.want {
+			t.Errorf("isTransient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
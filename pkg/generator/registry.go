@@ -0,0 +1,301 @@
+package generator
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// templateExt is the file extension of named templates
+const templateExt = ".tmpl"
+
+// builtinTemplates holds the named templates shipped with the generator.
+// Each file templates/<name>.tmpl is registered under <name>.
+//
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// BuiltinLayer is the name of the embedded template layer
+const BuiltinLayer = "builtin"
+
+// ProjectTemplateDir is the project-local overlay directory, relative to the project root
+const ProjectTemplateDir = ".template-health/templates"
+
+// userTemplateDir is the per-user overlay directory, relative to the home directory
+const userTemplateDir = ".template-health-endpoint/templates"
+
+// TemplateLayer is a single source of named templates in the registry search path
+type TemplateLayer struct {
+	Name string
+	FS   fs.FS
+}
+
+// TemplateRegistry manages all template files and functions
+type TemplateRegistry struct {
+	templates   map[string]*template.Template
+	sources     map[string]string
+	hashes      map[string]string
+	origins     map[string]string
+	layers      []TemplateLayer
+	functions   template.FuncMap
+	cache       *TemplateCache
+	fingerprint string
+	fields      map[string][]string
+	fieldsMu    sync.Mutex
+}
+
+// NewTemplateRegistry creates a new template registry with all templates.
+// Templates are loaded from the embedded built-in layer first; each override
+// directory is then applied in order, so later directories win. Override
+// directories that do not exist are ignored.
+func NewTemplateRegistry(overrideDirs ...string) (*TemplateRegistry, error) {
+	builtin, err := BuiltinTemplateLayer()
+	if err != nil {
+		return nil, err
+	}
+
+	layers := []TemplateLayer{builtin}
+	for _, dir := range overrideDirs {
+		if dir == "" {
+			continue
+		}
+		info, err := os.Stat(dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to access template directory %s: %w", dir, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("template directory %s is not a directory", dir)
+		}
+		layers = append(layers, TemplateLayer{Name: dir, FS: os.DirFS(dir)})
+	}
+
+	return NewTemplateRegistryFromLayers(layers...)
+}
+
+// BuiltinTemplateLayer returns the layer of templates shipped with the generator
+func BuiltinTemplateLayer() (TemplateLayer, error) {
+	builtin, err := fs.Sub(builtinTemplates, "templates")
+	if err != nil {
+		return TemplateLayer{}, fmt.Errorf("failed to open built-in templates: %w", err)
+	}
+	return TemplateLayer{Name: BuiltinLayer, FS: builtin}, nil
+}
+
+// UserTemplateDir returns the per-user overlay directory,
+// ~/.template-health-endpoint/templates
+func UserTemplateDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, filepath.FromSlash(userTemplateDir)), nil
+}
+
+// OverlayDirs returns the overlay search path for a project, lowest
+// precedence first: the installed template packs by name, the per-user
+// directory, then the project's ProjectTemplateDir. Packs and the user
+// directory are left out when there is no home directory.
+func OverlayDirs(projectDir string) []string {
+	var dirs []string
+	if root, err := UserPackDir(); err == nil {
+		dirs = append(dirs, InstalledPackDirs(root)...)
+	}
+	if dir, err := UserTemplateDir(); err == nil {
+		dirs = append(dirs, dir)
+	}
+	return append(dirs, filepath.Join(projectDir, filepath.FromSlash(ProjectTemplateDir)))
+}
+
+// NewOverlayRegistry creates a template registry from the built-in templates
+// and the overlay search path of projectDir. A file such as go-server.tmpl
+// in an overlay directory replaces the template registered as go-server.
+// Extra directories are applied last and win over every overlay.
+func NewOverlayRegistry(projectDir string, extraDirs ...string) (*TemplateRegistry, error) {
+	return NewTemplateRegistry(append(OverlayDirs(projectDir), extraDirs...)...)
+}
+
+// NewTemplateRegistryFromLayers creates a template registry from explicit layers.
+// Layers are applied in order, so a template in a later layer replaces the
+// template with the same name in an earlier one.
+func NewTemplateRegistryFromLayers(layers ...TemplateLayer) (*TemplateRegistry, error) {
+	registry := &TemplateRegistry{
+		templates: make(map[string]*template.Template),
+		sources:   make(map[string]string),
+		hashes:    make(map[string]string),
+		origins:   make(map[string]string),
+		layers:    layers,
+		functions: TemplateFuncs(),
+		cache:     SharedTemplateCache(),
+		fields:    make(map[string][]string),
+	}
+
+	for _, layer := range layers {
+		if err := registry.loadLayer(layer); err != nil {
+			return nil, fmt.Errorf("failed to load templates from %s: %w", layer.Name, err)
+		}
+	}
+
+	if err := registry.parseTemplates(); err != nil {
+		return nil, fmt.Errorf("failed to register templates: %w", err)
+	}
+
+	return registry, nil
+}
+
+// loadLayer reads every named template of a layer into the registry sources
+func (r *TemplateRegistry) loadLayer(layer TemplateLayer) error {
+	matches, err := fs.Glob(layer.FS, "*"+templateExt)
+	if err != nil {
+		return err
+	}
+
+	for _, match := range matches {
+		content, err := fs.ReadFile(layer.FS, match)
+		if err != nil {
+			return fmt.Errorf("failed to read template %s: %w", match, err)
+		}
+
+		name := strings.TrimSuffix(path.Base(match), templateExt)
+		r.sources[name] = string(content)
+		r.hashes[name] = Checksum(content)
+		r.origins[name] = layer.Name
+	}
+
+	return nil
+}
+
+// parseTemplates parses every registered template source through the
+// cache, so registries with the same sources share one parsed template
+func (r *TemplateRegistry) parseTemplates() error {
+	for name, content := range r.sources {
+		name, content := name, content
+		tmpl, err := r.cache.Template(templateCacheKey(name, content), len(content), func() (*template.Template, error) {
+			return template.New(name).Funcs(r.functions).Parse(content)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to parse template %s (from %s): %w", name, r.origins[name], err)
+		}
+		r.templates[name] = tmpl
+	}
+
+	r.fingerprint = r.computeFingerprint()
+	return nil
+}
+
+// computeFingerprint hashes the names and content hashes of all templates
+func (r *TemplateRegistry) computeFingerprint() string {
+	var b strings.Builder
+	for _, name := range r.Names() {
+		b.WriteString(name)
+		b.WriteByte(0)
+		b.WriteString(r.hashes[name])
+		b.WriteByte(0)
+	}
+	return Checksum([]byte(b.String()))
+}
+
+// Fingerprint returns a hash of the complete template set; it changes
+// whenever any template is added, removed or edited
+func (r *TemplateRegistry) Fingerprint() string {
+	return r.fingerprint
+}
+
+// Parse parses template content with the registry's function library.
+// Every registered template is associated with the result, so the content
+// can include them with {{template "<name>" .}}.
+func (r *TemplateRegistry) Parse(name, content string) (*template.Template, error) {
+	tmpl := template.New(name).Funcs(r.functions)
+	for _, registered := range r.Names() {
+		if registered == name {
+			continue
+		}
+		if _, err := tmpl.AddParseTree(registered, r.templates[registered].Tree); err != nil {
+			return nil, fmt.Errorf("failed to associate template %s: %w", registered, err)
+		}
+	}
+	return tmpl.Parse(content)
+}
+
+// RenderSource parses and executes template content that is not part of the
+// registry, such as a snapshot of an older template version
+func (r *TemplateRegistry) RenderSource(name, content string, ctx *GenerationContext) ([]byte, error) {
+	tmpl, err := r.Parse(name, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, ctx); err != nil {
+		return nil, fmt.Errorf("failed to execute template %s: %w", name, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Lookup returns the parsed template registered under name
+func (r *TemplateRegistry) Lookup(name string) (*template.Template, bool) {
+	tmpl, exists := r.templates[name]
+	return tmpl, exists
+}
+
+// Source returns the raw content of the template registered under name
+func (r *TemplateRegistry) Source(name string) (string, bool) {
+	content, exists := r.sources[name]
+	return content, exists
+}
+
+// Hash returns the SHA-256 of the raw content of the template registered under name
+func (r *TemplateRegistry) Hash(name string) (string, bool) {
+	hash, exists := r.hashes[name]
+	return hash, exists
+}
+
+// Origin returns the name of the layer that provided the template
+func (r *TemplateRegistry) Origin(name string) (string, bool) {
+	origin, exists := r.origins[name]
+	return origin, exists
+}
+
+// Providers returns every layer that has a template called name, highest
+// precedence first. The first layer is the one the registry uses.
+func (r *TemplateRegistry) Providers(name string) []TemplateLayer {
+	var providers []TemplateLayer
+	for i := len(r.layers) - 1; i >= 0; i-- {
+		if _, err := fs.Stat(r.layers[i].FS, name+templateExt); err == nil {
+			providers = append(providers, r.layers[i])
+		}
+	}
+	return providers
+}
+
+// Names returns the sorted names of all registered templates
+func (r *TemplateRegistry) Names() []string {
+	names := make([]string, 0, len(r.templates))
+	for name := range r.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Funcs returns the function library shared by all templates
+func (r *TemplateRegistry) Funcs() template.FuncMap {
+	return r.functions
+}
+
+// Layers returns the layers the registry was loaded from, lowest precedence first
+func (r *TemplateRegistry) Layers() []TemplateLayer {
+	return r.layers
+}
@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOverlayRegistry(t *testing.T) {
+	home := t.TempDir()
+	project := t.TempDir()
+	t.Setenv("HOME", home)
+
+	writeTemplate := func(dir, name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+templateExt), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	userDir, err := UserTemplateDir()
+	if err != nil {
+		t.Fatalf("UserTemplateDir() error = %v", err)
+	}
+	projectDir := filepath.Join(project, filepath.FromSlash(ProjectTemplateDir))
+	writeTemplate(userDir, "dockerfile", "FROM user\n")
+	writeTemplate(userDir, "gitignore", "user\n")
+	writeTemplate(projectDir, "dockerfile", "FROM project\n")
+
+	registry, err := NewOverlayRegistry(project)
+	if err != nil {
+		t.Fatalf("NewOverlayRegistry() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		source    string
+		providers []string
+	}{
+		{"dockerfile", "FROM project\n", []string{projectDir, userDir, BuiltinLayer}},
+		{"gitignore", "user\n", []string{userDir, BuiltinLayer}},
+		{"go-server", "", []string{BuiltinLayer}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if source, _ := registry.Source(tt.name); tt.source != "" && source != tt.source {
+				t.Errorf("Source() = %q, want %q", source, tt.source)
+			}
+
+			providers := registry.Providers(tt.name)
+			if len(providers) != len(tt.providers) {
+				t.Fatalf("Providers() returned %d layers, want %d", len(providers), len(tt.providers))
+			}
+			for i, layer := range providers {
+				if layer.Name != tt.providers[i] {
+					t.Errorf("Providers()[%d] = %s, want %s", i, layer.Name, tt.providers[i])
+				}
+			}
+			if origin, _ := registry.Origin(tt.name); origin != tt.providers[0] {
+				t.Errorf("Origin() = %s, want %s", origin, tt.providers[0])
+			}
+		})
+	}
+}
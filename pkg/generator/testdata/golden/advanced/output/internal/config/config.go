@@ -17,7 +17,7 @@ func Load() (*Config, error) {
 	cfg := &Config{
 		Port:    8080,
 		Version: "1.0.0",
-		Name:    "{{.Config.Name}}",
+		Name:    "golden-advanced",
 	}
 
 	// Override with environment variables
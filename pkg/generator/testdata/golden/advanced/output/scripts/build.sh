@@ -0,0 +1,16 @@
+#!/bin/bash
+
+# Build script for golden-advanced
+
+set -e
+
+echo "🔨 Building golden-advanced..."
+
+# Clean previous builds
+rm -rf bin/
+mkdir -p bin/
+
+# Build the application
+go build -o bin/golden-advanced cmd/server/main.go
+
+echo "✅ Build complete: bin/golden-advanced"
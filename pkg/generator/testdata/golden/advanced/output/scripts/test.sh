@@ -1,10 +1,10 @@
 #!/bin/bash
 
-# Test script for {{.Config.Name}}
+# Test script for golden-advanced
 
 set -e
 
-echo "🧪 Running tests for {{.Config.Name}}..."
+echo "🧪 Running tests for golden-advanced..."
 
 # Run tests
 go test -v ./...
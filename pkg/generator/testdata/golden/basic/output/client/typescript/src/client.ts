@@ -1,5 +1,5 @@
-// Generated TypeScript client for {{.Config.Name}}
-// Generated at: {{.Timestamp}}
+// Generated TypeScript client for golden-basic
+// Generated at: 2024-01-01T00:00:00Z
 
 import { HealthReport, ServerTime } from './types';
 
@@ -1,5 +1,5 @@
-// Generated TypeScript types for {{.Config.Name}}
-// Generated at: {{.Timestamp}}
+// Generated TypeScript types for golden-basic
+// Generated at: 2024-01-01T00:00:00Z
 
 export interface HealthReport {
   status: string;
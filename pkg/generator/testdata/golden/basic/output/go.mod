@@ -1,4 +1,4 @@
-module {{.Config.GoModule}}
+module github.com/example/golden-basic
 
 go 1.21
 
@@ -6,8 +6,8 @@ import (
 	"net/http"
 	"time"
 
-	"{{.Config.GoModule}}/internal/config"
-	"{{.Config.GoModule}}/internal/models"
+	"github.com/example/golden-basic/internal/config"
+	"github.com/example/golden-basic/internal/models"
 )
 
 // HealthHandler handles health-related HTTP requests
@@ -49,12 +49,12 @@ func (h *HealthHandler) ServerTime(w http.ResponseWriter, r *http.Request) {
 	location := now.Location()
 
 	serverTime := models.ServerTime{
-		Timestamp:   now,
-		Timezone:    location.String(),
-		Unix:        now.Unix(),
-		UnixMilli:   now.UnixMilli(),
-		ISO8601:     now.Format(time.RFC3339),
-		Formatted:   now.Format("Monday, January 2, 2006 at 3:04:05 PM MST"),
+		Timestamp: now,
+		Timezone:  location.String(),
+		Unix:      now.Unix(),
+		UnixMilli: now.UnixMilli(),
+		ISO8601:   now.Format(time.RFC3339),
+		Formatted: now.Format("Monday, January 2, 2006 at 3:04:05 PM MST"),
 	}
 
 	w.WriteHeader(http.StatusOK)
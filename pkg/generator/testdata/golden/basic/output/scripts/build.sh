@@ -0,0 +1,16 @@
+#!/bin/bash
+
+# Build script for golden-basic
+
+set -e
+
+echo "🔨 Building golden-basic..."
+
+# Clean previous builds
+rm -rf bin/
+mkdir -p bin/
+
+# Build the application
+go build -o bin/golden-basic cmd/server/main.go
+
+echo "✅ Build complete: bin/golden-basic"
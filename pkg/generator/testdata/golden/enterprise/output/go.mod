@@ -0,0 +1,11 @@
+module github.com/example/golden-enterprise
+
+go 1.21
+
+require (
+	github.com/gorilla/mux v1.8.1
+	go.opentelemetry.io/otel v1.21.0
+	go.opentelemetry.io/otel/trace v1.21.0
+	go.opentelemetry.io/otel/metric v1.21.0
+	github.com/cloudevents/sdk-go/v2 v2.14.0
+)
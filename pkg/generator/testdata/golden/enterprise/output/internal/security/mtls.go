@@ -0,0 +1,68 @@
+package security
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+)
+
+// MTLSConfig holds the configuration for mutual TLS
+type MTLSConfig struct {
+	CertFile   string
+	KeyFile    string
+	CAFile     string
+	ClientAuth tls.ClientAuthType
+}
+
+// SetupMTLS configures mutual TLS for the server
+func SetupMTLS(config MTLSConfig) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	caCert, err := ioutil.ReadFile(config.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("failed to parse CA certificate")
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   config.ClientAuth,
+		ClientCAs:    caCertPool,
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	return tlsConfig, nil
+}
+
+// MTLSMiddleware validates client certificates
+func MTLSMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+			http.Error(w, "Client certificate required", http.StatusUnauthorized)
+			return
+		}
+
+		clientCert := r.TLS.PeerCertificates[0]
+		clientID := clientCert.Subject.CommonName
+		if clientID == "" {
+			http.Error(w, "Invalid client certificate", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := WithClientIdentity(r.Context(), clientID)
+		r = r.WithContext(ctx)
+
+		log.Printf("mTLS: Client authenticated: %s", clientID)
+		next.ServeHTTP(w, r)
+	})
+}
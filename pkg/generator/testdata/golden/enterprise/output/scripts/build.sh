@@ -0,0 +1,16 @@
+#!/bin/bash
+
+# Build script for golden-enterprise
+
+set -e
+
+echo "🔨 Building golden-enterprise..."
+
+# Clean previous builds
+rm -rf bin/
+mkdir -p bin/
+
+# Build the application
+go build -o bin/golden-enterprise cmd/server/main.go
+
+echo "✅ Build complete: bin/golden-enterprise"
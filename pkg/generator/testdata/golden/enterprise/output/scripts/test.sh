@@ -0,0 +1,16 @@
+#!/bin/bash
+
+# Test script for golden-enterprise
+
+set -e
+
+echo "🧪 Running tests for golden-enterprise..."
+
+# Run tests
+go test -v ./...
+
+# Run tests with coverage
+go test -v -coverprofile=coverage.out ./...
+go tool cover -html=coverage.out -o coverage.html
+
+echo "✅ Tests complete. Coverage report: coverage.html"
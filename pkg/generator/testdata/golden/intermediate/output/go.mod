@@ -0,0 +1,10 @@
+module github.com/example/golden-intermediate
+
+go 1.21
+
+require (
+	github.com/gorilla/mux v1.8.1
+	go.opentelemetry.io/otel v1.21.0
+	go.opentelemetry.io/otel/trace v1.21.0
+	go.opentelemetry.io/otel/metric v1.21.0
+)
@@ -0,0 +1,8 @@
+package observability
+
+// Simplified tracing for intermediate tier
+type TracingProvider struct{}
+
+func NewTracingProvider(serviceName string) *TracingProvider {
+	return &TracingProvider{}
+}
@@ -0,0 +1,16 @@
+#!/bin/bash
+
+# Build script for golden-intermediate
+
+set -e
+
+echo "🔨 Building golden-intermediate..."
+
+# Clean previous builds
+rm -rf bin/
+mkdir -p bin/
+
+# Build the application
+go build -o bin/golden-intermediate cmd/server/main.go
+
+echo "✅ Build complete: bin/golden-intermediate"
@@ -0,0 +1,420 @@
+package generator
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/config"
+)
+
+// TierFile is a file in the effective file set of a static template tier
+type TierFile struct {
+	Path      string // slash-separated path relative to the tier directory
+	Tier      string // tier whose directory holds the file
+	Overrides string // tier whose copy of the file this one replaces, if any
+}
+
+// Tier is a static template tier with its extends chain resolved. A tier
+// directory holds a template.yaml and the files the tier adds or overrides;
+// everything else is inherited from the tier it extends.
+type Tier struct {
+	Name     string
+	Parent   *Tier
+	Declared *config.TemplateConfig // template.yaml as written
+	Config   *config.TemplateConfig // features, metadata and variables merged along the chain
+	Deleted  []string               // inherited files the tier deletes
+	files    map[string]TierFile
+}
+
+// Files returns the effective file set of the tier sorted by path
+func (t *Tier) Files() []TierFile {
+	files := make([]TierFile, 0, len(t.files))
+	for _, file := range t.files {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
+
+// File returns the effective file at a slash-separated path
+func (t *Tier) File(name string) (TierFile, bool) {
+	file, ok := t.files[name]
+	return file, ok
+}
+
+// Match returns the effective files at name, or below it when name ends in a slash
+func (t *Tier) Match(name string) []TierFile {
+	if !strings.HasSuffix(name, "/") {
+		if file, ok := t.files[name]; ok {
+			return []TierFile{file}
+		}
+		return nil
+	}
+
+	var files []TierFile
+	for _, file := range t.Files() {
+		if strings.HasPrefix(file.Path, name) {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// Chain returns the names of the tiers from the base tier up to t
+func (t *Tier) Chain() []string {
+	var chain []string
+	for tier := t; tier != nil; tier = tier.Parent {
+		chain = append([]string{tier.Name}, chain...)
+	}
+	return chain
+}
+
+// TierRegistry holds the static template tiers of a templates directory,
+// one subdirectory per tier, resolved along their extends chains
+type TierRegistry struct {
+	fsys  fs.FS
+	tiers map[string]*Tier
+
+	// goSuffix is appended to the names of stored Go files
+	goSuffix string
+}
+
+// builtinTiers holds every tier the generator ships, a copy of the tiers
+// below config.DefaultTemplatesDir, so generation and migration do not
+// depend on the working directory. Go files carry builtinGoSuffix so the
+// go tool does not build them.
+//
+//go:embed all:tiers
+var builtinTiers embed.FS
+
+// builtinGoSuffix is appended to the Go files of the built-in tiers
+const builtinGoSuffix = ".tmpl"
+
+// BuiltinTierRegistry returns the tiers shipped with the generator
+func BuiltinTierRegistry() (*TierRegistry, error) {
+	fsys, err := fs.Sub(builtinTiers, "tiers")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load built-in tiers: %w", err)
+	}
+	return newTierRegistry(fsys, builtinGoSuffix)
+}
+
+// BuiltinTierConfig returns the template configuration of a built-in tier,
+// merged along its extends chain, or nil for a tier the generator does not ship
+func BuiltinTierConfig(tier config.TemplateTier) (*config.TemplateConfig, error) {
+	tiers, err := BuiltinTierRegistry()
+	if err != nil {
+		return nil, err
+	}
+	if resolved, ok := tiers.Tier(string(tier)); ok {
+		return resolved.Config, nil
+	}
+	return nil, nil
+}
+
+// LoadTierRegistry loads the tiers below dir, such as config.DefaultTemplatesDir.
+// A missing directory is reported as an error wrapping fs.ErrNotExist.
+func LoadTierRegistry(dir string) (*TierRegistry, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("failed to access templates directory: %w", err)
+	}
+	return NewTierRegistry(os.DirFS(dir))
+}
+
+// NewTierRegistry loads the tiers of a templates file system. Every top-level
+// directory with a template.yaml is a tier named after the directory.
+func NewTierRegistry(fsys fs.FS) (*TierRegistry, error) {
+	return newTierRegistry(fsys, "")
+}
+
+// newTierRegistry loads the tiers of a templates file system whose Go files
+// are stored with goSuffix appended to their names
+func newTierRegistry(fsys fs.FS, goSuffix string) (*TierRegistry, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates directory: %w", err)
+	}
+
+	declared := make(map[string]*config.TemplateConfig)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := path.Join(entry.Name(), config.TemplateConfigFile)
+		data, err := fs.ReadFile(fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		declared[entry.Name()], err = config.ParseTemplateConfig(name, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	registry := &TierRegistry{fsys: fsys, tiers: make(map[string]*Tier), goSuffix: goSuffix}
+	for name := range declared {
+		if _, err := registry.resolve(name, declared, nil); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// resolve builds a tier after the tiers it extends; stack holds the tiers
+// being resolved to detect cycles
+func (r *TierRegistry) resolve(name string, declared map[string]*config.TemplateConfig, stack []string) (*Tier, error) {
+	if tier, ok := r.tiers[name]; ok {
+		return tier, nil
+	}
+	for i, pending := range stack {
+		if pending == name {
+			return nil, fmt.Errorf("tier %s extends itself: %s", name, strings.Join(append(stack[i:], name), " → "))
+		}
+	}
+
+	own := declared[name]
+	tier := &Tier{Name: name, Declared: own, Config: own, files: make(map[string]TierFile)}
+	if own.Extends != "" {
+		if _, ok := declared[own.Extends]; !ok {
+			return nil, fmt.Errorf("tier %s extends unknown tier %s", name, own.Extends)
+		}
+		parent, err := r.resolve(own.Extends, declared, append(stack, name))
+		if err != nil {
+			return nil, err
+		}
+		tier.Parent = parent
+		tier.Config = mergeTierConfig(parent.Config, own)
+		for file, inherited := range parent.files {
+			tier.files[file] = inherited
+		}
+		for _, file := range own.Delete {
+			if _, ok := tier.files[file]; ok {
+				delete(tier.files, file)
+				tier.Deleted = append(tier.Deleted, file)
+			}
+		}
+	}
+
+	err := fs.WalkDir(r.fsys, name, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel := strings.TrimPrefix(file, name+"/")
+		if r.goSuffix != "" && strings.HasSuffix(rel, ".go"+r.goSuffix) {
+			rel = strings.TrimSuffix(rel, r.goSuffix)
+		}
+		if rel == config.TemplateConfigFile {
+			return nil
+		}
+		entry := TierFile{Path: rel, Tier: name}
+		if inherited, ok := tier.files[rel]; ok {
+			entry.Overrides = inherited.Tier
+		}
+		tier.files[rel] = entry
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tier %s: %w", name, err)
+	}
+
+	r.tiers[name] = tier
+	return tier, nil
+}
+
+// mergeTierConfig returns the configuration of a tier on top of the merged
+// configuration of its parent: features and metadata are merged key by key
+// and variables name by name, the tier's own declarations winning
+func mergeTierConfig(parent, own *config.TemplateConfig) *config.TemplateConfig {
+	merged := *own
+
+	merged.Features = make(map[string]bool)
+	for _, features := range []map[string]bool{parent.Features, own.Features} {
+		for feature, enabled := range features {
+			merged.Features[feature] = enabled
+		}
+	}
+
+	if len(parent.Metadata) > 0 || len(own.Metadata) > 0 {
+		merged.Metadata = make(map[string]string)
+		for _, metadata := range []map[string]string{parent.Metadata, own.Metadata} {
+			for key, value := range metadata {
+				merged.Metadata[key] = value
+			}
+		}
+	}
+
+	merged.Variables = parent.WithVariables(own.Variables).Variables
+	return &merged
+}
+
+// Tier returns the tier with the given name
+func (r *TierRegistry) Tier(name string) (*Tier, bool) {
+	tier, ok := r.tiers[name]
+	return tier, ok
+}
+
+// Tiers returns every tier, base tiers first and each tier after the one
+// it extends, ties broken by name
+func (r *TierRegistry) Tiers() []*Tier {
+	tiers := make([]*Tier, 0, len(r.tiers))
+	for _, tier := range r.tiers {
+		tiers = append(tiers, tier)
+	}
+	sort.Slice(tiers, func(i, j int) bool {
+		di, dj := len(tiers[i].Chain()), len(tiers[j].Chain())
+		if di != dj {
+			return di < dj
+		}
+		return tiers[i].Name < tiers[j].Name
+	})
+	return tiers
+}
+
+// ReadFile reads the contents of an effective tier file
+func (r *TierRegistry) ReadFile(file TierFile) ([]byte, error) {
+	name := path.Join(file.Tier, file.Path)
+	if path.Ext(name) == ".go" {
+		name += r.goSuffix
+	}
+	data, err := fs.ReadFile(r.fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s/%s: %w", file.Tier, file.Path, err)
+	}
+	return data, nil
+}
+
+// RenderFiles renders the effective files of a tier at the given paths,
+// where a path ending in a slash selects every file below it. Files are
+// executed as templates of registry with ctx and written without a .tmpl
+// suffix.
+func (r *TierRegistry) RenderFiles(registry *TemplateRegistry, tier string, paths []string, ctx *GenerationContext) ([]RenderedFile, error) {
+	resolved, ok := r.Tier(tier)
+	if !ok {
+		return nil, fmt.Errorf("unknown tier %s", tier)
+	}
+
+	var rendered []RenderedFile
+	for _, name := range paths {
+		matches := resolved.Match(name)
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("tier %s has no file %s", tier, name)
+		}
+
+		for _, file := range matches {
+			source, err := r.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			templateName := path.Join(file.Tier, file.Path)
+			content, err := registry.RenderSource(templateName, string(source), ctx)
+			if err != nil {
+				return nil, err
+			}
+			target := strings.TrimSuffix(file.Path, ".tmpl")
+			content, err = FormatArtifact(target, content)
+			if err != nil {
+				return nil, fmt.Errorf("template %s produced invalid output: %w", templateName, err)
+			}
+			rendered = append(rendered, RenderedFile{Path: target, Template: templateName, Content: content})
+		}
+	}
+	return rendered, nil
+}
+
+// TierIssueKind classifies a divergence between a tier and the tier it extends
+type TierIssueKind string
+
+const (
+	// TierRedundantCopy is a file identical to the copy the tier inherits
+	TierRedundantCopy TierIssueKind = "redundant-copy"
+
+	// TierUndeclaredOverride is a file that replaces an inherited copy without being listed under overrides
+	TierUndeclaredOverride TierIssueKind = "undeclared-override"
+
+	// TierStaleOverride is a file listed under overrides that the tier does not override
+	TierStaleOverride TierIssueKind = "stale-override"
+
+	// TierStaleDelete is a file listed under delete that the tier does not inherit
+	TierStaleDelete TierIssueKind = "stale-delete"
+)
+
+// TierIssue is an accidental divergence of a tier from the tier it extends
+type TierIssue struct {
+	Tier    string
+	Path    string
+	Kind    TierIssueKind
+	Message string
+}
+
+// String formats the issue as tier/path: message [kind]
+func (i TierIssue) String() string {
+	return fmt.Sprintf("%s/%s: %s [%s]", i.Tier, i.Path, i.Message, i.Kind)
+}
+
+// Check compares every tier with the tier it extends and reports files
+// copied unchanged, overrides that are not declared and overrides or
+// deletions that no longer apply, in tier order
+func (r *TierRegistry) Check() ([]TierIssue, error) {
+	var issues []TierIssue
+	for _, tier := range r.Tiers() {
+		declared := make(map[string]bool)
+		for _, file := range tier.Declared.Overrides {
+			declared[file] = true
+			if entry, ok := tier.File(file); !ok || entry.Tier != tier.Name || entry.Overrides == "" {
+				issues = append(issues, TierIssue{Tier: tier.Name, Path: file, Kind: TierStaleOverride,
+					Message: "is listed under overrides but replaces no inherited file"})
+			}
+		}
+
+		deleted := make(map[string]bool)
+		for _, file := range tier.Deleted {
+			deleted[file] = true
+		}
+		for _, file := range tier.Declared.Delete {
+			if !deleted[file] {
+				issues = append(issues, TierIssue{Tier: tier.Name, Path: file, Kind: TierStaleDelete,
+					Message: fmt.Sprintf("is listed under delete but not inherited from %s", tier.Declared.Extends)})
+			}
+		}
+
+		for _, file := range tier.Files() {
+			if file.Tier != tier.Name || file.Overrides == "" {
+				continue
+			}
+			own, err := r.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			inherited, err := r.ReadFile(tier.Parent.files[file.Path])
+			if err != nil {
+				return nil, err
+			}
+
+			switch {
+			case bytes.Equal(own, inherited):
+				issues = append(issues, TierIssue{Tier: tier.Name, Path: file.Path, Kind: TierRedundantCopy,
+					Message: fmt.Sprintf("is identical to the copy in %s; remove it to inherit", file.Overrides)})
+			case !declared[file.Path]:
+				changed := 0
+				for _, op := range diffLines(splitLines(string(inherited)), splitLines(string(own))) {
+					if op.Kind != diffEqual {
+						changed++
+					}
+				}
+				issues = append(issues, TierIssue{Tier: tier.Name, Path: file.Path, Kind: TierUndeclaredOverride,
+					Message: fmt.Sprintf("differs from the copy in %s by %d changed lines; list it under overrides if that is intended", file.Overrides, changed)})
+			}
+		}
+	}
+	return issues, nil
+}
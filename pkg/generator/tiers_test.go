@@ -0,0 +1,263 @@
+package generator
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/LarsArtmann/BMAD-METHOD/pkg/config"
+)
+
+func TestTierRegistry(t *testing.T) {
+	fsys := fstest.MapFS{
+		"base/template.yaml": {Data: []byte(`
+tier: base
+features: {docker: true, metrics: false}
+variables:
+  - {name: port, type: int, default: "8080"}
+`)},
+		"base/main.go":      {Data: []byte("package main\n")},
+		"base/health.go":    {Data: []byte("health\n")},
+		"base/.gitignore":   {Data: []byte("bin/\n")},
+		"base/docs/API.md":  {Data: []byte("api\n")},
+		"base/docs/FAQ.md":  {Data: []byte("faq\n")},
+		"notatier/file.txt": {Data: []byte("ignored\n")},
+		"top/template.yaml": {Data: []byte(`
+tier: top
+extends: base
+features: {metrics: true}
+variables:
+  - {name: port, type: int, default: "9090"}
+  - {name: region, type: string}
+overrides: [main.go]
+delete: [.gitignore]
+`)},
+		"top/main.go":    {Data: []byte("package main\n\nfunc main() {}\n")},
+		"top/metrics.go": {Data: []byte("metrics\n")},
+	}
+
+	registry, err := NewTierRegistry(fsys)
+	if err != nil {
+		t.Fatalf("NewTierRegistry() error = %v", err)
+	}
+	if _, ok := registry.Tier("notatier"); ok {
+		t.Error("A directory without template.yaml must not be a tier")
+	}
+
+	top, ok := registry.Tier("top")
+	if !ok {
+		t.Fatal("Tier top was not loaded")
+	}
+	want := []TierFile{
+		{Path: "docs/API.md", Tier: "base"},
+		{Path: "docs/FAQ.md", Tier: "base"},
+		{Path: "health.go", Tier: "base"},
+		{Path: "main.go", Tier: "top", Overrides: "base"},
+		{Path: "metrics.go", Tier: "top"},
+	}
+	if got := top.Files(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Files() = %+v, want %+v", got, want)
+	}
+	if got := top.Match("docs/"); len(got) != 2 {
+		t.Errorf("Match(docs/) = %+v, want both docs", got)
+	}
+	if !reflect.DeepEqual(top.Deleted, []string{".gitignore"}) || !reflect.DeepEqual(top.Chain(), []string{"base", "top"}) {
+		t.Errorf("Deleted = %v, Chain() = %v", top.Deleted, top.Chain())
+	}
+
+	if !top.Config.Features["docker"] || !top.Config.Features["metrics"] {
+		t.Errorf("Features = %v, want inherited docker and overridden metrics", top.Config.Features)
+	}
+	if len(top.Config.Variables) != 2 || top.Config.Variables[0].Default != "9090" {
+		t.Errorf("Variables = %+v, want port redeclared and region added", top.Config.Variables)
+	}
+
+	data, err := registry.ReadFile(want[2])
+	if err != nil || string(data) != "health\n" {
+		t.Errorf("ReadFile() = %q, %v", data, err)
+	}
+
+	issues, err := registry.Check()
+	if err != nil || len(issues) != 0 {
+		t.Errorf("Check() = %v, %v, want no issues", issues, err)
+	}
+}
+
+func TestTierRegistry_Check(t *testing.T) {
+	registry, err := NewTierRegistry(fstest.MapFS{
+		"base/template.yaml": {Data: []byte("tier: base\n")},
+		"base/a.go":          {Data: []byte("a\n")},
+		"base/b.go":          {Data: []byte("b\n")},
+		"top/template.yaml":  {Data: []byte("tier: top\nextends: base\noverrides: [c.go]\ndelete: [d.go]\n")},
+		"top/a.go":           {Data: []byte("a\n")},
+		"top/b.go":           {Data: []byte("b\nchanged\n")},
+		"top/c.go":           {Data: []byte("c\n")},
+	})
+	if err != nil {
+		t.Fatalf("NewTierRegistry() error = %v", err)
+	}
+
+	issues, err := registry.Check()
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.Path+" "+string(issue.Kind))
+	}
+	want := []string{
+		"c.go " + string(TierStaleOverride),
+		"d.go " + string(TierStaleDelete),
+		"a.go " + string(TierRedundantCopy),
+		"b.go " + string(TierUndeclaredOverride),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() = %v, want %v", got, want)
+	}
+}
+
+func TestTierRegistry_Errors(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"cycle": {
+			"a/template.yaml": {Data: []byte("extends: b\n")},
+			"b/template.yaml": {Data: []byte("extends: a\n")},
+		},
+		"unknown parent": {
+			"a/template.yaml": {Data: []byte("extends: missing\n")},
+		},
+		"delete without extends": {
+			"a/template.yaml": {Data: []byte("delete: [x.go]\n")},
+		},
+	} {
+		if _, err := NewTierRegistry(fsys); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestTierRegistry_Templates(t *testing.T) {
+	registry, err := LoadTierRegistry("../../templates")
+	if err != nil {
+		t.Fatalf("LoadTierRegistry() error = %v", err)
+	}
+
+	enterprise, ok := registry.Tier("enterprise")
+	if !ok {
+		t.Fatal("Tier enterprise was not loaded")
+	}
+	if got := strings.Join(enterprise.Chain(), " "); got != "basic intermediate advanced enterprise" {
+		t.Errorf("Chain() = %s", got)
+	}
+	if file, ok := enterprise.File("internal/handlers/health.go"); !ok || file.Tier != "basic" {
+		t.Errorf("internal/handlers/health.go = %+v, want inherited from basic", file)
+	}
+
+	issues, err := registry.Check()
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	for _, issue := range issues {
+		t.Errorf("Divergence: %s", issue)
+	}
+}
+
+func TestBuiltinTierRegistry(t *testing.T) {
+	// The embedded tiers are a copy of the templates directory
+	templatesDir := filepath.Join("..", "..", config.DefaultTemplatesDir)
+	source, err := LoadTierRegistry(templatesDir)
+	if err != nil {
+		t.Fatalf("LoadTierRegistry() error = %v", err)
+	}
+	builtin, err := BuiltinTierRegistry()
+	if err != nil {
+		t.Fatalf("BuiltinTierRegistry() error = %v", err)
+	}
+	for _, tier := range []config.TemplateTier{config.TierBasic, config.TierIntermediate, config.TierAdvanced, config.TierEnterprise} {
+		embedded, err := fs.ReadFile(builtinTiers, path.Join("tiers", string(tier), config.TemplateConfigFile))
+		if err != nil {
+			t.Fatalf("Tier %s is not embedded: %v", tier, err)
+		}
+		declared, err := os.ReadFile(filepath.Join(templatesDir, string(tier), config.TemplateConfigFile))
+		if err != nil {
+			t.Fatalf("Failed to read %s template.yaml: %v", tier, err)
+		}
+		if !bytes.Equal(embedded, declared) {
+			t.Errorf("Embedded %s/template.yaml differs from templates/%s/template.yaml; copy it into pkg/generator/tiers", tier, tier)
+		}
+
+		want, _ := source.Tier(string(tier))
+		got, _ := builtin.Tier(string(tier))
+		if !reflect.DeepEqual(got.Files(), want.Files()) {
+			t.Errorf("Embedded %s files = %v, want %v", tier, got.Files(), want.Files())
+			continue
+		}
+		for _, file := range want.Files() {
+			wantData, _ := source.ReadFile(file)
+			gotData, err := builtin.ReadFile(file)
+			if err != nil || !bytes.Equal(gotData, wantData) {
+				t.Errorf("Embedded %s/%s differs from templates; copy it into pkg/generator/tiers (Go files with a %s suffix)", file.Tier, file.Path, builtinGoSuffix)
+			}
+		}
+	}
+
+	// Declarations must not depend on the working directory
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	registry, err := NewTemplateRegistry()
+	if err != nil {
+		t.Fatalf("NewTemplateRegistry() error = %v", err)
+	}
+	rendered, err := builtin.RenderFiles(registry, "enterprise", []string{"internal/handlers/metrics.go", "internal/compliance/"}, &GenerationContext{
+		Config:  &config.ProjectConfig{Name: "outside-repo", GoModule: "github.com/example/outside-repo"},
+		Version: GeneratorVersion,
+	})
+	if err != nil {
+		t.Fatalf("RenderFiles() error = %v", err)
+	}
+	if len(rendered) != 2 || rendered[0].Path != "internal/handlers/metrics.go" || rendered[1].Path != "internal/compliance/audit.go" {
+		t.Fatalf("RenderFiles() = %v, want metrics.go and audit.go", rendered)
+	}
+	for _, file := range rendered {
+		if strings.Contains(string(file.Content), "{{") || !strings.Contains(string(file.Content), "outside-repo") {
+			t.Errorf("%s is not rendered:\n%s", file.Path, file.Content)
+		}
+	}
+
+	tierConfig, err := BuiltinTierConfig(config.TierEnterprise)
+	if err != nil {
+		t.Fatalf("BuiltinTierConfig() error = %v", err)
+	}
+	if tierConfig == nil || len(tierConfig.Variables) == 0 || tierConfig.Variables[0].Name != "port" {
+		t.Fatalf("BuiltinTierConfig(enterprise) = %+v, want the port variable inherited from basic", tierConfig)
+	}
+
+	cfg := &config.ProjectConfig{
+		Name:      "outside-repo",
+		GoModule:  "github.com/example/outside-repo",
+		Tier:      config.TierBasic,
+		Version:   "1.0.0",
+		OutputDir: "outside-repo",
+		Variables: map[string]string{"port": "abc"},
+	}
+	gen, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	gen.SetOutput(NewMemoryOutput())
+	if err := gen.Generate(); err == nil || !strings.Contains(err.Error(), "port") {
+		t.Errorf("Generate() with port=abc error = %v, want an invalid port", err)
+	}
+}
@@ -0,0 +1,288 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ConflictPolicy controls how generation treats an output directory that already contains files
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyFail refuses to generate into a non-empty directory
+	ConflictPolicyFail ConflictPolicy = "fail"
+
+	// ConflictPolicyOverwrite replaces existing files with the generated ones
+	ConflictPolicyOverwrite ConflictPolicy = "overwrite"
+
+	// ConflictPolicySkip keeps existing files and only adds new ones
+	ConflictPolicySkip ConflictPolicy = "skip"
+
+	// ConflictPolicyMerge three-way merges generated files into existing ones,
+	// using the previous generation manifest as the base when there is one
+	ConflictPolicyMerge ConflictPolicy = "merge"
+)
+
+// IsValid checks if the conflict policy is a valid option
+func (p ConflictPolicy) IsValid() bool {
+	switch p {
+	case ConflictPolicyFail, ConflictPolicyOverwrite, ConflictPolicySkip, ConflictPolicyMerge:
+		return true
+	}
+	return false
+}
+
+// ErrOutputNotEmpty is returned when the output directory has files and the policy is ConflictPolicyFail
+var ErrOutputNotEmpty = errors.New("directory already exists and is not empty")
+
+// isEmptyDir reports whether dir is missing or has no entries
+func isEmptyDir(dir string) (bool, error) {
+	f, err := os.Open(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	_, err = f.Readdirnames(1)
+	if errors.Is(err, io.EOF) {
+		return true, nil
+	}
+	return false, err
+}
+
+// createStagingDir creates a hidden directory next to dir, so moving its
+// content into place is a rename on the same filesystem
+func createStagingDir(dir, purpose string) (string, error) {
+	dir = filepath.Clean(dir)
+	parent := filepath.Dir(dir)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory %s: %w", parent, err)
+	}
+
+	staging, err := os.MkdirTemp(parent, "."+filepath.Base(dir)+"."+purpose+"-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s directory: %w", purpose, err)
+	}
+	return staging, nil
+}
+
+// isGeneratorMetadata reports whether a project file belongs to the generator
+// itself; those are always replaced regardless of the conflict policy
+func isGeneratorMetadata(path string) bool {
+	return path == ManifestFileName || strings.HasPrefix(path, SnapshotDir+"/")
+}
+
+// commitTransaction moves a fully generated project from staging into dir.
+// An empty or missing dir is replaced with a single rename. Otherwise files
+// are moved one by one according to policy; if any move fails, every
+// replaced file is restored and every added file removed. Files merged
+// with conflict markers are reported by Conflicts.
+func (g *Generator) commitTransaction(staging, dir string, policy ConflictPolicy, previous *Manifest) error {
+	empty, err := isEmptyDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to inspect output directory %s: %w", dir, err)
+	}
+
+	if empty {
+		if err := os.Remove(dir); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to replace output directory %s: %w", dir, err)
+		}
+		if err := os.Rename(staging, dir); err != nil {
+			return fmt.Errorf("failed to move generated project into %s: %w", dir, err)
+		}
+		return nil
+	}
+
+	files, err := stagedFiles(staging)
+	if err != nil {
+		return err
+	}
+
+	backup, err := createStagingDir(dir, "rollback")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(backup)
+
+	tx := &fileTransaction{dir: dir, backup: backup}
+	var conflicts []string
+
+	for _, path := range files {
+		src := filepath.Join(staging, path)
+		dst := filepath.Join(dir, path)
+
+		existing, err := os.ReadFile(dst)
+		exists := err == nil
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			tx.rollback()
+			return fmt.Errorf("failed to read %s: %w", dst, err)
+		}
+
+		if exists && !isGeneratorMetadata(path) {
+			switch policy {
+			case ConflictPolicySkip:
+				continue
+			case ConflictPolicyMerge:
+				generated, err := os.ReadFile(src)
+				if err != nil {
+					tx.rollback()
+					return fmt.Errorf("failed to read staged %s: %w", path, err)
+				}
+				merged, conflicted := g.mergeExisting(dir, path, existing, generated, previous)
+				if conflicted {
+					conflicts = append(conflicts, path)
+				}
+				if err := os.WriteFile(src, merged, 0644); err != nil {
+					tx.rollback()
+					return fmt.Errorf("failed to write merged %s: %w", path, err)
+				}
+			}
+		}
+
+		if err := tx.move(src, path, exists); err != nil {
+			tx.rollback()
+			return fmt.Errorf("failed to move %s into place: %w", path, err)
+		}
+	}
+
+	g.conflicts = conflicts
+	return os.RemoveAll(staging)
+}
+
+// mergeExisting three-way merges a generated file into the existing one
+func (g *Generator) mergeExisting(dir, path string, existing, generated []byte, previous *Manifest) ([]byte, bool) {
+	base := ""
+	if previous != nil && previous.Config != nil {
+		if entry, ok := previous.File(path); ok {
+			// Untouched since the previous generation: take the new version
+			if Checksum(existing) == entry.SHA256 {
+				return generated, false
+			}
+
+			if source, err := LoadTemplateSnapshot(dir, entry.TemplateHash); err == nil {
+				if rendered, err := g.templates.RenderSource(entry.Template, source, previous.FileContext(entry)); err == nil {
+					if formatted, err := FormatArtifact(path, rendered); err == nil {
+						rendered = formatted
+					}
+					base = string(rendered)
+				}
+			}
+		}
+	}
+
+	result := Merge3(base, string(existing), string(generated), MergeLabels{
+		Base:   "a/" + path,
+		Ours:   "existing",
+		Theirs: "generated",
+	}, ConflictMarkers)
+
+	return []byte(result.Content), result.HasConflicts()
+}
+
+// stagedFiles lists every file under staging as a sorted slash-separated relative path
+func stagedFiles(staging string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(staging, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(staging, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staged files: %w", err)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// fileTransaction moves files into a directory while remembering how to undo each move
+type fileTransaction struct {
+	dir      string
+	backup   string
+	added    []string
+	replaced []string
+	dirs     []string
+}
+
+// move renames src to path under the transaction directory, backing up any existing file
+func (tx *fileTransaction) move(src, path string, exists bool) error {
+	dst := filepath.Join(tx.dir, path)
+
+	if err := tx.mkdirAll(filepath.Dir(dst)); err != nil {
+		return err
+	}
+
+	if exists {
+		saved := filepath.Join(tx.backup, path)
+		if err := os.MkdirAll(filepath.Dir(saved), 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(dst, saved); err != nil {
+			return err
+		}
+		tx.replaced = append(tx.replaced, path)
+	}
+
+	if err := os.Rename(src, dst); err != nil {
+		return err
+	}
+	if !exists {
+		tx.added = append(tx.added, path)
+	}
+
+	return nil
+}
+
+// mkdirAll creates dir and remembers every directory it had to create
+func (tx *fileTransaction) mkdirAll(dir string) error {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	// Deepest directories last so rollback can remove them first
+	for i := len(missing) - 1; i >= 0; i-- {
+		tx.dirs = append(tx.dirs, missing[i])
+	}
+	return nil
+}
+
+// rollback undoes every move made so far
+func (tx *fileTransaction) rollback() {
+	for _, path := range tx.added {
+		os.Remove(filepath.Join(tx.dir, path))
+	}
+	for _, path := range tx.replaced {
+		dst := filepath.Join(tx.dir, path)
+		os.Remove(dst)
+		os.Rename(filepath.Join(tx.backup, path), dst)
+	}
+	for i := len(tx.dirs) - 1; i >= 0; i-- {
+		os.Remove(tx.dirs[i])
+	}
+}
@@ -0,0 +1,147 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/scanner"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Diagnostic is a single problem found in a generated file
+type Diagnostic struct {
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// String formats the diagnostic as path:line:column: message
+func (d Diagnostic) String() string {
+	switch {
+	case d.Line > 0 && d.Column > 0:
+		return fmt.Sprintf("%s:%d:%d: %s", d.Path, d.Line, d.Column, d.Message)
+	case d.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", d.Path, d.Line, d.Message)
+	default:
+		return fmt.Sprintf("%s: %s", d.Path, d.Message)
+	}
+}
+
+// ArtifactError reports that a generated file is not valid Go, YAML or JSON,
+// which almost always means a bug in the template that produced it
+type ArtifactError struct {
+	Path        string
+	Diagnostics []Diagnostic
+}
+
+// Error implements the error interface
+func (e *ArtifactError) Error() string {
+	lines := make([]string, len(e.Diagnostics))
+	for i, d := range e.Diagnostics {
+		lines[i] = d.String()
+	}
+	return fmt.Sprintf("generated file %s is invalid:\n  %s", e.Path, strings.Join(lines, "\n  "))
+}
+
+// yamlLinePattern extracts the line number from yaml.v3 error messages
+var yamlLinePattern = regexp.MustCompile(`line (\d+): (.*)`)
+
+// FormatArtifact verifies a rendered file by its extension and returns its
+// final content. Go files are parsed and gofmt-ed; YAML and JSON files are
+// parsed and returned unchanged. Other files pass through as is. Problems
+// are reported as an *ArtifactError with file:line diagnostics.
+func FormatArtifact(filename string, content []byte) ([]byte, error) {
+	name := path.Base(filename)
+
+	var diagnostics []Diagnostic
+	switch strings.ToLower(path.Ext(name)) {
+	case ".go":
+		formatted, err := format.Source(content)
+		if err == nil {
+			return formatted, nil
+		}
+		diagnostics = goDiagnostics(filename, err)
+	case ".yaml", ".yml":
+		diagnostics = yamlDiagnostics(filename, content)
+	case ".json":
+		diagnostics = jsonDiagnostics(filename, content)
+	}
+
+	if len(diagnostics) > 0 {
+		return nil, &ArtifactError{Path: filename, Diagnostics: diagnostics}
+	}
+	return content, nil
+}
+
+// goDiagnostics converts a go/format error into diagnostics
+func goDiagnostics(filename string, err error) []Diagnostic {
+	var list scanner.ErrorList
+	if !errors.As(err, &list) {
+		return []Diagnostic{{Path: filename, Message: err.Error()}}
+	}
+
+	diagnostics := make([]Diagnostic, 0, len(list))
+	for _, e := range list {
+		diagnostics = append(diagnostics, Diagnostic{
+			Path:    filename,
+			Line:    e.Pos.Line,
+			Column:  e.Pos.Column,
+			Message: e.Msg,
+		})
+	}
+	return diagnostics
+}
+
+// yamlDiagnostics parses every document in a YAML stream
+func yamlDiagnostics(filename string, content []byte) []Diagnostic {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			d := Diagnostic{Path: filename, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+			if m := yamlLinePattern.FindStringSubmatch(d.Message); m != nil {
+				d.Line, _ = strconv.Atoi(m[1])
+				d.Message = m[2]
+			}
+			return []Diagnostic{d}
+		}
+	}
+}
+
+// jsonDiagnostics parses a JSON document
+func jsonDiagnostics(filename string, content []byte) []Diagnostic {
+	var v interface{}
+	err := json.Unmarshal(content, &v)
+	if err == nil {
+		return nil
+	}
+
+	d := Diagnostic{Path: filename, Message: err.Error()}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		d.Line, d.Column = lineColumn(content, int(syntaxErr.Offset))
+	}
+	return []Diagnostic{d}
+}
+
+// lineColumn converts a byte offset into a 1-based line and column
+func lineColumn(content []byte, offset int) (int, int) {
+	offset = min(offset, len(content))
+	before := content[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := offset - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFormatArtifact(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		want     string
+		wantLine int
+	}{
+		{
+			name:     "go is formatted",
+			filename: "internal/server/server.go",
+			content:  "package server\nfunc  New( ) {\n}\n",
+			want:     "package server\n\nfunc New() {\n}\n",
+		},
+		{
+			name:     "go syntax error",
+			filename: "internal/server/server.go",
+			content:  "package server\n\nfunc New() {\n\treturn (\n}\n",
+			wantLine: 5,
+		},
+		{
+			name:     "valid yaml stream",
+			filename: "deployments/kubernetes/deployment.yaml",
+			content:  "kind: Service\n---\nkind: Deployment\n",
+			want:     "kind: Service\n---\nkind: Deployment\n",
+		},
+		{
+			name:     "yaml syntax error",
+			filename: "deployments/kubernetes/deployment.yaml",
+			content:  "metadata:\n  name: svc\n  labels: app: svc\n",
+			wantLine: 3,
+		},
+		{
+			name:     "json syntax error",
+			filename: "client/typescript/package.json",
+			content:  "{\n  \"name\": \"client\",\n}\n",
+			wantLine: 3,
+		},
+		{
+			name:     "other files pass through",
+			filename: "README.md",
+			content:  "# {not: valid: anything\n",
+			want:     "# {not: valid: anything\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatArtifact(tt.filename, []byte(tt.content))
+
+			if tt.wantLine > 0 {
+				var artifactErr *ArtifactError
+				if !errors.As(err, &artifactErr) {
+					t.Fatalf("Expected *ArtifactError, got %v", err)
+				}
+				d := artifactErr.Diagnostics[0]
+				if d.Path != tt.filename || d.Line != tt.wantLine {
+					t.Errorf("Diagnostic = %s, want %s at line %d", d, tt.filename, tt.wantLine)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("FormatArtifact() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("FormatArtifact() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,432 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// muxImportPath is the import path of the gorilla router used by generated servers
+const muxImportPath = "github.com/gorilla/mux"
+
+// Route is an HTTP route registered on the health subrouter
+type Route struct {
+	Method  string
+	Path    string
+	Handler string // method of the health handler, e.g. "DependenciesCheck"
+}
+
+// WiringPatch lists the server wiring a project needs, or with Remove set,
+// the wiring a project no longer needs
+type WiringPatch struct {
+	Imports    []string
+	Middleware []string // expressions passed to router.Use
+	Routes     []Route
+	Remove     bool
+}
+
+// WiringEdit describes a single edit made to the server wiring
+type WiringEdit struct {
+	Line        int // line in the original source the edit was made after, or removed
+	Description string
+}
+
+// String formats the edit for migration plans
+func (e WiringEdit) String() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Description)
+}
+
+// routerWiring holds the gorilla mux wiring found in a single function
+type routerWiring struct {
+	router     string
+	routerStmt ast.Stmt
+	subrouter  string
+	subStmt    ast.Stmt
+	handler    string
+	paths      map[string]bool
+	routes     map[string]ast.Stmt // route statements by path
+	lastRoute  ast.Stmt
+	middleware map[string]bool
+	uses       map[string]ast.Stmt // router.Use statements by middleware
+	lastUse    ast.Stmt
+}
+
+// PatchServerWiring adds the imports, middleware and routes of patch to
+// the Go source in src, or removes them if patch.Remove is set. Every
+// function that creates a gorilla mux router is patched; wiring that is
+// already present (or absent) is left alone, as is everything else in the
+// file. The result is gofmt-ed.
+func PatchServerWiring(filename string, src []byte, patch WiringPatch) ([]byte, []WiringEdit, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	muxName := "mux"
+	for _, spec := range file.Imports {
+		if path, _ := strconv.Unquote(spec.Path.Value); path == muxImportPath && spec.Name != nil {
+			muxName = spec.Name.Name
+		}
+	}
+
+	var wirings []*routerWiring
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+			if w := findRouterWiring(fset, fn.Body, muxName); w != nil {
+				wirings = append(wirings, w)
+			}
+		}
+	}
+
+	if len(wirings) == 0 && (len(patch.Middleware) > 0 || len(patch.Routes) > 0) {
+		return nil, nil, fmt.Errorf("no %s router found in %s", muxImportPath, filename)
+	}
+
+	if patch.Remove {
+		return removeServerWiring(fset, file, filename, src, wirings, patch)
+	}
+
+	inserts := make(map[int]string)
+	var edits []WiringEdit
+
+	insertAfter := func(stmt ast.Stmt, code, description string) {
+		end := fset.Position(stmt.End())
+		start := fset.Position(stmt.Pos())
+		lineStart := start.Offset - (start.Column - 1)
+		indent := src[lineStart:start.Offset]
+		inserts[lineEnd(src, end.Offset)] += "\n" + string(indent) + code
+		edits = append(edits, WiringEdit{Line: end.Line, Description: description})
+	}
+
+	for _, imp := range patch.Imports {
+		if hasImport(file, imp) {
+			continue
+		}
+		offset, code, line := importInsertion(fset, file, imp)
+		inserts[lineEnd(src, offset)] += code
+		edits = append(edits, WiringEdit{Line: line, Description: fmt.Sprintf("import %q", imp)})
+	}
+
+	for _, w := range wirings {
+		for _, mw := range patch.Middleware {
+			if w.middleware[mw] {
+				continue
+			}
+			anchor := w.lastUse
+			if anchor == nil {
+				anchor = w.routerStmt
+			}
+			insertAfter(anchor, fmt.Sprintf("%s.Use(%s)", w.router, mw), fmt.Sprintf("add middleware %s.Use(%s)", w.router, mw))
+			w.middleware[mw] = true
+		}
+
+		for _, route := range patch.Routes {
+			if w.paths[route.Path] {
+				continue
+			}
+			if w.subStmt == nil {
+				return nil, nil, fmt.Errorf("no subrouter found in %s to register %s on", filename, route.Path)
+			}
+			if w.handler == "" {
+				return nil, nil, fmt.Errorf("cannot determine the health handler in %s to register %s with", filename, route.Path)
+			}
+			anchor := w.lastRoute
+			if anchor == nil {
+				anchor = w.subStmt
+			}
+			handler := w.handler + "." + route.Handler
+			insertAfter(anchor, fmt.Sprintf("%s.HandleFunc(%q, %s).Methods(%q)", w.subrouter, route.Path, handler, route.Method),
+				fmt.Sprintf("register route %s %s → %s", route.Method, route.Path, handler))
+			w.paths[route.Path] = true
+		}
+	}
+
+	if len(inserts) == 0 {
+		return src, nil, nil
+	}
+
+	offsets := make([]int, 0, len(inserts))
+	for offset := range inserts {
+		offsets = append(offsets, offset)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(offsets)))
+
+	out := append([]byte(nil), src...)
+	for _, offset := range offsets {
+		out = append(out[:offset], append([]byte(inserts[offset]), out[offset:]...)...)
+	}
+
+	formatted, err := format.Source(out)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to format patched %s: %w", filename, err)
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].Line < edits[j].Line })
+	return formatted, edits, nil
+}
+
+// removeServerWiring deletes the router.Use and route statements of patch
+// from every wiring, then the imports of patch that nothing else in the
+// file still refers to
+func removeServerWiring(fset *token.FileSet, file *ast.File, filename string, src []byte, wirings []*routerWiring, patch WiringPatch) ([]byte, []WiringEdit, error) {
+	type span struct{ start, end int }
+	var removed []span
+	var edits []WiringEdit
+
+	removeLines := func(node ast.Node, description string) {
+		start := fset.Position(node.Pos())
+		end := fset.Position(node.End())
+		removed = append(removed, span{start.Offset - (start.Column - 1), min(lineEnd(src, end.Offset)+1, len(src))})
+		edits = append(edits, WiringEdit{Line: start.Line, Description: description})
+	}
+
+	for _, w := range wirings {
+		for _, mw := range patch.Middleware {
+			if stmt, ok := w.uses[mw]; ok {
+				removeLines(stmt, fmt.Sprintf("remove middleware %s.Use(%s)", w.router, mw))
+			}
+		}
+		for _, route := range patch.Routes {
+			if stmt, ok := w.routes[route.Path]; ok {
+				removeLines(stmt, fmt.Sprintf("unregister route %s %s", route.Method, route.Path))
+			}
+		}
+	}
+
+	// An import stays while code outside the removed statements uses it
+	inRemoved := func(pos token.Pos) bool {
+		offset := fset.Position(pos).Offset
+		for _, r := range removed {
+			if offset >= r.start && offset < r.end {
+				return true
+			}
+		}
+		return false
+	}
+	for _, imp := range patch.Imports {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.IMPORT {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				spec := spec.(*ast.ImportSpec)
+				if p, _ := strconv.Unquote(spec.Path.Value); p != imp {
+					continue
+				}
+				name := path.Base(imp)
+				if spec.Name != nil {
+					name = spec.Name.Name
+				}
+				used := false
+				ast.Inspect(file, func(n ast.Node) bool {
+					if sel, ok := n.(*ast.SelectorExpr); ok {
+						if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == name && !inRemoved(sel.Pos()) {
+							used = true
+						}
+					}
+					return !used
+				})
+				if used {
+					continue
+				}
+				var node ast.Node = spec
+				if !gen.Lparen.IsValid() {
+					node = gen
+				}
+				removeLines(node, fmt.Sprintf("remove import %q", imp))
+			}
+		}
+	}
+
+	if len(removed) == 0 {
+		return src, nil, nil
+	}
+
+	sort.Slice(removed, func(i, j int) bool { return removed[i].start > removed[j].start })
+	out := append([]byte(nil), src...)
+	for _, r := range removed {
+		out = append(out[:r.start], out[r.end:]...)
+	}
+
+	formatted, err := format.Source(out)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to format patched %s: %w", filename, err)
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].Line < edits[j].Line })
+	return formatted, edits, nil
+}
+
+// findRouterWiring collects the router, subrouter, routes and middleware in a function body
+func findRouterWiring(fset *token.FileSet, body *ast.BlockStmt, muxName string) *routerWiring {
+	var w *routerWiring
+
+	for _, stmt := range body.List {
+		switch s := stmt.(type) {
+		case *ast.AssignStmt:
+			if len(s.Lhs) != 1 || len(s.Rhs) != 1 {
+				continue
+			}
+			name, ok := s.Lhs[0].(*ast.Ident)
+			if !ok {
+				continue
+			}
+			call, ok := s.Rhs[0].(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			switch {
+			case isSelector(call.Fun, muxName, "NewRouter"):
+				w = &routerWiring{
+					router:     name.Name,
+					routerStmt: s,
+					paths:      make(map[string]bool),
+					routes:     make(map[string]ast.Stmt),
+					middleware: make(map[string]bool),
+					uses:       make(map[string]ast.Stmt),
+				}
+			case w != nil && w.subStmt == nil && methodName(call) == "Subrouter" && rootIdent(call) == w.router:
+				w.subrouter = name.Name
+				w.subStmt = s
+			}
+
+		case *ast.ExprStmt:
+			if w == nil {
+				continue
+			}
+			call, ok := s.X.(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+
+			if isSelector(call.Fun, w.router, "Use") && len(call.Args) == 1 {
+				mw := exprString(fset, call.Args[0])
+				w.middleware[mw] = true
+				w.uses[mw] = s
+				w.lastUse = s
+				continue
+			}
+
+			if route := findCall(call, w.subrouter, "HandleFunc"); route != nil && w.subrouter != "" && len(route.Args) == 2 {
+				if lit, ok := route.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					path, _ := strconv.Unquote(lit.Value)
+					w.paths[path] = true
+					w.routes[path] = s
+				}
+				if sel, ok := route.Args[1].(*ast.SelectorExpr); ok {
+					if recv, ok := sel.X.(*ast.Ident); ok && w.handler == "" {
+						w.handler = recv.Name
+					}
+				}
+				w.lastRoute = s
+			}
+		}
+	}
+
+	return w
+}
+
+// importInsertion returns where and how to add an import to file
+func importInsertion(fset *token.FileSet, file *ast.File, path string) (int, string, int) {
+	spec := strconv.Quote(path)
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if gen.Lparen.IsValid() && len(gen.Specs) > 0 {
+			last := fset.Position(gen.Specs[len(gen.Specs)-1].End())
+			return last.Offset, "\n\t" + spec, last.Line
+		}
+		end := fset.Position(gen.End())
+		return end.Offset, "\nimport " + spec, end.Line
+	}
+
+	end := fset.Position(file.Name.End())
+	return end.Offset, "\n\nimport " + spec, end.Line
+}
+
+// lineEnd returns the offset of the end of the line containing offset, so
+// inserted code goes after any trailing comment
+func lineEnd(src []byte, offset int) int {
+	for offset < len(src) && src[offset] != '\n' {
+		offset++
+	}
+	return offset
+}
+
+// hasImport reports whether file imports path
+func hasImport(file *ast.File, path string) bool {
+	for _, spec := range file.Imports {
+		if p, _ := strconv.Unquote(spec.Path.Value); p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// isSelector reports whether expr is recv.name
+func isSelector(expr ast.Expr, recv, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && ident.Name == recv
+}
+
+// methodName returns the name of the method called by call
+func methodName(call *ast.CallExpr) string {
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+		return sel.Sel.Name
+	}
+	return ""
+}
+
+// rootIdent returns the identifier a method call chain starts from
+func rootIdent(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.CallExpr:
+			expr = e.Fun
+		case *ast.SelectorExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// findCall finds recv.name(...) in a method call chain such as recv.name(...).Methods(...)
+func findCall(call *ast.CallExpr, recv, name string) *ast.CallExpr {
+	for call != nil {
+		if isSelector(call.Fun, recv, name) {
+			return call
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return nil
+		}
+		call, _ = sel.X.(*ast.CallExpr)
+	}
+	return nil
+}
+
+// exprString prints an expression the way it appears in source
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	var sb strings.Builder
+	printer.Fprint(&sb, fset, expr)
+	return sb.String()
+}
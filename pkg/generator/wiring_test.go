@@ -0,0 +1,140 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const testServerSource = `package server
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"example.com/svc/internal/handlers"
+)
+
+func New() http.Handler {
+	healthHandler := handlers.NewHealthHandler()
+
+	router := mux.NewRouter()
+
+	// Health endpoints
+	health := router.PathPrefix("/health").Subrouter()
+	health.HandleFunc("", healthHandler.CheckHealth).Methods("GET")
+	health.HandleFunc("/custom", customCheck).Methods("GET") // user route
+
+	router.HandleFunc("/orders", listOrders)
+	return router
+}
+`
+
+func TestPatchServerWiring(t *testing.T) {
+	patch := WiringPatch{
+		Imports:    []string{"example.com/svc/internal/middleware"},
+		Middleware: []string{"middleware.ServerTimingMiddleware"},
+		Routes: []Route{
+			{Method: "GET", Path: "/dependencies", Handler: "DependenciesCheck"},
+			{Method: "GET", Path: "", Handler: "CheckHealth"},
+		},
+	}
+
+	out, edits, err := PatchServerWiring("server.go", []byte(testServerSource), patch)
+	if err != nil {
+		t.Fatalf("PatchServerWiring() error = %v", err)
+	}
+
+	if len(edits) != 3 {
+		t.Fatalf("Expected 3 edits, got %d: %v", len(edits), edits)
+	}
+
+	patched := string(out)
+	for _, want := range []string{
+		"\t\"example.com/svc/internal/middleware\"\n",
+		"\trouter := mux.NewRouter()\n\trouter.Use(middleware.ServerTimingMiddleware)\n",
+		"// user route\n\thealth.HandleFunc(\"/dependencies\", healthHandler.DependenciesCheck).Methods(\"GET\")\n",
+		"\trouter.HandleFunc(\"/orders\", listOrders)\n",
+	} {
+		if !strings.Contains(patched, want) {
+			t.Errorf("Patched source missing %q:\n%s", want, patched)
+		}
+	}
+
+	// Patching again must be a no-op
+	again, edits, err := PatchServerWiring("server.go", out, patch)
+	if err != nil {
+		t.Fatalf("PatchServerWiring() second run error = %v", err)
+	}
+	if len(edits) != 0 || string(again) != patched {
+		t.Errorf("Second patch made edits: %v", edits)
+	}
+}
+
+func TestPatchServerWiring_Remove(t *testing.T) {
+	patch := WiringPatch{
+		Imports: []string{"example.com/svc/internal/security", "example.com/svc/internal/compliance"},
+		Middleware: []string{
+			"security.MTLSMiddleware",
+			"compliance.AuditMiddleware(compliance.DefaultAuditLogger())",
+		},
+		Routes: []Route{{Method: "GET", Path: "/dependencies", Handler: "DependenciesCheck"}},
+	}
+
+	upgraded, _, err := PatchServerWiring("server.go", []byte(testServerSource), patch)
+	if err != nil {
+		t.Fatalf("PatchServerWiring() error = %v", err)
+	}
+
+	// Removing the wiring again restores the original source
+	patch.Remove = true
+	downgraded, edits, err := PatchServerWiring("server.go", upgraded, patch)
+	if err != nil {
+		t.Fatalf("PatchServerWiring() remove error = %v", err)
+	}
+	if len(edits) != 5 {
+		t.Errorf("Expected 5 edits, got %d: %v", len(edits), edits)
+	}
+	if string(downgraded) != testServerSource {
+		t.Errorf("Removing the wiring did not restore the source:\n%s", downgraded)
+	}
+
+	// Removing wiring that is not there is a no-op
+	again, edits, err := PatchServerWiring("server.go", downgraded, patch)
+	if err != nil {
+		t.Fatalf("PatchServerWiring() second remove error = %v", err)
+	}
+	if len(edits) != 0 || string(again) != testServerSource {
+		t.Errorf("Second removal made edits: %v", edits)
+	}
+}
+
+func TestPatchServerWiring_RemoveKeepsUsedImports(t *testing.T) {
+	src := strings.Replace(testServerSource, "\trouter.HandleFunc(\"/orders\", listOrders)\n",
+		"\trouter.Use(middleware.ServerTimingMiddleware)\n\trouter.HandleFunc(\"/orders\", middleware.Wrap(listOrders))\n", 1)
+	src = strings.Replace(src, "\"github.com/gorilla/mux\"\n", "\"github.com/gorilla/mux\"\n\n\t\"example.com/svc/internal/middleware\"\n", 1)
+
+	out, edits, err := PatchServerWiring("server.go", []byte(src), WiringPatch{
+		Imports:    []string{"example.com/svc/internal/middleware"},
+		Middleware: []string{"middleware.ServerTimingMiddleware"},
+		Remove:     true,
+	})
+	if err != nil {
+		t.Fatalf("PatchServerWiring() error = %v", err)
+	}
+	if len(edits) != 1 {
+		t.Errorf("Expected only the router.Use call to be removed, got %v", edits)
+	}
+	if patched := string(out); strings.Contains(patched, "router.Use") || !strings.Contains(patched, "\"example.com/svc/internal/middleware\"") {
+		t.Errorf("Expected the middleware import to stay for middleware.Wrap:\n%s", patched)
+	}
+}
+
+func TestPatchServerWiring_NoRouter(t *testing.T) {
+	_, _, err := PatchServerWiring("main.go", []byte("package main\n\nfunc main() {}\n"), WiringPatch{
+		Routes: []Route{{Method: "GET", Path: "/metrics", Handler: "MetricsCheck"}},
+	})
+	if err == nil {
+		t.Error("Expected an error for a file without a router")
+	}
+}
@@ -9,7 +9,7 @@ import (
 	"os"
 	"time"
 
-	"{{.ModuleName}}/internal/security"
+	"{{.Config.GoModule}}/internal/security"
 )
 
 // AuditEvent represents an audit log event
@@ -70,6 +70,22 @@ func NewAuditLogger(logFile string, enabled bool) (*AuditLogger, error) {
 	}, nil
 }
 
+// DefaultAuditLogger returns an audit logger writing to the file named by
+// AUDIT_LOG_FILE; auditing is disabled when it is unset or cannot be opened
+func DefaultAuditLogger() *AuditLogger {
+	logFile := os.Getenv("AUDIT_LOG_FILE")
+	if logFile == "" {
+		return &AuditLogger{enabled: false}
+	}
+
+	logger, err := NewAuditLogger(logFile, true)
+	if err != nil {
+		log.Printf("Audit logging disabled: %v", err)
+		return &AuditLogger{enabled: false}
+	}
+	return logger
+}
+
 // LogEvent logs an audit event
 func (a *AuditLogger) LogEvent(event AuditEvent) error {
 	if !a.enabled {
@@ -1,108 +0,0 @@
-package handlers
-
-import (
-	"encoding/json"
-	"net/http"
-	"time"
-)
-
-// DependencyStatus represents the health status of a dependency
-type DependencyStatus struct {
-	Status       string    `json:"status"`
-	ResponseTime string    `json:"response_time"`
-	LastCheck    time.Time `json:"last_check"`
-	Message      string    `json:"message,omitempty"`
-}
-
-// DependenciesResponse represents the response for dependency health checks
-type DependenciesResponse struct {
-	Dependencies map[string]DependencyStatus `json:"dependencies"`
-	Timestamp    time.Time                   `json:"timestamp"`
-}
-
-// DependenciesCheck handles dependency health check requests
-func (h *HealthHandler) DependenciesCheck(w http.ResponseWriter, r *http.Request) {
-	start := time.Now()
-	
-	dependencies := make(map[string]DependencyStatus)
-	
-	// Check database dependency
-	dependencies["database"] = h.checkDatabase()
-	
-	// Check cache dependency
-	dependencies["cache"] = h.checkCache()
-	
-	// Check external API dependency
-	dependencies["external_api"] = h.checkExternalAPI()
-	
-	response := DependenciesResponse{
-		Dependencies: dependencies,
-		Timestamp:    time.Now(),
-	}
-	
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("X-Response-Time", time.Since(start).String())
-	
-	// Determine overall status
-	overallHealthy := true
-	for _, dep := range dependencies {
-		if dep.Status != "healthy" {
-			overallHealthy = false
-			break
-		}
-	}
-	
-	if !overallHealthy {
-		w.WriteHeader(http.StatusServiceUnavailable)
-	}
-	
-	json.NewEncoder(w).Encode(response)
-}
-
-// checkDatabase checks the database connection health
-func (h *HealthHandler) checkDatabase() DependencyStatus {
-	start := time.Now()
-	
-	// TODO: Implement actual database health check
-	// For now, simulate a healthy database
-	responseTime := time.Since(start)
-	
-	return DependencyStatus{
-		Status:       "healthy",
-		ResponseTime: responseTime.String(),
-		LastCheck:    time.Now(),
-		Message:      "Database connection is healthy",
-	}
-}
-
-// checkCache checks the cache connection health
-func (h *HealthHandler) checkCache() DependencyStatus {
-	start := time.Now()
-	
-	// TODO: Implement actual cache health check
-	// For now, simulate a healthy cache
-	responseTime := time.Since(start)
-	
-	return DependencyStatus{
-		Status:       "healthy",
-		ResponseTime: responseTime.String(),
-		LastCheck:    time.Now(),
-		Message:      "Cache connection is healthy",
-	}
-}
-
-// checkExternalAPI checks external API health
-func (h *HealthHandler) checkExternalAPI() DependencyStatus {
-	start := time.Now()
-	
-	// TODO: Implement actual external API health check
-	// For now, simulate a healthy external API
-	responseTime := time.Since(start)
-	
-	return DependencyStatus{
-		Status:       "healthy",
-		ResponseTime: responseTime.String(),
-		LastCheck:    time.Now(),
-		Message:      "External API is responding",
-	}
-}